@@ -3,7 +3,7 @@ package spotify
 import (
 	"context"
 	"errors"
-	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -44,7 +44,7 @@ type SimpleAlbum struct {
 	Endpoint string `json:"href"`
 	// The cover art for the album in various sizes,
 	// widest first.
-	Images []Image `json:"images"`
+	Images Images `json:"images"`
 	// Known external URLs for this album.
 	ExternalURLs map[string]string `json:"external_urls"`
 	// The date the album was first released.  For example, "1981-12-15".
@@ -108,22 +108,29 @@ type SavedAlbum struct {
 }
 
 // GetAlbum gets Spotify catalog information for a single album, given its
-// [Spotify ID]. Supported options: [Market].
+// [Spotify ID], URI or share URL, see [ResolveID]. Supported options: [Market].
 //
 // [Spotify ID]: https://developer.spotify.com/documentation/web-api/concepts/spotify-uris-ids
 func (c *Client) GetAlbum(ctx context.Context, id ID, opts ...RequestOption) (*FullAlbum, error) {
-	spotifyURL := fmt.Sprintf("%salbums/%s", c.baseURL, id)
-
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
-		spotifyURL += "?" + params
+	id, err := resolveID(id, "album")
+	if err != nil {
+		return nil, err
+	}
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	if cached, ok := c.cachedEntity(ctx, "album", o.urlParams, id); ok {
+		a := cached.(FullAlbum)
+		return &a, nil
 	}
+	spotifyURL := c.apiURL(o.urlParams, "albums/", string(id))
 
 	var a FullAlbum
 
-	err := c.get(ctx, spotifyURL, &a)
+	err = c.get(ctx, spotifyURL, &a)
 	if err != nil {
 		return nil, err
 	}
+	c.cacheEntity(ctx, "album", o.urlParams, id, a)
 
 	return &a, nil
 }
@@ -149,21 +156,50 @@ func (c *Client) GetAlbums(ctx context.Context, ids []ID, opts ...RequestOption)
 	if len(ids) > 20 {
 		return nil, errors.New("spotify: exceeded maximum number of albums")
 	}
-	params := processOptions(opts...).urlParams
-	params.Set("ids", strings.Join(toStringSlice(ids), ","))
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	ids, err := resolveIDs(ids, "album")
+	if err != nil {
+		return nil, err
+	}
+	albums := make([]*FullAlbum, len(ids))
+	missing, positions := c.uncachedEntities(ctx, "album", o.urlParams, ids, func(i int, cached interface{}) {
+		a := cached.(FullAlbum)
+		albums[i] = &a
+	})
+	if len(missing) == 0 && len(ids) > 0 {
+		return albums, nil
+	}
 
-	spotifyURL := fmt.Sprintf("%salbums?%s", c.baseURL, params.Encode())
+	params := url.Values{"ids": {joinIDs(missing)}}
+	for k, v := range o.urlParams {
+		params[k] = v
+	}
+
+	spotifyURL := c.apiURL(params, "albums")
 
 	var a struct {
 		Albums []*FullAlbum `json:"albums"`
 	}
 
-	err := c.get(ctx, spotifyURL, &a)
+	err = c.get(ctx, spotifyURL, &a)
 	if err != nil {
 		return nil, err
 	}
+	if c.entities == nil {
+		return a.Albums, nil
+	}
+	for j, album := range a.Albums {
+		if j >= len(positions) {
+			break
+		}
+		albums[positions[j]] = album
+		if album != nil {
+			c.cacheEntity(ctx, "album", o.urlParams, missing[j], *album)
+		}
+	}
 
-	return a.Albums, nil
+	return albums, nil
 }
 
 // AlbumType represents the type of an album. It can be used to filter
@@ -178,6 +214,8 @@ const (
 	AlbumTypeSingle
 	AlbumTypeAppearsOn
 	AlbumTypeCompilation
+
+	allAlbumTypes = AlbumTypeAlbum | AlbumTypeSingle | AlbumTypeAppearsOn | AlbumTypeCompilation
 )
 
 func (at AlbumType) encode() string {
@@ -205,17 +243,67 @@ func (at AlbumType) encode() string {
 //
 // [tracks]: https://developer.spotify.com/documentation/web-api/reference/get-an-albums-tracks
 func (c *Client) GetAlbumTracks(ctx context.Context, id ID, opts ...RequestOption) (*SimpleTrackPage, error) {
-	spotifyURL := fmt.Sprintf("%salbums/%s/tracks", c.baseURL, id)
-
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
-		spotifyURL += "?" + params
+	id, err := resolveID(id, "album")
+	if err != nil {
+		return nil, err
+	}
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
 	}
+	ctx = o.context(ctx)
+	spotifyURL := c.apiURL(o.urlParams, "albums/", string(id), "/tracks")
 
 	var result SimpleTrackPage
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
 
 	return &result, nil
 }
+
+// GetAlbumTracksFull gets all of the tracks of an album as full tracks, which
+// unlike the simple tracks returned by [Client.GetAlbumTracks] include their
+// popularity and external IDs.  The album's tracks are listed first, and then
+// fetched in batches of 50 with [Client.GetTracks].  Tracks that can't be
+// found are left out.
+//
+// Supported options: [Market].
+func (c *Client) GetAlbumTracksFull(ctx context.Context, id ID, opts ...RequestOption) ([]FullTrack, error) {
+	page, err := c.GetAlbumTracks(ctx, id, append([]RequestOption{Limit(50)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]ID, 0, page.Total)
+	for {
+		for _, t := range page.Tracks {
+			ids = append(ids, t.ID)
+		}
+		err = c.NextPage(ctx, page)
+		if errors.Is(err, ErrNoMorePages) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tracks := make([]FullTrack, 0, len(ids))
+	for start := 0; start < len(ids); start += 50 {
+		end := start + 50
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch, err := c.GetTracks(ctx, ids[start:end], opts...)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range batch {
+			if t != nil {
+				tracks = append(tracks, *t)
+			}
+		}
+	}
+	return tracks, nil
+}
@@ -2,7 +2,9 @@ package spotify
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -105,3 +107,34 @@ func TestFindAlbumTracks(t *testing.T) {
 		t.Error("Expected 1 track, got", len(res.Tracks))
 	}
 }
+
+func TestGetAlbumTracksFull(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/albums/album/tracks":
+			if r.URL.Query().Get("offset") == "" {
+				fmt.Fprintf(w, `{"items": [{"id": "t1"}, {"id": "t2"}], "total": 3, "next": "%s/albums/album/tracks?offset=2"}`, server.URL)
+				return
+			}
+			fmt.Fprint(w, `{"items": [{"id": "t3"}], "offset": 2, "total": 3}`)
+		case "/tracks":
+			if ids := r.URL.Query().Get("ids"); ids != "t1,t2,t3" {
+				t.Errorf("Expected all tracks in one batch, got %s", ids)
+			}
+			fmt.Fprint(w, `{"tracks": [{"id": "t1", "popularity": 10}, null, {"id": "t3", "popularity": 30}]}`)
+		default:
+			t.Errorf("Unexpected request for %s", r.URL)
+		}
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	tracks, err := client.GetAlbumTracksFull(context.Background(), "album")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 2 || tracks[0].Popularity != 10 || tracks[1].ID != "t3" {
+		t.Errorf("Unexpected tracks: %+v", tracks)
+	}
+}
@@ -2,7 +2,8 @@ package spotify
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"net/url"
 	"strings"
 )
 
@@ -28,18 +29,27 @@ type FullArtist struct {
 	Genres    []string  `json:"genres"`
 	Followers Followers `json:"followers"`
 	// Images of the artist in various sizes, widest first.
-	Images []Image `json:"images"`
+	Images Images `json:"images"`
 }
 
 // GetArtist gets Spotify catalog information for a single artist, given its Spotify ID.
 func (c *Client) GetArtist(ctx context.Context, id ID) (*FullArtist, error) {
-	spotifyURL := fmt.Sprintf("%sartists/%s", c.baseURL, id)
+	id, err := resolveID(id, "artist")
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := c.cachedEntity(ctx, "artist", nil, id); ok {
+		a := cached.(FullArtist)
+		return &a, nil
+	}
+	spotifyURL := c.apiURL(nil, "artists/", string(id))
 
 	var a FullArtist
-	err := c.get(ctx, spotifyURL, &a)
+	err = c.get(ctx, spotifyURL, &a)
 	if err != nil {
 		return nil, err
 	}
+	c.cacheEntity(ctx, "artist", nil, id, a)
 
 	return &a, nil
 }
@@ -50,18 +60,42 @@ func (c *Client) GetArtist(ctx context.Context, id ID) (*FullArtist, error) {
 // in the result will be nil.  Duplicate IDs will result in duplicate artists
 // in the result.
 func (c *Client) GetArtists(ctx context.Context, ids ...ID) ([]*FullArtist, error) {
-	spotifyURL := fmt.Sprintf("%sartists?ids=%s", c.baseURL, strings.Join(toStringSlice(ids), ","))
+	ids, err := resolveIDs(ids, "artist")
+	if err != nil {
+		return nil, err
+	}
+	artists := make([]*FullArtist, len(ids))
+	missing, positions := c.uncachedEntities(ctx, "artist", nil, ids, func(i int, cached interface{}) {
+		a := cached.(FullArtist)
+		artists[i] = &a
+	})
+	if len(missing) == 0 && len(ids) > 0 {
+		return artists, nil
+	}
+	spotifyURL := c.apiURL(url.Values{"ids": {joinIDs(missing)}}, "artists")
 
 	var a struct {
 		Artists []*FullArtist
 	}
 
-	err := c.get(ctx, spotifyURL, &a)
+	err = c.get(ctx, spotifyURL, &a)
 	if err != nil {
 		return nil, err
 	}
+	if c.entities == nil {
+		return a.Artists, nil
+	}
+	for j, artist := range a.Artists {
+		if j >= len(positions) {
+			break
+		}
+		artists[positions[j]] = artist
+		if artist != nil {
+			c.cacheEntity(ctx, "artist", nil, missing[j], *artist)
+		}
+	}
 
-	return a.Artists, nil
+	return artists, nil
 }
 
 // GetArtistsTopTracks gets Spotify catalog information about an artist's top
@@ -70,13 +104,17 @@ func (c *Client) GetArtists(ctx context.Context, ids ...ID) ([]*FullArtist, erro
 //
 // [ISO 3166-1 alpha-2]: https://en.wikipedia.org/wiki/ISO_3166-1_alpha-2
 func (c *Client) GetArtistsTopTracks(ctx context.Context, artistID ID, country string) ([]FullTrack, error) {
-	spotifyURL := fmt.Sprintf("%sartists/%s/top-tracks?country=%s", c.baseURL, artistID, country)
+	artistID, err := resolveID(artistID, "artist")
+	if err != nil {
+		return nil, err
+	}
+	spotifyURL := c.apiURL(url.Values{"country": {country}}, "artists/", string(artistID), "/top-tracks")
 
 	var t struct {
 		Tracks []FullTrack `json:"tracks"`
 	}
 
-	err := c.get(ctx, spotifyURL, &t)
+	err = c.get(ctx, spotifyURL, &t)
 	if err != nil {
 		return nil, err
 	}
@@ -89,13 +127,17 @@ func (c *Client) GetArtistsTopTracks(ctx context.Context, artistID ID, country s
 // listening history.  This function returns up to 20 artists that are considered
 // related to the specified artist.
 func (c *Client) GetRelatedArtists(ctx context.Context, id ID) ([]FullArtist, error) {
-	spotifyURL := fmt.Sprintf("%sartists/%s/related-artists", c.baseURL, id)
+	id, err := resolveID(id, "artist")
+	if err != nil {
+		return nil, err
+	}
+	spotifyURL := c.apiURL(nil, "artists/", string(id), "/related-artists")
 
 	var a struct {
 		Artists []FullArtist `json:"artists"`
 	}
 
-	err := c.get(ctx, spotifyURL, &a)
+	err = c.get(ctx, spotifyURL, &a)
 	if err != nil {
 		return nil, err
 	}
@@ -104,36 +146,99 @@ func (c *Client) GetRelatedArtists(ctx context.Context, id ID) ([]FullArtist, er
 }
 
 // GetArtistAlbums gets Spotify catalog information about an artist's albums.
-// It is equivalent to GetArtistAlbumsOpt(artistID, nil).
 //
-// The AlbumType argument can be used to find a particular types of album.
+// The [IncludeGroups] option can be used to find particular types of albums.
 // If the Market is not specified, Spotify will likely return a lot
 // of duplicates (one for each market in which the album is available
 //
-// Supported options: [Market].
-func (c *Client) GetArtistAlbums(ctx context.Context, artistID ID, ts []AlbumType, opts ...RequestOption) (*SimpleAlbumPage, error) {
-	spotifyURL := fmt.Sprintf("%sartists/%s/albums", c.baseURL, artistID)
-	// add optional query string if options were specified
-	values := processOptions(opts...).urlParams
-
-	if ts != nil {
-		types := make([]string, len(ts))
-		for i := range ts {
-			types[i] = ts[i].encode()
-		}
-		values.Set("include_groups", strings.Join(types, ","))
+// Supported options: [IncludeGroups], [Market], [Limit], [Offset].
+func (c *Client) GetArtistAlbums(ctx context.Context, artistID ID, opts ...RequestOption) (*SimpleAlbumPage, error) {
+	artistID, err := resolveID(artistID, "artist")
+	if err != nil {
+		return nil, err
 	}
-
-	if query := values.Encode(); query != "" {
-		spotifyURL += "?" + query
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
 	}
+	ctx = o.context(ctx)
+	spotifyURL := c.apiURL(o.urlParams, "artists/", string(artistID), "/albums")
 
 	var p SimpleAlbumPage
 
-	err := c.get(ctx, spotifyURL, &p)
+	err = c.get(ctx, spotifyURL, &p)
 	if err != nil {
 		return nil, err
 	}
 
 	return &p, nil
 }
+
+// GetArtistAlbumsAll gets all of an artist's albums, following the pages of
+// [Client.GetArtistAlbums].  If progress isn't nil, it is called after each
+// page.
+//
+// Without the [Market] option, Spotify returns a separate entry for each
+// regional release of an album.  If dedupe is true, these duplicates are
+// removed with [DedupeAlbums].
+//
+// Supported options: [IncludeGroups], [Market], [Limit], [Offset].
+func (c *Client) GetArtistAlbumsAll(ctx context.Context, artistID ID, dedupe bool, progress ProgressFunc, opts ...RequestOption) ([]SimpleAlbum, error) {
+	page, err := c.GetArtistAlbums(ctx, artistID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	albums := make([]SimpleAlbum, 0, page.Total)
+	for {
+		albums = append(albums, page.Albums...)
+		if progress != nil {
+			progress(len(albums), int(page.Total))
+		}
+		err = c.NextPage(ctx, page)
+		if errors.Is(err, ErrNoMorePages) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if dedupe {
+		albums = DedupeAlbums(albums)
+	}
+	return albums, nil
+}
+
+// DedupeAlbums removes regional duplicates from a list of albums, keeping the
+// first entry of each.  Albums are considered duplicates if they have the same
+// name (ignoring case), type, release date, number of tracks and artists, even
+// if their IDs differ.
+func DedupeAlbums(albums []SimpleAlbum) []SimpleAlbum {
+	type albumKey struct {
+		name, albumType, releaseDate, artists string
+		tracks                                Numeric
+	}
+
+	seen := make(map[albumKey]bool, len(albums))
+	deduped := make([]SimpleAlbum, 0, len(albums))
+	for _, a := range albums {
+		artists := make([]string, len(a.Artists))
+		for i, artist := range a.Artists {
+			artists[i] = string(artist.ID)
+		}
+		key := albumKey{
+			name:        strings.ToLower(a.Name),
+			albumType:   a.AlbumType,
+			releaseDate: a.ReleaseDate,
+			artists:     strings.Join(artists, ","),
+			tracks:      a.TotalTracks,
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, a)
+	}
+	return deduped
+}
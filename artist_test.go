@@ -16,7 +16,9 @@ package spotify
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -157,10 +159,14 @@ func TestRelatedArtistsWithFloats(t *testing.T) {
 }
 
 func TestArtistAlbumsFiltered(t *testing.T) {
-	client, server := testClientString(http.StatusOK, albumsResponse)
+	client, server := testClientString(http.StatusOK, albumsResponse, func(r *http.Request) {
+		if got := r.URL.Query().Get("include_groups"); got != "single" {
+			t.Errorf("Expected include_groups=single, got %q", got)
+		}
+	})
 	defer server.Close()
 
-	albums, err := client.GetArtistAlbums(context.Background(), "1vCWHaC5f2uS3yhpwWbIA6", []AlbumType{AlbumTypeSingle}, Limit(2))
+	albums, err := client.GetArtistAlbums(context.Background(), "1vCWHaC5f2uS3yhpwWbIA6", IncludeGroups(AlbumTypeSingle), Limit(2))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -185,3 +191,40 @@ func TestArtistAlbumsFiltered(t *testing.T) {
 		t.Errorf("Wrong Spotify external URL: want %s, got %s\n", url, spotifyURL)
 	}
 }
+
+func TestGetArtistAlbumsAll(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") == "2" {
+			fmt.Fprint(w, `{"items": [
+				{"id": "c", "name": "Later", "album_type": "album", "release_date": "2020-01-01", "total_tracks": 10}
+			], "offset": 2, "total": 3}`)
+			return
+		}
+		fmt.Fprintf(w, `{"items": [
+			{"id": "a", "name": "Debut", "album_type": "album", "release_date": "2019-01-01", "total_tracks": 9},
+			{"id": "b", "name": "Debut", "album_type": "album", "release_date": "2019-01-01", "total_tracks": 9}
+		], "offset": 0, "total": 3, "next": "%s/artists/artist/albums?offset=2"}`, server.URL)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	var calls int
+	albums, err := client.GetArtistAlbumsAll(context.Background(), "artist", false, func(fetched, total int) {
+		calls++
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(albums) != 3 || calls != 2 {
+		t.Errorf("Expected 3 albums in 2 pages, got %d albums and %d calls", len(albums), calls)
+	}
+
+	albums, err = client.GetArtistAlbumsAll(context.Background(), "artist", true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(albums) != 2 || albums[0].ID != "a" || albums[1].ID != "c" {
+		t.Errorf("Expected regional duplicate to be removed, got %v", albums)
+	}
+}
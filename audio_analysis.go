@@ -1,9 +1,6 @@
 package spotify
 
-import (
-	"context"
-	"fmt"
-)
+import "context"
 
 // AudioAnalysis contains a [detailed audio analysis] for a single track
 // identified by its unique [Spotify ID].
@@ -102,11 +99,15 @@ type AnalysisTrack struct {
 //
 // [audio analysis]: https://developer.spotify.com/documentation/web-api/reference/get-audio-analysis
 func (c *Client) GetAudioAnalysis(ctx context.Context, id ID) (*AudioAnalysis, error) {
-	url := fmt.Sprintf("%saudio-analysis/%s", c.baseURL, id)
+	id, err := resolveID(id, "track")
+	if err != nil {
+		return nil, err
+	}
+	spotifyURL := c.apiURL(nil, "audio-analysis/", string(id))
 
 	temp := AudioAnalysis{}
 
-	err := c.get(ctx, url, &temp)
+	err = c.get(ctx, spotifyURL, &temp)
 	if err != nil {
 		return nil, err
 	}
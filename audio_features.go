@@ -2,8 +2,9 @@ package spotify
 
 import (
 	"context"
-	"fmt"
-	"strings"
+	"errors"
+	"net/http"
+	"net/url"
 )
 
 // AudioFeatures contains various high-level acoustic attributes
@@ -113,16 +114,105 @@ const (
 // Objects are returned in the order requested.  If an object
 // is not found, a nil value is returned in the appropriate position.
 func (c *Client) GetAudioFeatures(ctx context.Context, ids ...ID) ([]*AudioFeatures, error) {
-	url := fmt.Sprintf("%saudio-features?ids=%s", c.baseURL, strings.Join(toStringSlice(ids), ","))
+	ids, err := resolveIDs(ids, "track")
+	if err != nil {
+		return nil, err
+	}
+	spotifyURL := c.apiURL(url.Values{"ids": {joinIDs(ids)}}, "audio-features")
 
 	temp := struct {
 		F []*AudioFeatures `json:"audio_features"`
 	}{}
 
-	err := c.get(ctx, url, &temp)
+	err = c.get(ctx, spotifyURL, &temp)
 	if err != nil {
 		return nil, err
 	}
 
 	return temp.F, nil
 }
+
+// AudioFeaturesProvider provides audio features for tracks.  [Client]
+// implements it with the Web API, which Spotify has removed for new apps.
+// Code that depends on an AudioFeaturesProvider rather than on the client can
+// switch to other sources of audio features, such as [StaticAudioFeatures],
+// an [AudioFeaturesFunc] wrapping an external service, or a combination of
+// them made with [AudioFeaturesChain].
+//
+// Implementations return features in the order requested, with a nil value
+// for tracks they know nothing about.
+type AudioFeaturesProvider interface {
+	GetAudioFeatures(ctx context.Context, ids ...ID) ([]*AudioFeatures, error)
+}
+
+var _ AudioFeaturesProvider = (*Client)(nil)
+
+// AudioFeaturesFunc adapts a function to an [AudioFeaturesProvider].  It can
+// be used to plug in external backends.
+type AudioFeaturesFunc func(ctx context.Context, ids ...ID) ([]*AudioFeatures, error)
+
+// GetAudioFeatures implements [AudioFeaturesProvider] by calling f.
+func (f AudioFeaturesFunc) GetAudioFeatures(ctx context.Context, ids ...ID) ([]*AudioFeatures, error) {
+	return f(ctx, ids...)
+}
+
+// StaticAudioFeatures is an [AudioFeaturesProvider] backed by user-supplied
+// data, for example features computed ahead of time or exported before the
+// endpoint was removed.
+type StaticAudioFeatures map[ID]*AudioFeatures
+
+// GetAudioFeatures implements [AudioFeaturesProvider].
+func (s StaticAudioFeatures) GetAudioFeatures(ctx context.Context, ids ...ID) ([]*AudioFeatures, error) {
+	features := make([]*AudioFeatures, len(ids))
+	for i, id := range ids {
+		features[i] = s[id]
+	}
+	return features, nil
+}
+
+// AudioFeaturesChain returns an [AudioFeaturesProvider] that asks each of
+// providers in turn for the tracks that the previous ones had no features
+// for.  A provider that fails with a 403 or 404 status, which Spotify uses for
+// endpoints that an app can't access, is skipped; other errors are returned.
+func AudioFeaturesChain(providers ...AudioFeaturesProvider) AudioFeaturesProvider {
+	return audioFeaturesChain(providers)
+}
+
+type audioFeaturesChain []AudioFeaturesProvider
+
+func (chain audioFeaturesChain) GetAudioFeatures(ctx context.Context, ids ...ID) ([]*AudioFeatures, error) {
+	features := make([]*AudioFeatures, len(ids))
+	missing := make([]int, len(ids))
+	for i := range ids {
+		missing[i] = i
+	}
+
+	for _, p := range chain {
+		if len(missing) == 0 {
+			break
+		}
+		query := make([]ID, len(missing))
+		for i, pos := range missing {
+			query[i] = ids[pos]
+		}
+		found, err := p.GetAudioFeatures(ctx, query...)
+		var e Error
+		if errors.As(err, &e) && (e.Status == http.StatusForbidden || e.Status == http.StatusNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var stillMissing []int
+		for i, pos := range missing {
+			if i < len(found) && found[i] != nil {
+				features[pos] = found[i]
+				continue
+			}
+			stillMissing = append(stillMissing, pos)
+		}
+		missing = stillMissing
+	}
+	return features, nil
+}
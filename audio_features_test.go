@@ -93,3 +93,33 @@ func TestAudioFeatures(t *testing.T) {
 		t.Errorf("Want key G, got %v\n", features[0].Key)
 	}
 }
+
+func TestAudioFeaturesChain(t *testing.T) {
+	client, server := testClientString(http.StatusForbidden, `{"error": {"status": 403, "message": "Forbidden"}}`)
+	defer server.Close()
+
+	static := StaticAudioFeatures{"a": {ID: "a", Tempo: 120}}
+	var asked []ID
+	external := AudioFeaturesFunc(func(ctx context.Context, ids ...ID) ([]*AudioFeatures, error) {
+		asked = ids
+		features := make([]*AudioFeatures, len(ids))
+		for i, id := range ids {
+			if id == "b" {
+				features[i] = &AudioFeatures{ID: id, Tempo: 90}
+			}
+		}
+		return features, nil
+	})
+
+	chain := AudioFeaturesChain(client, static, external)
+	features, err := chain.GetAudioFeatures(context.Background(), "a", "b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(asked) != 2 || asked[0] != "b" || asked[1] != "c" {
+		t.Errorf("Expected only missing tracks to be requested, got %v", asked)
+	}
+	if features[0].Tempo != 120 || features[1].Tempo != 90 || features[2] != nil {
+		t.Errorf("Unexpected features: %v", features)
+	}
+}
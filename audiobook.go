@@ -0,0 +1,95 @@
+package spotify
+
+import "context"
+
+// SimpleAudiobook contains basic data about an audiobook.
+type SimpleAudiobook struct {
+	// The authors of the audiobook.
+	Authors []Author `json:"authors"`
+
+	// A list of the countries in which the audiobook can be played,
+	// identified by their [ISO 3166-1 alpha-2] code.
+	//
+	// [ISO 3166-1 alpha-2]: https://en.wikipedia.org/wiki/ISO_3166-1_alpha-2
+	AvailableMarkets []string `json:"available_markets"`
+
+	// The copyright statements of the audiobook.
+	Copyrights []Copyright `json:"copyrights"`
+
+	// A description of the audiobook.
+	Description string `json:"description"`
+
+	// The edition of the audiobook, for example "Unabridged".
+	Edition string `json:"edition"`
+
+	// Whether or not the audiobook has explicit content
+	// (true = yes it does; false = no it does not OR unknown).
+	Explicit bool `json:"explicit"`
+
+	// Known external URLs for this audiobook.
+	ExternalURLs map[string]string `json:"external_urls"`
+
+	// A link to the Web API endpoint providing full details
+	// of the audiobook.
+	Href string `json:"href"`
+
+	// The SpotifyID for the audiobook.
+	ID ID `json:"id"`
+
+	// The cover art for the audiobook in various sizes,
+	// widest first.
+	Images Images `json:"images"`
+
+	// A list of the languages used in the audiobook, identified by
+	// their [ISO 639] code.
+	//
+	// [ISO 639]: https://en.wikipedia.org/wiki/ISO_639
+	Languages []string `json:"languages"`
+
+	// The media type of the audiobook.
+	MediaType string `json:"media_type"`
+
+	// The name of the audiobook.
+	Name string `json:"name"`
+
+	// The narrators of the audiobook.
+	Narrators []Narrator `json:"narrators"`
+
+	// The publisher of the audiobook.
+	Publisher string `json:"publisher"`
+
+	// The number of chapters in the audiobook.
+	TotalChapters Numeric `json:"total_chapters"`
+
+	// The object type: “audiobook”.
+	Type string `json:"type"`
+
+	// The Spotify URI for the audiobook.
+	URI URI `json:"uri"`
+}
+
+// Author is an author of an audiobook.
+type Author struct {
+	Name string `json:"name"`
+}
+
+// Narrator is a narrator of an audiobook.
+type Narrator struct {
+	Name string `json:"name"`
+}
+
+// SearchAudiobooks searches for audiobooks that match query, and returns the
+// first page of them.  It is a shorthand for [Client.Search] with
+// [SearchTypeAudiobook].  Audiobooks are only available in some markets.
+//
+// Supported options: [Limit], [Market], [Offset].
+func (c *Client) SearchAudiobooks(ctx context.Context, query string, opts ...RequestOption) (*SimpleAudiobookPage, error) {
+	result, err := c.Search(ctx, query, SearchTypeAudiobook, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if result.Audiobooks == nil {
+		return &SimpleAudiobookPage{}, nil
+	}
+	return result.Audiobooks, nil
+}
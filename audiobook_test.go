@@ -0,0 +1,36 @@
+package spotify
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestSearchAudiobooks(t *testing.T) {
+	client, server := testClientString(http.StatusOK, `{"audiobooks": {"total": 1, "items": [{
+		"id": "7iHfbu1YPACw6oZPAFJtqe",
+		"name": "Dune",
+		"authors": [{"name": "Frank Herbert"}],
+		"narrators": [{"name": "Scott Brick"}, {"name": "Orlagh Cassidy"}],
+		"edition": "Unabridged",
+		"total_chapters": 52,
+		"type": "audiobook"
+	}]}}`, func(r *http.Request) {
+		if got := r.URL.Query().Get("type"); got != "audiobook" {
+			t.Errorf("Expected an audiobook search, got type %q", got)
+		}
+	})
+	defer server.Close()
+
+	page, err := client.SearchAudiobooks(context.Background(), "dune", Market("US"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Audiobooks) != 1 {
+		t.Fatalf("Expected 1 audiobook, got %d", len(page.Audiobooks))
+	}
+	book := page.Audiobooks[0]
+	if book.Name != "Dune" || book.Authors[0].Name != "Frank Herbert" || len(book.Narrators) != 2 || book.TotalChapters != 52 {
+		t.Errorf("Unexpected audiobook %+v", book)
+	}
+}
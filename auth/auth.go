@@ -5,8 +5,12 @@ import (
 	"errors"
 	"net/http"
 	"os"
+	"reflect"
+	"strings"
 
 	"golang.org/x/oauth2"
+
+	"github.com/zmb3/spotify/v2"
 )
 
 const (
@@ -69,8 +73,132 @@ const (
 	ScopeUserTopRead = "user-top-read"
 	// ScopeStreaming seeks permission to play music and control playback on your other devices.
 	ScopeStreaming = "streaming"
+	// ScopeUserReadPlaybackPosition seeks read access to a user's position in
+	// the episodes they listened to, which the Web API reports as resume points.
+	ScopeUserReadPlaybackPosition = "user-read-playback-position"
+	// ScopeAppRemoteControl seeks permission to control the Spotify app on
+	// the user's iOS and Android devices, through the App Remote SDKs.
+	ScopeAppRemoteControl = "app-remote-control"
+	// ScopeUserSoaLink seeks permission to link a partner account to a
+	// Spotify user, for Spotify Open Access partners.
+	ScopeUserSoaLink = "user-soa-link"
+	// ScopeUserSoaUnlink seeks permission to unlink a partner account from a
+	// Spotify user, for Spotify Open Access partners.
+	ScopeUserSoaUnlink = "user-soa-unlink"
+	// ScopeSoaManageEntitlements seeks permission to modify the entitlements
+	// of linked users, for Spotify Open Access partners.
+	ScopeSoaManageEntitlements = "soa-manage-entitlements"
+	// ScopeSoaManagePartner seeks permission to update the partner
+	// information, for Spotify Open Access partners.
+	ScopeSoaManagePartner = "soa-manage-partner"
+	// ScopeSoaCreatePartner seeks permission to create new partners, for
+	// Spotify Open Access platform partners.
+	ScopeSoaCreatePartner = "soa-create-partner"
+)
+
+// AllScopes returns every scope that the Web API defines, except for the
+// Spotify Open Access scopes, which are only granted to partners.  Asking
+// users for every scope is rarely a good idea; it's mostly useful for
+// development tools.
+func AllScopes() []string {
+	return []string{
+		ScopeImageUpload,
+		ScopePlaylistReadPrivate,
+		ScopePlaylistModifyPublic,
+		ScopePlaylistModifyPrivate,
+		ScopePlaylistReadCollaborative,
+		ScopeUserFollowModify,
+		ScopeUserFollowRead,
+		ScopeUserLibraryModify,
+		ScopeUserLibraryRead,
+		ScopeUserReadPrivate,
+		ScopeUserReadEmail,
+		ScopeUserReadCurrentlyPlaying,
+		ScopeUserReadPlaybackState,
+		ScopeUserModifyPlaybackState,
+		ScopeUserReadRecentlyPlayed,
+		ScopeUserTopRead,
+		ScopeStreaming,
+		ScopeUserReadPlaybackPosition,
+		ScopeAppRemoteControl,
+	}
+}
+
+// Feature is a group of related functionality, used with [ScopesFor] to find
+// the scopes to request.
+type Feature int
+
+// Features that can be passed to [ScopesFor].
+const (
+	// FeatureProfile reads the user's profile, including their email address.
+	FeatureProfile Feature = iota
+	// FeatureLibraryRead reads the user's saved tracks, albums, shows and
+	// episodes.
+	FeatureLibraryRead
+	// FeatureLibraryModify saves items to and removes them from the user's library.
+	FeatureLibraryModify
+	// FeaturePlaylistsRead reads the user's playlists, including private
+	// and collaborative ones.
+	FeaturePlaylistsRead
+	// FeaturePlaylistsModify creates and edits the user's playlists,
+	// including their cover images.
+	FeaturePlaylistsModify
+	// FeatureFollowRead reads the artists and users that the user follows.
+	FeatureFollowRead
+	// FeatureFollowModify follows and unfollows artists, users and playlists.
+	FeatureFollowModify
+	// FeatureTopItems reads the user's top artists and tracks.
+	FeatureTopItems
+	// FeatureListeningHistory reads the user's recently played tracks and
+	// their position in episodes.
+	FeatureListeningHistory
+	// FeaturePlaybackRead reads the user's devices, playback state and queue.
+	FeaturePlaybackRead
+	// FeaturePlaybackControl controls playback on the user's devices.
+	FeaturePlaybackControl
+	// FeatureWebPlaybackSDK plays music in the browser with the Web Playback SDK.
+	FeatureWebPlaybackSDK
 )
 
+var featureScopes = map[Feature][]string{
+	FeatureProfile:          {ScopeUserReadPrivate, ScopeUserReadEmail},
+	FeatureLibraryRead:      {ScopeUserLibraryRead},
+	FeatureLibraryModify:    {ScopeUserLibraryModify},
+	FeaturePlaylistsRead:    {ScopePlaylistReadPrivate, ScopePlaylistReadCollaborative},
+	FeaturePlaylistsModify:  {ScopePlaylistModifyPublic, ScopePlaylistModifyPrivate, ScopeImageUpload},
+	FeatureFollowRead:       {ScopeUserFollowRead},
+	FeatureFollowModify:     {ScopeUserFollowModify, ScopePlaylistModifyPublic, ScopePlaylistModifyPrivate},
+	FeatureTopItems:         {ScopeUserTopRead},
+	FeatureListeningHistory: {ScopeUserReadRecentlyPlayed, ScopeUserReadPlaybackPosition},
+	FeaturePlaybackRead:     {ScopeUserReadPlaybackState, ScopeUserReadCurrentlyPlaying},
+	FeaturePlaybackControl:  {ScopeUserReadPlaybackState, ScopeUserModifyPlaybackState},
+	FeatureWebPlaybackSDK:   {ScopeStreaming, ScopeUserReadEmail, ScopeUserReadPrivate},
+}
+
+// ScopesFor returns the scopes needed by the given features, without
+// duplicates, for use with [WithScopes]:
+//
+//	auth := spotifyauth.New(spotifyauth.WithScopes(spotifyauth.ScopesFor(
+//		spotifyauth.FeatureTopItems,
+//		spotifyauth.FeatureFollowRead,
+//	)...))
+//
+// To check the scopes needed by specific [spotify.Client] methods, see
+// [spotify.RequiredScopes] and [Authenticator.ValidateScopes].
+func ScopesFor(features ...Feature) []string {
+	seen := make(map[string]bool)
+	var scopes []string
+	for _, f := range features {
+		for _, s := range featureScopes[f] {
+			if !seen[s] {
+				seen[s] = true
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	return scopes
+}
+
 // Authenticator provides convenience functions for implementing the OAuth2 flow.
 // You should always use [New] to make them.
 //
@@ -84,7 +212,9 @@ const (
 //	token, err := a.Token(state, r)
 //	client := a.Client(token)
 type Authenticator struct {
-	config *oauth2.Config
+	config    *oauth2.Config
+	states    StateStore
+	refreshes *refreshGroup
 }
 
 type AuthenticatorOption func(a *Authenticator)
@@ -106,12 +236,31 @@ func WithClientSecret(secret string) AuthenticatorOption {
 }
 
 // WithScopes configures the oauth scopes that the client should request.
+// It replaces any scopes configured by earlier options; use
+// [WithAdditionalScopes] to add to them instead.
 func WithScopes(scopes ...string) AuthenticatorOption {
 	return func(a *Authenticator) {
 		a.config.Scopes = scopes
 	}
 }
 
+// WithAdditionalScopes adds to the oauth scopes that the client should
+// request, keeping those configured by earlier options.  Scopes that are
+// already configured are not added again.
+func WithAdditionalScopes(scopes ...string) AuthenticatorOption {
+	return func(a *Authenticator) {
+		seen := make(map[string]bool, len(a.config.Scopes)+len(scopes))
+		result := make([]string, 0, len(a.config.Scopes)+len(scopes))
+		for _, s := range append(a.config.Scopes[:len(a.config.Scopes):len(a.config.Scopes)], scopes...) {
+			if !seen[s] {
+				seen[s] = true
+				result = append(result, s)
+			}
+		}
+		a.config.Scopes = result
+	}
+}
+
 // WithRedirectURL configures a redirect url for oauth flows. It must exactly match one of the
 // URLs specified in your Spotify developer account.
 func WithRedirectURL(url string) AuthenticatorOption {
@@ -134,7 +283,8 @@ func New(opts ...AuthenticatorOption) *Authenticator {
 	}
 
 	a := &Authenticator{
-		config: cfg,
+		config:    cfg,
+		refreshes: &refreshGroup{calls: make(map[string]*refreshCall)},
 	}
 
 	for _, opt := range opts {
@@ -160,11 +310,19 @@ func (a Authenticator) AuthURL(state string, opts ...oauth2.AuthCodeOption) stri
 
 // Token pulls an authorization code from an HTTP request and attempts to exchange
 // it for an access token.  The standard use case is to call Token from the handler
-// that handles requests to your application's redirect URL.
+// that handles requests to your application's redirect URL.  If Spotify reports
+// an error, such as the user declining access, an [AuthError] is returned.
+//
+// State must be the state that was passed to [Authenticator.AuthURL] for the
+// user's browser session, usually kept in a cookie, so that a redirect made
+// for another session is rejected.  If the authenticator was configured with
+// [WithStateStore], the state must also be one made by
+// [Authenticator.NewState], and it is consumed, so that it can't be used
+// again; otherwise, [ErrInvalidState] is returned.
 func (a Authenticator) Token(ctx context.Context, state string, r *http.Request, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
 	values := r.URL.Query()
 	if e := values.Get("error"); e != "" {
-		return nil, errors.New("spotify: auth failed - " + e)
+		return nil, AuthError{Code: e, Description: values.Get("error_description")}
 	}
 	code := values.Get("code")
 	if code == "" {
@@ -174,20 +332,64 @@ func (a Authenticator) Token(ctx context.Context, state string, r *http.Request,
 	if actualState != state {
 		return nil, errors.New("spotify: redirect state parameter doesn't match")
 	}
-	return a.config.Exchange(ctx, code, opts...)
+	if a.states != nil {
+		ok, err := a.states.Consume(actualState)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrInvalidState
+		}
+	}
+	return a.Exchange(ctx, code, opts...)
 }
 
 // RefreshToken returns a new token if an access token has expired.
-// If it has not expired, return the existing token.
+// If it has not expired, return the existing token.  If the refresh token was
+// revoked, an [AuthError] with the code [ErrorCodeInvalidGrant] is returned.
+//
+// It is safe to call RefreshToken from several goroutines with the same
+// expired token: only one of them refreshes it, and the others wait for and
+// share the result, so that the Accounts Service doesn't rate limit the
+// application.  A caller that is waiting for another's refresh returns early
+// if its own ctx is done.
+//
+// The clients and token sources made by an Authenticator don't use
+// RefreshToken: each of them refreshes its own token once, however many
+// requests it is making, but separate clients made from the same token
+// refresh it separately.
 func (a Authenticator) RefreshToken(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error) {
+	if token.Valid() || a.refreshes == nil {
+		return a.refreshToken(ctx, token)
+	}
+	return a.refreshes.do(ctx, token.RefreshToken, func() (*oauth2.Token, error) {
+		return a.refreshToken(ctx, token)
+	})
+}
+
+func (a Authenticator) refreshToken(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error) {
 	src := a.config.TokenSource(ctx, token)
-	return src.Token()
+	t, err := src.Token()
+	if err != nil {
+		return nil, tokenError(err)
+	}
+	return t, nil
 }
 
 // Exchange is like [Token], except it allows you to manually specify the access
 // code instead of pulling it out of an HTTP request.
 func (a Authenticator) Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
-	return a.config.Exchange(ctx, code, opts...)
+	t, err := a.config.Exchange(ctx, code, opts...)
+	if err != nil {
+		return nil, tokenError(err)
+	}
+	return t, nil
+}
+
+// TokenSource returns a token source that returns token until it expires, and
+// then refreshes it.
+func (a Authenticator) TokenSource(ctx context.Context, token *oauth2.Token) oauth2.TokenSource {
+	return a.config.TokenSource(ctx, token)
 }
 
 // Client creates a [net/http.Client] that will use the specified access token
@@ -195,3 +397,89 @@ func (a Authenticator) Exchange(ctx context.Context, code string, opts ...oauth2
 func (a Authenticator) Client(ctx context.Context, token *oauth2.Token) *http.Client {
 	return a.config.Client(ctx, token)
 }
+
+// ValidateScopes checks that the scopes granted to token include all of the
+// scopes required by the named [spotify.Client] methods, as reported by
+// [spotify.RequiredScopes].  If token is nil, or doesn't record which scopes
+// were granted, the scopes configured with [WithScopes] are checked instead,
+// which allows applications to verify their configuration at startup.
+//
+// Methods that modify playlists are satisfied by either
+// [ScopePlaylistModifyPublic] or [ScopePlaylistModifyPrivate], as each of
+// them is enough for playlists with the matching visibility.  Names that
+// aren't methods of spotify.Client are reported as errors.
+//
+// The returned error lists every unknown method and missing scope, along
+// with the methods that need it.
+func (a Authenticator) ValidateScopes(token *oauth2.Token, methods ...string) error {
+	granted := make(map[string]bool)
+	if scope, ok := tokenScope(token); ok {
+		for _, s := range strings.Fields(scope) {
+			granted[s] = true
+		}
+	} else {
+		for _, s := range a.config.Scopes {
+			granted[s] = true
+		}
+	}
+
+	var unknown, missing []string
+	neededBy := make(map[string][]string)
+	for _, method := range methods {
+		if _, ok := clientType.MethodByName(method); !ok {
+			unknown = append(unknown, method)
+			continue
+		}
+		for _, s := range spotify.RequiredScopes(method) {
+			requirement := s
+			if alt, ok := alternativeScopes[s]; ok {
+				if granted[alt] {
+					continue
+				}
+				requirement = ScopePlaylistModifyPublic + " or " + ScopePlaylistModifyPrivate
+			}
+			if granted[s] {
+				continue
+			}
+			if neededBy[requirement] == nil {
+				missing = append(missing, requirement)
+			}
+			if n := len(neededBy[requirement]); n == 0 || neededBy[requirement][n-1] != method {
+				neededBy[requirement] = append(neededBy[requirement], method)
+			}
+		}
+	}
+
+	var msgs []string
+	if len(unknown) > 0 {
+		msgs = append(msgs, "unknown methods: "+strings.Join(unknown, ", "))
+	}
+	for _, s := range missing {
+		msgs = append(msgs, "missing scope "+s+" (needed by "+strings.Join(neededBy[s], ", ")+")")
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.New("spotify: " + strings.Join(msgs, "; "))
+}
+
+// clientType is used to check the method names passed to ValidateScopes.
+var clientType = reflect.TypeOf(&spotify.Client{})
+
+// alternativeScopes maps each scope that can stand in for another to that
+// scope: either playlist modification scope is enough for the playlists of
+// the matching visibility.
+var alternativeScopes = map[string]string{
+	ScopePlaylistModifyPublic:  ScopePlaylistModifyPrivate,
+	ScopePlaylistModifyPrivate: ScopePlaylistModifyPublic,
+}
+
+// tokenScope returns the space-separated list of scopes that Spotify reported
+// when it issued token.
+func tokenScope(token *oauth2.Token) (string, bool) {
+	if token == nil {
+		return "", false
+	}
+	scope, ok := token.Extra("scope").(string)
+	return scope, ok
+}
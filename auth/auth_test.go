@@ -0,0 +1,88 @@
+package spotifyauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestRefreshTokenDeduplicates(t *testing.T) {
+	var refreshes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&refreshes, 1)
+		// give the other goroutines time to join the refresh
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "new%d", "token_type": "Bearer", "expires_in": 3600}`, n)
+	}))
+	defer server.Close()
+
+	a := New(WithClientID("id"), WithClientSecret("secret"))
+	a.config.Endpoint.TokenURL = server.URL
+	expired := &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}
+
+	var wg sync.WaitGroup
+	tokens := make([]*oauth2.Token, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token, err := a.RefreshToken(context.Background(), expired)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			tokens[i] = token
+		}(i)
+	}
+	wg.Wait()
+
+	if refreshes != 1 {
+		t.Errorf("Expected 1 refresh, got %d", refreshes)
+	}
+	for _, token := range tokens {
+		if token == nil || token.AccessToken != "new1" {
+			t.Errorf("Expected the shared token, got %v", token)
+		}
+	}
+}
+
+func TestRefreshTokenWaiterCanceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token": "new", "token_type": "Bearer", "expires_in": 3600}`)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	a := New(WithClientID("id"), WithClientSecret("secret"))
+	a.config.Endpoint.TokenURL = server.URL
+	expired := &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}
+
+	go a.RefreshToken(context.Background(), expired)
+	// wait for the first refresh to be in flight
+	for {
+		a.refreshes.mu.Lock()
+		n := len(a.refreshes.calls)
+		a.refreshes.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := a.RefreshToken(ctx, expired); err != context.DeadlineExceeded {
+		t.Errorf("Expected the waiting caller to give up, got %v", err)
+	}
+}
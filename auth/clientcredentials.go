@@ -0,0 +1,62 @@
+package spotifyauth
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// ClientCredentials implements the [client credentials flow], in which an
+// application authenticates as itself rather than on behalf of a user.  It
+// can't be used to access a user's private data.  Unlike the tokens issued by
+// [Authenticator], client credentials tokens have no refresh token; the
+// clients and token sources made by ClientCredentials request a new token
+// whenever the current one expires.
+//
+// You should always use [NewClientCredentials] to make them.
+//
+// Example:
+//
+//	creds := spotifyauth.NewClientCredentials()
+//	client := spotify.New(creds.Client(ctx))
+//
+// [client credentials flow]: https://developer.spotify.com/documentation/web-api/tutorials/client-credentials-flow
+type ClientCredentials struct {
+	config *clientcredentials.Config
+}
+
+// NewClientCredentials creates a [ClientCredentials] configured for Spotify's
+// token endpoint.  It accepts the same options as [New]; only [WithClientID]
+// and [WithClientSecret] apply to this flow.
+//
+// By default, it pulls your client ID and secret key from the SPOTIFY_ID and SPOTIFY_SECRET environment variables.
+func NewClientCredentials(opts ...AuthenticatorOption) *ClientCredentials {
+	a := New(opts...)
+	return &ClientCredentials{
+		config: &clientcredentials.Config{
+			ClientID:     a.config.ClientID,
+			ClientSecret: a.config.ClientSecret,
+			TokenURL:     a.config.Endpoint.TokenURL,
+		},
+	}
+}
+
+// Token requests a new access token.
+func (c *ClientCredentials) Token(ctx context.Context) (*oauth2.Token, error) {
+	return c.config.Token(ctx)
+}
+
+// TokenSource returns a token source that reuses its token until it expires,
+// and then requests a new one.
+func (c *ClientCredentials) TokenSource(ctx context.Context) oauth2.TokenSource {
+	return c.config.TokenSource(ctx)
+}
+
+// Client creates a [net/http.Client] that authenticates its requests with
+// client credentials tokens, requesting new ones as needed.  You will
+// typically pass this to [github.com/zmb3/spotify.New].
+func (c *ClientCredentials) Client(ctx context.Context) *http.Client {
+	return c.config.Client(ctx)
+}
@@ -0,0 +1,108 @@
+package spotifyauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// clientCredentialsServer serves a token endpoint for the client credentials
+// flow, issuing tokens that expire after expiresIn seconds.
+func clientCredentialsServer(t *testing.T, expiresIn int, requests *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(requests, 1)
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if grant := r.PostForm.Get("grant_type"); grant != "client_credentials" {
+			t.Errorf("Expected the client_credentials grant, got %q", grant)
+		}
+		if id, secret, ok := r.BasicAuth(); !ok || id != "id" || secret != "secret" {
+			t.Errorf("Expected the client ID and secret, got %q, %q", id, secret)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "token%d", "token_type": "Bearer", "expires_in": %d}`, n, expiresIn)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testClientCredentials(server *httptest.Server) *ClientCredentials {
+	c := NewClientCredentials(WithClientID("id"), WithClientSecret("secret"))
+	c.config.TokenURL = server.URL
+	return c
+}
+
+func TestClientCredentials(t *testing.T) {
+	c := NewClientCredentials(WithClientID("id"), WithClientSecret("secret"))
+	if c.config.ClientID != "id" || c.config.ClientSecret != "secret" || c.config.TokenURL != TokenURL {
+		t.Errorf("Unexpected config %+v", c.config)
+	}
+
+	var requests int32
+	c = testClientCredentials(clientCredentialsServer(t, 3600, &requests))
+	token, err := c.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "token1" || token.RefreshToken != "" {
+		t.Errorf("Unexpected token %+v", token)
+	}
+}
+
+func TestClientCredentialsTokenSource(t *testing.T) {
+	var requests int32
+	ts := testClientCredentials(clientCredentialsServer(t, 3600, &requests)).TokenSource(context.Background())
+	for i := 0; i < 3; i++ {
+		token, err := ts.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.AccessToken != "token1" {
+			t.Errorf("Expected the token to be reused, got %q", token.AccessToken)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 token request, got %d", requests)
+	}
+
+	// tokens that are about to expire are replaced by new ones
+	requests = 0
+	ts = testClientCredentials(clientCredentialsServer(t, 1, &requests)).TokenSource(context.Background())
+	for i := 1; i <= 2; i++ {
+		token, err := ts.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("token%d", i); token.AccessToken != want {
+			t.Errorf("Expected %q, got %q", want, token.AccessToken)
+		}
+	}
+}
+
+func TestClientCredentialsClient(t *testing.T) {
+	var requests int32
+	c := testClientCredentials(clientCredentialsServer(t, 3600, &requests))
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token1" {
+			t.Errorf("Expected the client credentials token, got %q", auth)
+		}
+	}))
+	defer api.Close()
+
+	client := c.Client(context.Background())
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(api.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 token request, got %d", requests)
+	}
+}
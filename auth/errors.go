@@ -0,0 +1,78 @@
+package spotifyauth
+
+import (
+	"encoding/json"
+	"errors"
+
+	"golang.org/x/oauth2"
+)
+
+// Error codes reported by the Spotify Accounts Service, as defined by
+// [RFC 6749].  They are found in [AuthError.Code].
+//
+// [RFC 6749]: https://www.rfc-editor.org/rfc/rfc6749#section-5.2
+const (
+	// ErrorCodeAccessDenied means that the user declined to authorize the
+	// application.
+	ErrorCodeAccessDenied = "access_denied"
+	// ErrorCodeInvalidGrant means that the authorization code or refresh
+	// token is invalid, expired or revoked, for example because the user
+	// removed the application's access.  The user has to log in again.
+	ErrorCodeInvalidGrant = "invalid_grant"
+	// ErrorCodeInvalidClient means that the client ID or secret is wrong.
+	ErrorCodeInvalidClient = "invalid_client"
+	// ErrorCodeInvalidRequest means that a parameter is missing or invalid,
+	// such as a redirect URL that isn't registered.
+	ErrorCodeInvalidRequest = "invalid_request"
+	// ErrorCodeInvalidScope means that a requested scope doesn't exist.
+	ErrorCodeInvalidScope = "invalid_scope"
+	// ErrorCodeUnsupportedResponseType means that the response type isn't
+	// supported.
+	ErrorCodeUnsupportedResponseType = "unsupported_response_type"
+)
+
+// AuthError is returned when the Spotify Accounts Service reports an error,
+// either in the query of the redirect handled by [Authenticator.Token], or
+// in the response of its token endpoint.
+//
+// For example, to ask users whose consent was revoked to log in again:
+//
+//	var authErr spotifyauth.AuthError
+//	if errors.As(err, &authErr) && authErr.Code == spotifyauth.ErrorCodeInvalidGrant {
+//		// redirect to the login page
+//	}
+type AuthError struct {
+	// Code is the error code, such as [ErrorCodeAccessDenied].
+	Code string `json:"error"`
+	// Description is a human-readable explanation, if Spotify sent one.
+	Description string `json:"error_description"`
+	// Err is the error returned by the token endpoint request, if any.
+	Err error `json:"-"`
+}
+
+func (e AuthError) Error() string {
+	msg := "spotify: auth failed - " + e.Code
+	if e.Description != "" {
+		msg += ": " + e.Description
+	}
+	return msg
+}
+
+// Unwrap returns the error returned by the token endpoint request, if any.
+func (e AuthError) Unwrap() error {
+	return e.Err
+}
+
+// tokenError converts an error returned by the token endpoint into an
+// [AuthError], if the response reports an error code.
+func tokenError(err error) error {
+	var re *oauth2.RetrieveError
+	if !errors.As(err, &re) {
+		return err
+	}
+	e := AuthError{Err: err}
+	if json.Unmarshal(re.Body, &e) != nil || e.Code == "" {
+		return err
+	}
+	return e
+}
@@ -0,0 +1,87 @@
+package spotifyauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestAuthError(t *testing.T) {
+	tests := []struct {
+		err  AuthError
+		want string
+	}{
+		{AuthError{Code: ErrorCodeAccessDenied}, "spotify: auth failed - access_denied"},
+		{AuthError{Code: ErrorCodeInvalidGrant, Description: "Refresh token revoked"}, "spotify: auth failed - invalid_grant: Refresh token revoked"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+	}
+
+	cause := errors.New("cause")
+	var err error = fmt.Errorf("refreshing: %w", AuthError{Code: ErrorCodeInvalidClient, Err: cause})
+	var authErr AuthError
+	if !errors.As(err, &authErr) || authErr.Code != ErrorCodeInvalidClient {
+		t.Errorf("Expected errors.As to find the AuthError in %v", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected the AuthError to unwrap to its cause")
+	}
+}
+
+func TestTokenError(t *testing.T) {
+	plain := errors.New("connection refused")
+	if got := tokenError(plain); got != plain {
+		t.Errorf("Expected other errors to be returned as is, got %v", got)
+	}
+
+	noCode := &oauth2.RetrieveError{Body: []byte(`<html>Bad Gateway</html>`)}
+	if got := tokenError(noCode); got != error(noCode) {
+		t.Errorf("Expected responses without an error code to be returned as is, got %v", got)
+	}
+
+	re := &oauth2.RetrieveError{Body: []byte(`{"error": "invalid_client", "error_description": "Invalid client secret"}`)}
+	authErr, ok := tokenError(re).(AuthError)
+	if !ok || authErr.Code != ErrorCodeInvalidClient || authErr.Description != "Invalid client secret" || authErr.Err != re {
+		t.Errorf("Unexpected error %#v", tokenError(re))
+	}
+}
+
+func TestTokenErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": "invalid_grant", "error_description": "Refresh token revoked"}`)
+	}))
+	defer server.Close()
+
+	a := New(WithClientID("id"), WithClientSecret("secret"))
+	a.config.Endpoint.TokenURL = server.URL
+	_, err := a.RefreshToken(context.Background(), &oauth2.Token{RefreshToken: "refresh"})
+	authErr, ok := err.(AuthError)
+	if !ok || authErr.Code != ErrorCodeInvalidGrant || authErr.Description != "Refresh token revoked" {
+		t.Errorf("Expected an invalid_grant error, got %#v", err)
+	}
+
+	_, err = a.Exchange(context.Background(), "code")
+	if !errors.As(err, &authErr) || authErr.Code != ErrorCodeInvalidGrant {
+		t.Errorf("Expected an invalid_grant error from Exchange, got %#v", err)
+	}
+}
+
+func TestTokenRedirectError(t *testing.T) {
+	a := New(WithClientID("id"), WithClientSecret("secret"))
+	r := httptest.NewRequest("GET", "/callback?error=access_denied&state=state", nil)
+	_, err := a.Token(context.Background(), "state", r)
+	authErr, ok := err.(AuthError)
+	if !ok || authErr.Code != ErrorCodeAccessDenied || authErr.Err != nil {
+		t.Errorf("Expected an access_denied error, got %#v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package spotifyauth
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// refreshGroup deduplicates concurrent refreshes of the same token: while a
+// refresh is in flight, callers with the same refresh token wait for it and
+// share its result instead of starting their own.  Waiting callers give up
+// when their own context is done.
+type refreshGroup struct {
+	mu    sync.Mutex
+	calls map[string]*refreshCall
+}
+
+type refreshCall struct {
+	done  chan struct{}
+	token *oauth2.Token
+	err   error
+}
+
+func (g *refreshGroup) do(ctx context.Context, key string, refresh func() (*oauth2.Token, error)) (*oauth2.Token, error) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.token, c.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c := &refreshCall{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	c.token, c.err = refresh()
+	close(c.done)
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	return c.token, c.err
+}
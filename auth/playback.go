@@ -0,0 +1,109 @@
+package spotifyauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrNoStreamingScope is returned by [Authenticator.PlaybackToken] when the
+// token wasn't granted [ScopeStreaming], which the Web Playback SDK requires.
+var ErrNoStreamingScope = errors.New("spotify: token wasn't granted the streaming scope")
+
+// PlaybackToken returns an access token for the [Web Playback SDK], refreshing
+// token if it has expired or is about to.  The SDK needs the
+// [ScopeStreaming], [ScopeUserReadEmail] and [ScopeUserReadPrivate] scopes;
+// if token records the scopes it was granted and streaming isn't one of them,
+// [ErrNoStreamingScope] is returned.
+//
+// The returned token is token itself if it didn't need to be refreshed.
+// Otherwise, it replaces token and should be stored in its place.
+//
+// [Web Playback SDK]: https://developer.spotify.com/documentation/web-playback-sdk
+func (a Authenticator) PlaybackToken(ctx context.Context, token *oauth2.Token) (*oauth2.Token, error) {
+	if scope, ok := tokenScope(token); ok && !hasScope(scope, ScopeStreaming) {
+		return nil, ErrNoStreamingScope
+	}
+	// the browser uses the token for a while after fetching it, so make sure
+	// that it doesn't expire right away
+	if token.Valid() && (token.Expiry.IsZero() || time.Until(token.Expiry) > playbackTokenMargin) {
+		return token, nil
+	}
+	refreshable := *token
+	refreshable.AccessToken = ""
+	return a.RefreshToken(ctx, &refreshable)
+}
+
+// playbackTokenMargin is the minimum lifetime of tokens returned by
+// [Authenticator.PlaybackToken].
+const playbackTokenMargin = 5 * time.Minute
+
+func hasScope(scope, want string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
+
+// PlaybackTokenHandler returns a handler that serves access tokens to the Web
+// Playback SDK running in the user's browser, so that the refresh token never
+// leaves the server.  lookup returns the stored token of the user making the
+// request, typically based on a session cookie.  If the token is refreshed,
+// save is called with the new token, unless it is nil.
+//
+// The handler responds with a JSON object such as
+// {"access_token": "...", "expires_in": 3600}, which the SDK's getOAuthToken
+// callback can pass on:
+//
+//	getOAuthToken: cb => fetch("/spotify/token")
+//		.then(r => r.json())
+//		.then(t => cb(t.access_token)),
+//
+// It responds with 401 Unauthorized if lookup fails, 403 Forbidden if the
+// token wasn't granted [ScopeStreaming], and 502 Bad Gateway if the token
+// can't be refreshed.
+func (a Authenticator) PlaybackTokenHandler(
+	lookup func(r *http.Request) (*oauth2.Token, error),
+	save func(r *http.Request, token *oauth2.Token) error,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := lookup(r)
+		if err != nil || token == nil {
+			http.Error(w, "no Spotify token for this session", http.StatusUnauthorized)
+			return
+		}
+		fresh, err := a.PlaybackToken(r.Context(), token)
+		if errors.Is(err, ErrNoStreamingScope) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			http.Error(w, "couldn't refresh the Spotify token", http.StatusBadGateway)
+			return
+		}
+		if fresh != token && save != nil {
+			if err := save(r, fresh); err != nil {
+				http.Error(w, "couldn't save the Spotify token", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		resp := struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int64  `json:"expires_in,omitempty"`
+		}{AccessToken: fresh.AccessToken}
+		if !fresh.Expiry.IsZero() {
+			resp.ExpiresIn = int64(time.Until(fresh.Expiry) / time.Second)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
@@ -0,0 +1,118 @@
+package spotifyauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func scopedToken(scope string, expiry time.Time) *oauth2.Token {
+	token := &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: expiry}
+	return token.WithExtra(map[string]interface{}{"scope": scope})
+}
+
+func TestPlaybackToken(t *testing.T) {
+	a := testAuthenticator(t)
+	ctx := context.Background()
+
+	_, err := a.PlaybackToken(ctx, scopedToken(ScopeUserReadEmail, time.Now().Add(time.Hour)))
+	if !errors.Is(err, ErrNoStreamingScope) {
+		t.Errorf("Expected ErrNoStreamingScope, got %v", err)
+	}
+
+	valid := scopedToken(ScopeStreaming+" "+ScopeUserReadEmail, time.Now().Add(time.Hour))
+	token, err := a.PlaybackToken(ctx, valid)
+	if err != nil || token != valid {
+		t.Errorf("Expected the valid token to be returned as is, got %v, %v", token, err)
+	}
+
+	expiring := scopedToken(ScopeStreaming, time.Now().Add(time.Minute))
+	token, err = a.PlaybackToken(ctx, expiring)
+	if err != nil || token.AccessToken != "token" {
+		t.Errorf("Expected a token about to expire to be refreshed, got %v, %v", token, err)
+	}
+
+	// tokens that don't record their scopes aren't checked
+	unscoped := &oauth2.Token{AccessToken: "old", Expiry: time.Now().Add(time.Hour)}
+	if token, err := a.PlaybackToken(ctx, unscoped); err != nil || token != unscoped {
+		t.Errorf("Expected the unscoped token to be returned as is, got %v, %v", token, err)
+	}
+}
+
+func TestPlaybackTokenHandler(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "invalid_grant"}`))
+	}))
+	defer failing.Close()
+	broken := New(WithClientID("id"), WithClientSecret("secret"))
+	broken.config.Endpoint.TokenURL = failing.URL
+
+	expired := scopedToken(ScopeStreaming, time.Now().Add(-time.Hour))
+	tests := []struct {
+		name   string
+		auth   *Authenticator
+		token  *oauth2.Token
+		status int
+		saved  bool
+	}{
+		{name: "no session", auth: testAuthenticator(t), status: http.StatusUnauthorized},
+		{name: "no streaming scope", auth: testAuthenticator(t), token: scopedToken(ScopeUserReadEmail, time.Now().Add(time.Hour)), status: http.StatusForbidden},
+		{name: "refresh fails", auth: broken, token: expired, status: http.StatusBadGateway},
+		{name: "valid", auth: testAuthenticator(t), token: scopedToken(ScopeStreaming, time.Now().Add(time.Hour)), status: http.StatusOK},
+		{name: "refreshed", auth: testAuthenticator(t), token: expired, status: http.StatusOK, saved: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *oauth2.Token
+			h := tt.auth.PlaybackTokenHandler(
+				func(r *http.Request) (*oauth2.Token, error) {
+					if tt.token == nil {
+						return nil, errors.New("no session")
+					}
+					return tt.token, nil
+				},
+				func(r *http.Request, token *oauth2.Token) error {
+					saved = token
+					return nil
+				},
+			)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", "/spotify/token", nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+			if (saved != nil) != tt.saved {
+				t.Errorf("Expected saved to be %v, got %v", tt.saved, saved)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			if cc := w.Header().Get("Cache-Control"); cc != "no-store" {
+				t.Errorf("Expected Cache-Control no-store, got %q", cc)
+			}
+			var resp struct {
+				AccessToken string `json:"access_token"`
+				ExpiresIn   int64  `json:"expires_in"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			want := tt.token.AccessToken
+			if tt.saved {
+				want = "token"
+			}
+			if resp.AccessToken != want || resp.ExpiresIn <= 0 {
+				t.Errorf("Expected access token %q with an expiry, got %+v", want, resp)
+			}
+		})
+	}
+}
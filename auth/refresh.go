@@ -0,0 +1,57 @@
+package spotifyauth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// RefreshTokenSource returns a token source that obtains access tokens from a
+// stored refresh token, which is all that daemons usually persist.  The first
+// call to its Token method refreshes the token right away.
+//
+// Spotify may rotate refresh tokens, returning a new one along with a new
+// access token.  The token source always uses the latest one, and if
+// onRefresh isn't nil, it is called with every new token so that the refresh
+// token can be persisted again.  onRefresh must not block for long, as
+// requests wait for it.
+func (a Authenticator) RefreshTokenSource(ctx context.Context, refreshToken string, onRefresh func(*oauth2.Token)) oauth2.TokenSource {
+	src := a.config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken})
+	if onRefresh == nil {
+		return src
+	}
+	return &notifyingTokenSource{src: src, notify: onRefresh}
+}
+
+// ClientFromRefreshToken creates a [net/http.Client] that authenticates its
+// requests with access tokens obtained from refreshToken, as described in
+// [Authenticator.RefreshTokenSource].  You will typically pass this to
+// [github.com/zmb3/spotify.New].
+func (a Authenticator) ClientFromRefreshToken(ctx context.Context, refreshToken string, onRefresh func(*oauth2.Token)) *http.Client {
+	return oauth2.NewClient(ctx, a.RefreshTokenSource(ctx, refreshToken, onRefresh))
+}
+
+// notifyingTokenSource calls notify whenever src returns a new token.
+type notifyingTokenSource struct {
+	src    oauth2.TokenSource
+	notify func(*oauth2.Token)
+
+	mu   sync.Mutex
+	last string
+}
+
+func (s *notifyingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	if t.AccessToken != s.last {
+		s.last = t.AccessToken
+		s.notify(t)
+	}
+	return t, nil
+}
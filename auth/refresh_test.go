@@ -0,0 +1,113 @@
+package spotifyauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+// rotatingAuthenticator returns an Authenticator whose token endpoint
+// accepts refresh tokens "refresh1", "refresh2", ... in turn, replacing each
+// of them with the next one.  Tokens expire after expiresIn seconds.
+func rotatingAuthenticator(t *testing.T, expiresIn int) *Authenticator {
+	next := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if grant := r.PostForm.Get("grant_type"); grant != "refresh_token" {
+			t.Errorf("Expected the refresh_token grant, got %q", grant)
+		}
+		if got, want := r.PostForm.Get("refresh_token"), "refresh"+strconv.Itoa(next); got != want {
+			t.Errorf("Expected refresh token %q, got %q", want, got)
+		}
+		next++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "token%d", "refresh_token": "refresh%d", "token_type": "Bearer", "expires_in": %d}`, next-1, next, expiresIn)
+	}))
+	t.Cleanup(server.Close)
+
+	a := New(WithClientID("id"), WithClientSecret("secret"))
+	a.config.Endpoint.TokenURL = server.URL
+	return a
+}
+
+func TestRefreshTokenSource(t *testing.T) {
+	// tokens that are about to expire are refreshed on every call
+	a := rotatingAuthenticator(t, 1)
+	var notified []string
+	ts := a.RefreshTokenSource(context.Background(), "refresh1", func(token *oauth2.Token) {
+		notified = append(notified, token.AccessToken+"/"+token.RefreshToken)
+	})
+	for i := 1; i <= 3; i++ {
+		token, err := ts.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("token%d", i); token.AccessToken != want {
+			t.Errorf("Expected %q, got %q", want, token.AccessToken)
+		}
+	}
+	want := "token1/refresh2 token2/refresh3 token3/refresh4"
+	if got := strings.Join(notified, " "); got != want {
+		t.Errorf("Expected notifications %q, got %q", want, got)
+	}
+}
+
+func TestRefreshTokenSourceReusesToken(t *testing.T) {
+	a := rotatingAuthenticator(t, 3600)
+	notified := 0
+	ts := a.RefreshTokenSource(context.Background(), "refresh1", func(*oauth2.Token) { notified++ })
+	for i := 0; i < 3; i++ {
+		token, err := ts.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.AccessToken != "token1" {
+			t.Errorf("Expected the token to be reused, got %q", token.AccessToken)
+		}
+	}
+	if notified != 1 {
+		t.Errorf("Expected 1 notification, got %d", notified)
+	}
+
+	// onRefresh is optional
+	ts = rotatingAuthenticator(t, 3600).RefreshTokenSource(context.Background(), "refresh1", nil)
+	if _, err := ts.Token(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClientFromRefreshToken(t *testing.T) {
+	a := rotatingAuthenticator(t, 1)
+	var saved string
+	client := a.ClientFromRefreshToken(context.Background(), "refresh1", func(token *oauth2.Token) {
+		saved = token.RefreshToken
+	})
+
+	var auths []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+	}))
+	defer api.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(api.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if got := strings.Join(auths, ", "); got != "Bearer token1, Bearer token2" {
+		t.Errorf("Unexpected Authorization headers %q", got)
+	}
+	if saved != "refresh3" {
+		t.Errorf("Expected the latest refresh token to be saved, got %q", saved)
+	}
+}
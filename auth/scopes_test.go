@@ -0,0 +1,231 @@
+package spotifyauth
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestValidateScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  string
+		methods []string
+		err     string
+	}{
+		{
+			name:    "no scopes needed",
+			methods: []string{"GetAlbum"},
+		},
+		{
+			name:    "granted",
+			scopes:  ScopeUserLibraryRead + " " + ScopeUserModifyPlaybackState,
+			methods: []string{"CurrentUsersTracks", "Play"},
+		},
+		{
+			name:    "missing",
+			scopes:  ScopeUserLibraryRead,
+			methods: []string{"CurrentUsersTracks", "Play", "Pause"},
+			err:     "spotify: missing scope user-modify-playback-state (needed by Play, Pause)",
+		},
+		{
+			name:    "public playlists only",
+			scopes:  ScopePlaylistModifyPublic,
+			methods: []string{"AddTracksToPlaylist"},
+		},
+		{
+			name:    "private playlists only",
+			scopes:  ScopePlaylistModifyPrivate + " " + ScopeImageUpload,
+			methods: []string{"AddTracksToPlaylist", "SetPlaylistImage"},
+		},
+		{
+			name:    "no playlist scope",
+			scopes:  ScopeImageUpload,
+			methods: []string{"AddTracksToPlaylist", "SetPlaylistImage"},
+			err:     "spotify: missing scope playlist-modify-public or playlist-modify-private (needed by AddTracksToPlaylist, SetPlaylistImage)",
+		},
+		{
+			name:    "unknown methods",
+			scopes:  ScopeUserModifyPlaybackState,
+			methods: []string{"Play", "PlayAll", "Skip"},
+			err:     "spotify: unknown methods: PlayAll, Skip",
+		},
+		{
+			name:    "unknown and missing",
+			methods: []string{"Pause", "Stop"},
+			err:     "spotify: unknown methods: Stop; missing scope user-modify-playback-state (needed by Pause)",
+		},
+	}
+
+	a := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := (&oauth2.Token{AccessToken: "token"}).WithExtra(map[string]interface{}{"scope": tt.scopes})
+			err := a.ValidateScopes(token, tt.methods...)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateScopesConfigured(t *testing.T) {
+	a := New(WithScopes(ScopePlaylistModifyPrivate))
+	if err := a.ValidateScopes(nil, "AddTracksToPlaylist"); err != nil {
+		t.Errorf("Expected the configured scopes to be used, got %v", err)
+	}
+	if err := a.ValidateScopes(nil, "Play"); err == nil {
+		t.Error("Expected an error for a scope that isn't configured")
+	}
+}
+
+func TestAllScopes(t *testing.T) {
+	scopes := AllScopes()
+	seen := make(map[string]bool)
+	for _, s := range scopes {
+		if seen[s] {
+			t.Errorf("Duplicate scope %q", s)
+		}
+		seen[s] = true
+	}
+	for _, s := range []string{ScopeStreaming, ScopeUserReadPrivate, ScopePlaylistModifyPrivate, ScopeAppRemoteControl} {
+		if !seen[s] {
+			t.Errorf("Expected %q to be included", s)
+		}
+	}
+	if seen[ScopeSoaCreatePartner] {
+		t.Error("Expected the Open Access scopes to be left out")
+	}
+
+	// callers may modify the result
+	scopes[0] = "modified"
+	if AllScopes()[0] == "modified" {
+		t.Error("Expected each call to return a new slice")
+	}
+}
+
+func TestScopesFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		features []Feature
+		want     []string
+	}{
+		{name: "none"},
+		{
+			name:     "single",
+			features: []Feature{FeatureTopItems},
+			want:     []string{ScopeUserTopRead},
+		},
+		{
+			name:     "duplicates removed",
+			features: []Feature{FeatureProfile, FeatureWebPlaybackSDK},
+			want:     []string{ScopeUserReadPrivate, ScopeUserReadEmail, ScopeStreaming},
+		},
+		{
+			name:     "order kept",
+			features: []Feature{FeaturePlaybackControl, FeaturePlaybackRead},
+			want:     []string{ScopeUserReadPlaybackState, ScopeUserModifyPlaybackState, ScopeUserReadCurrentlyPlaying},
+		},
+		{
+			name:     "unknown feature",
+			features: []Feature{Feature(-1), FeatureLibraryRead},
+			want:     []string{ScopeUserLibraryRead},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScopesFor(tt.features...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScopesForKnownScopes(t *testing.T) {
+	known := make(map[string]bool)
+	for _, s := range AllScopes() {
+		known[s] = true
+	}
+	for f := FeatureProfile; f <= FeatureWebPlaybackSDK; f++ {
+		scopes := ScopesFor(f)
+		if len(scopes) == 0 {
+			t.Errorf("Feature %d has no scopes", f)
+		}
+		for _, s := range scopes {
+			if !known[s] {
+				t.Errorf("Feature %d uses %q, which AllScopes doesn't return", f, s)
+			}
+		}
+	}
+}
+
+func TestWithAdditionalScopes(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []AuthenticatorOption
+		want []string
+	}{
+		{
+			name: "without earlier scopes",
+			opts: []AuthenticatorOption{WithAdditionalScopes(ScopeStreaming)},
+			want: []string{ScopeStreaming},
+		},
+		{
+			name: "after WithScopes",
+			opts: []AuthenticatorOption{
+				WithScopes(ScopeUserReadPrivate),
+				WithAdditionalScopes(ScopeStreaming, ScopeUserReadEmail),
+			},
+			want: []string{ScopeUserReadPrivate, ScopeStreaming, ScopeUserReadEmail},
+		},
+		{
+			name: "duplicates removed",
+			opts: []AuthenticatorOption{
+				WithScopes(ScopeUserReadPrivate, ScopeStreaming),
+				WithAdditionalScopes(ScopeStreaming, ScopeUserReadEmail, ScopeUserReadEmail),
+			},
+			want: []string{ScopeUserReadPrivate, ScopeStreaming, ScopeUserReadEmail},
+		},
+		{
+			name: "replaced by a later WithScopes",
+			opts: []AuthenticatorOption{
+				WithAdditionalScopes(ScopeStreaming),
+				WithScopes(ScopeUserTopRead),
+			},
+			want: []string{ScopeUserTopRead},
+		},
+		{
+			name: "repeated",
+			opts: []AuthenticatorOption{
+				WithAdditionalScopes(ScopeUserTopRead),
+				WithAdditionalScopes(ScopeUserFollowRead),
+			},
+			want: []string{ScopeUserTopRead, ScopeUserFollowRead},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := New(tt.opts...)
+			if !reflect.DeepEqual(a.config.Scopes, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, a.config.Scopes)
+			}
+		})
+	}
+}
+
+func TestWithAdditionalScopesDoesntModifyCallerSlice(t *testing.T) {
+	base := make([]string, 1, 4)
+	base[0] = ScopeUserReadPrivate
+	New(WithScopes(base...), WithAdditionalScopes(ScopeStreaming))
+	if extra := base[:2][1]; extra != "" {
+		t.Errorf("Expected the caller's slice to be left alone, got %q", extra)
+	}
+}
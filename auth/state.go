@@ -0,0 +1,115 @@
+package spotifyauth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+)
+
+// GenerateState returns a random string to use as the state parameter of
+// [Authenticator.AuthURL], which protects the user from CSRF attacks.  It
+// has 128 bits of entropy.
+func GenerateState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// StateStore records the state parameters that were sent to Spotify, so that
+// [Authenticator.Token] can check that a redirect belongs to an authorization
+// request made by the application, and that it isn't replayed.  The store is
+// shared by all users, so it doesn't replace the check that the state belongs
+// to the user's own browser session; it only makes each state single use.
+// Use [NewStateStore] for a store that keeps states in memory, or implement
+// it with a shared database when running several instances.
+type StateStore interface {
+	// Add records a state.
+	Add(state string) error
+	// Consume removes a state, and reports whether it was recorded and
+	// hadn't expired.
+	Consume(state string) (bool, error)
+}
+
+// NewStateStore creates a [StateStore] that keeps states in memory for ttl.
+// Users who take longer than that to log in have to start again.
+func NewStateStore(ttl time.Duration) StateStore {
+	return &memoryStateStore{ttl: ttl, states: make(map[string]time.Time)}
+}
+
+type memoryStateStore struct {
+	ttl    time.Duration
+	mu     sync.Mutex
+	states map[string]time.Time
+}
+
+func (s *memoryStateStore) Add(state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for st, expiry := range s.states {
+		if now.After(expiry) {
+			delete(s.states, st)
+		}
+	}
+	s.states[state] = now.Add(s.ttl)
+	return nil
+}
+
+func (s *memoryStateStore) Consume(state string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expiry, ok := s.states[state]
+	delete(s.states, state)
+	return ok && time.Now().Before(expiry), nil
+}
+
+// WithStateStore configures the authenticator to record the states made by
+// [Authenticator.NewState] in store, and to check and consume them in
+// [Authenticator.Token].
+func WithStateStore(store StateStore) AuthenticatorOption {
+	return func(a *Authenticator) {
+		a.states = store
+	}
+}
+
+// ErrInvalidState is returned by [Authenticator.Token] when the state
+// parameter of the redirect wasn't made by [Authenticator.NewState], expired,
+// or was already used.
+var ErrInvalidState = errors.New("spotify: redirect state parameter is unknown or expired")
+
+// NewState generates a state with [GenerateState] and records it in the store
+// configured with [WithStateStore].  The state must also be bound to the
+// user's browser session, for example with a cookie, and passed to
+// [Authenticator.Token]:
+//
+//	state, err := auth.NewState()
+//	if err != nil {
+//		// handle error
+//	}
+//	http.SetCookie(w, &http.Cookie{Name: "oauth_state", Value: state, HttpOnly: true, Secure: true})
+//	http.Redirect(w, r, auth.AuthURL(state), http.StatusFound)
+//
+//	// then, in the redirect handler, the state is checked against the
+//	// session and consumed:
+//	cookie, err := r.Cookie("oauth_state")
+//	if err != nil {
+//		// handle error
+//	}
+//	token, err := auth.Token(r.Context(), cookie.Value, r)
+func (a Authenticator) NewState() (string, error) {
+	if a.states == nil {
+		return "", errors.New("spotify: NewState requires WithStateStore")
+	}
+	state, err := GenerateState()
+	if err != nil {
+		return "", err
+	}
+	if err := a.states.Add(state); err != nil {
+		return "", err
+	}
+	return state, nil
+}
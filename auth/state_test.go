@@ -0,0 +1,105 @@
+package spotifyauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMemoryStateStore(t *testing.T) {
+	store := NewStateStore(time.Hour)
+	if err := store.Add("state"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := store.Consume("state"); err != nil || !ok {
+		t.Errorf("Expected the state to be consumed, got %v, %v", ok, err)
+	}
+	if ok, _ := store.Consume("state"); ok {
+		t.Error("Expected the state to be single use")
+	}
+	if ok, _ := store.Consume("unknown"); ok {
+		t.Error("Expected an unknown state to be rejected")
+	}
+
+	expiring := NewStateStore(-time.Second)
+	_ = expiring.Add("state")
+	if ok, _ := expiring.Consume("state"); ok {
+		t.Error("Expected an expired state to be rejected")
+	}
+}
+
+func testAuthenticator(t *testing.T, opts ...AuthenticatorOption) *Authenticator {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token": "token", "token_type": "Bearer", "expires_in": 3600}`)
+	}))
+	t.Cleanup(server.Close)
+
+	a := New(append([]AuthenticatorOption{WithClientID("id"), WithClientSecret("secret")}, opts...)...)
+	a.config.Endpoint.TokenURL = server.URL
+	return a
+}
+
+func callback(state string) *http.Request {
+	return httptest.NewRequest("GET", "/callback?code=code&state="+state, nil)
+}
+
+func TestTokenState(t *testing.T) {
+	a := testAuthenticator(t, WithStateStore(NewStateStore(time.Hour)))
+	ctx := context.Background()
+
+	mine, err := a.NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	theirs, err := a.NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a valid state made for another session is rejected, and not consumed
+	if _, err := a.Token(ctx, mine, callback(theirs)); err == nil {
+		t.Error("Expected a state from another session to be rejected")
+	}
+	if _, err := a.Token(ctx, "", callback(theirs)); err == nil {
+		t.Error("Expected an empty session state to be rejected")
+	}
+
+	token, err := a.Token(ctx, mine, callback(mine))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "token" {
+		t.Errorf("Expected the exchanged token, got %q", token.AccessToken)
+	}
+	if _, err := a.Token(ctx, mine, callback(mine)); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected a replayed state to be rejected with ErrInvalidState, got %v", err)
+	}
+
+	// the state is bound to the session, but was never recorded
+	if _, err := a.Token(ctx, "forged", callback("forged")); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected an unknown state to be rejected with ErrInvalidState, got %v", err)
+	}
+	if _, err := a.Token(ctx, theirs, callback(theirs)); err != nil {
+		t.Errorf("Expected the other session's state to still be usable, got %v", err)
+	}
+}
+
+func TestTokenStateWithoutStore(t *testing.T) {
+	a := testAuthenticator(t)
+	ctx := context.Background()
+
+	if _, err := a.NewState(); err == nil {
+		t.Error("Expected NewState to require a store")
+	}
+	if _, err := a.Token(ctx, "mine", callback("theirs")); err == nil {
+		t.Error("Expected a mismatched state to be rejected")
+	}
+	if _, err := a.Token(ctx, "mine", callback("mine")); err != nil {
+		t.Errorf("Expected a matching state to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+// Package authcli obtains Spotify tokens from the command line.
+//
+// It runs the authorization code flow (or the authorization code flow with
+// PKCE, when no client secret is available) against a temporary local HTTP
+// server that receives the redirect from Spotify, and provides helpers to
+// persist and refresh the resulting token.  It is intended for scripts and
+// command-line tools; web applications should use [spotifyauth.Authenticator]
+// directly.
+//
+// Example:
+//
+//	token, err := authcli.Login(ctx, authcli.Config{
+//		ClientID: os.Getenv("SPOTIFY_ID"),
+//		Scopes:   []string{spotifyauth.ScopeUserReadPrivate},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = authcli.SaveToken("token.json", token)
+package authcli
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/oauth2"
+
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
+)
+
+// DefaultRedirectURL is used when [Config.RedirectURL] is empty.  It must be
+// registered as a redirect URI of your application in Spotify's developer
+// dashboard.
+const DefaultRedirectURL = "http://localhost:8080/callback"
+
+// Config describes the application that is requesting access.
+type Config struct {
+	// ClientID of the application.  If empty, the SPOTIFY_ID environment
+	// variable is used.
+	ClientID string
+	// ClientSecret of the application.  If empty, the SPOTIFY_SECRET
+	// environment variable is used.  If that is empty too, the PKCE flow is
+	// used, which doesn't require a secret.
+	ClientSecret string
+	// RedirectURL is the URL that Spotify redirects to after the user has
+	// logged in.  It must point to a local address, where Login will listen
+	// for the redirect.  Defaults to [DefaultRedirectURL].
+	RedirectURL string
+	// Scopes requested from the user.
+	Scopes []string
+	// Out is where the login URL is printed.  Defaults to [os.Stderr].
+	Out io.Writer
+}
+
+func (c Config) authenticator() *spotifyauth.Authenticator {
+	opts := []spotifyauth.AuthenticatorOption{
+		spotifyauth.WithRedirectURL(c.redirectURL()),
+		spotifyauth.WithScopes(c.Scopes...),
+	}
+	if c.ClientID != "" {
+		opts = append(opts, spotifyauth.WithClientID(c.ClientID))
+	}
+	if c.ClientSecret != "" {
+		opts = append(opts, spotifyauth.WithClientSecret(c.ClientSecret))
+	}
+	return spotifyauth.New(opts...)
+}
+
+func (c Config) redirectURL() string {
+	if c.RedirectURL == "" {
+		return DefaultRedirectURL
+	}
+	return c.RedirectURL
+}
+
+func (c Config) clientID() string {
+	if c.ClientID != "" {
+		return c.ClientID
+	}
+	return os.Getenv("SPOTIFY_ID")
+}
+
+func (c Config) usePKCE() bool {
+	return c.ClientSecret == "" && os.Getenv("SPOTIFY_SECRET") == ""
+}
+
+// Login prints a URL for the user to visit, waits for Spotify to redirect back
+// to the local callback server, and exchanges the authorization code for a
+// token.  It returns when the token has been obtained, an error occurs, or
+// ctx is done.
+func Login(ctx context.Context, cfg Config) (*oauth2.Token, error) {
+	redirect, err := url.Parse(cfg.redirectURL())
+	if err != nil {
+		return nil, fmt.Errorf("authcli: invalid redirect URL: %w", err)
+	}
+	out := cfg.Out
+	if out == nil {
+		out = os.Stderr
+	}
+
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	var authOpts, exchangeOpts []oauth2.AuthCodeOption
+	if cfg.usePKCE() {
+		verifier, err := randomString(64)
+		if err != nil {
+			return nil, err
+		}
+		authOpts = append(authOpts,
+			oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+			oauth2.SetAuthURLParam("code_challenge", codeChallenge(verifier)),
+		)
+		exchangeOpts = append(exchangeOpts,
+			oauth2.SetAuthURLParam("code_verifier", verifier),
+			oauth2.SetAuthURLParam("client_id", cfg.clientID()),
+		)
+	}
+
+	auth := cfg.authenticator()
+
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, fmt.Errorf("authcli: couldn't listen for redirect: %w", err)
+	}
+
+	type result struct {
+		token *oauth2.Token
+		err   error
+	}
+	results := make(chan result, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(redirect.Path, func(w http.ResponseWriter, r *http.Request) {
+		token, err := auth.Token(r.Context(), state, r, exchangeOpts...)
+		if err != nil {
+			http.Error(w, "Couldn't get token", http.StatusForbidden)
+		} else {
+			fmt.Fprintln(w, "Login completed, you can close this window.")
+		}
+		select {
+		case results <- result{token, err}:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Close()
+
+	fmt.Fprintln(out, "Please log in to Spotify by visiting the following page in your browser:")
+	fmt.Fprintln(out, auth.AuthURL(state, authOpts...))
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-results:
+		return r.token, r.err
+	}
+}
+
+// Refresh uses the token's refresh token to obtain a new access token, even if
+// the current one hasn't expired yet.
+func Refresh(ctx context.Context, cfg Config, token *oauth2.Token) (*oauth2.Token, error) {
+	if token.RefreshToken == "" {
+		return nil, errors.New("authcli: token has no refresh token")
+	}
+	expired := &oauth2.Token{RefreshToken: token.RefreshToken}
+	refreshed, err := cfg.authenticator().RefreshToken(ctx, expired)
+	if err != nil {
+		return nil, err
+	}
+	// Spotify may not issue a new refresh token, in which case the old
+	// one remains valid
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+	return refreshed, nil
+}
+
+// SaveToken writes token to the named file as JSON.  The file is only readable
+// by the current user.
+func SaveToken(path string, token *oauth2.Token) error {
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// LoadToken reads a token written by [SaveToken].
+func LoadToken(path string) (*oauth2.Token, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("authcli: couldn't decode token: %w", err)
+	}
+	return &token, nil
+}
+
+// randomString returns a URL-safe string made from n random bytes.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// codeChallenge derives the S256 PKCE code challenge from a code verifier.
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,49 @@
+package authcli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestCodeChallenge(t *testing.T) {
+	// example from RFC 7636, appendix B
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	if got, want := codeChallenge(verifier), "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestSaveAndLoadToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	token := &oauth2.Token{
+		AccessToken:  "access_token",
+		TokenType:    "Bearer",
+		RefreshToken: "refresh_token",
+		Expiry:       time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	if err := SaveToken(path, token); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadToken(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.AccessToken != token.AccessToken || loaded.RefreshToken != token.RefreshToken {
+		t.Errorf("Expected %+v, got %+v", token, loaded)
+	}
+	if !loaded.Expiry.Equal(token.Expiry) {
+		t.Errorf("Expected expiry %v, got %v", token.Expiry, loaded.Expiry)
+	}
+}
+
+func TestRefreshRequiresRefreshToken(t *testing.T) {
+	_, err := Refresh(context.Background(), Config{}, &oauth2.Token{AccessToken: "access_token"})
+	if err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,180 @@
+package spotify
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CacheBackend stores API responses so that they can be reused instead of
+// requesting them again.  Implementations must be safe for concurrent use.
+type CacheBackend interface {
+	// Get returns the value stored under key.  The boolean result is false
+	// if there is no such value, or if it has expired.
+	Get(key string) ([]byte, bool, error)
+	// Set stores value under key.  The value expires after ttl; a ttl of zero
+	// means that it never expires.
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete removes the value stored under key, if any.
+	Delete(key string) error
+}
+
+// DefaultCacheTTL is the time that cached responses are kept if [WithCache] is
+// given a ttl of zero.
+const DefaultCacheTTL = 24 * time.Hour
+
+// cachedEndpoints lists the top-level paths whose responses describe catalog
+// entities, which rarely change and can safely be cached.  Shows and episodes
+// aren't included, as their responses carry the user's resume points.
+var cachedEndpoints = map[string]bool{
+	"albums":         true,
+	"artists":        true,
+	"audio-analysis": true,
+	"audio-features": true,
+	"tracks":         true,
+}
+
+// WithCache configures the client to store responses for catalog entities,
+// such as artists, albums and tracks, in backend and to reuse them for up to
+// ttl.  Requests for user data, playlists, playback state, shows and
+// episodes, and requests made with [TokenMarket], are never cached, as their
+// responses depend on the user; [WithOfflineMode] keeps the last responses to
+// those in a backend of its own, and [WithPlaylistCache] does the same for
+// playlist items.  Errors from the backend are ignored, and fall back to
+// requesting the API.
+//
+// Combined with [NewFileCache], this lets command-line tools keep caches
+// across runs.
+func WithCache(backend CacheBackend, ttl time.Duration) ClientOption {
+	return func(client *Client) {
+		if ttl == 0 {
+			ttl = DefaultCacheTTL
+		}
+		client.cache = backend
+		client.cacheTTL = ttl
+	}
+}
+
+// WithPlaylistCache configures [Client.GetAllPlaylistItems] to keep the items
+// of the playlists it fetches in backend for up to ttl, under the playlist's
+// snapshot ID, which changes whenever the playlist is modified.  If ttl is
+// zero, [DefaultCacheTTL] is used.
+//
+// Playlists may be private, and their items depend on the user's market, but
+// the cached items aren't keyed by user.  Like the backend of
+// [WithOfflineMode], backend must only be used by clients acting for the same
+// user, and shouldn't be a backend shared with [WithCache].  Requests made
+// with [TokenMarket] aren't cached.
+func WithPlaylistCache(backend CacheBackend, ttl time.Duration) ClientOption {
+	return func(client *Client) {
+		if ttl == 0 {
+			ttl = DefaultCacheTTL
+		}
+		client.playlistCache = backend
+		client.playlistCacheTTL = ttl
+	}
+}
+
+// cacheKey returns the key under which the response for rawURL is cached, and
+// whether it should be cached at all.
+func (c *Client) cacheKey(rawURL string) (string, bool) {
+	if c.cache == nil || !strings.HasPrefix(rawURL, c.baseURL) {
+		return "", false
+	}
+	endpoint := strings.TrimPrefix(rawURL, c.baseURL)
+	if i := strings.IndexAny(endpoint, "/?"); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+	if !cachedEndpoints[endpoint] {
+		return "", false
+	}
+	// the market of the token depends on the user
+	if u, err := url.Parse(rawURL); err != nil || u.Query().Get("market") == MarketFromToken {
+		return "", false
+	}
+	return c.acceptLanguage + " " + rawURL, true
+}
+
+// FileCache is a [CacheBackend] that stores each value in a file in a
+// directory.  You should always use [NewFileCache] to make them.
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache creates a cache that stores values in dir, creating the
+// directory if necessary.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+// path returns the name of the file that stores the value for key.  Keys are
+// hashed, as they may contain characters that aren't valid in file names.
+func (f *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get implements [CacheBackend].
+func (f *FileCache) Get(key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	// each file starts with the expiry time, in nanoseconds since the epoch
+	if len(data) < 8 {
+		return nil, false, nil
+	}
+	expiry := int64(binary.BigEndian.Uint64(data))
+	if expiry != 0 && time.Now().UnixNano() > expiry {
+		return nil, false, nil
+	}
+	return data[8:], true, nil
+}
+
+// Set implements [CacheBackend].  Values are written to a temporary file
+// first, so that readers never see a partially written value.
+func (f *FileCache) Set(key string, value []byte, ttl time.Duration) error {
+	var expiry int64
+	if ttl > 0 {
+		expiry = time.Now().Add(ttl).UnixNano()
+	}
+	data := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(data, uint64(expiry))
+	copy(data[8:], value)
+
+	tmp, err := os.CreateTemp(f.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(key))
+}
+
+// Delete implements [CacheBackend].
+func (f *FileCache) Delete(key string) error {
+	err := os.Remove(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
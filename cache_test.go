@@ -0,0 +1,101 @@
+package spotify
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFileCache(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := cache.Get("missing"); ok || err != nil {
+		t.Errorf("Expected a miss, got %v, %v", ok, err)
+	}
+	if err := cache.Set("key", []byte("value"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	data, ok, err := cache.Get("key")
+	if err != nil || !ok || string(data) != "value" {
+		t.Errorf("Expected a hit, got %q, %v, %v", data, ok, err)
+	}
+	if err := cache.Delete("key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := cache.Get("key"); ok {
+		t.Error("Expected the value to be deleted")
+	}
+
+	if err := cache.Set("expired", []byte("value"), time.Nanosecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if _, ok, _ := cache.Get("expired"); ok {
+		t.Error("Expected the value to have expired")
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	requests := 0
+	client, server := testClientFile(http.StatusOK, "test_data/find_artist.txt", func(*http.Request) {
+		requests++
+	})
+	defer server.Close()
+
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	WithCache(cache, time.Hour)(client)
+
+	for i := 0; i < 2; i++ {
+		artist, err := client.GetArtist(context.Background(), "0TnOYISbd1XYRBk9myaseg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if artist.Name != "Pitbull" {
+			t.Errorf("Unexpected artist %q", artist.Name)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+
+	// playback state is never cached
+	for i := 0; i < 2; i++ {
+		_, _ = client.PlayerDevices(context.Background())
+	}
+	if requests != 3 {
+		t.Errorf("Expected uncached requests, got %d", requests)
+	}
+}
+
+func TestCacheKeyUserData(t *testing.T) {
+	client := New(http.DefaultClient)
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	WithCache(cache, time.Hour)(client)
+
+	tests := []struct {
+		path      string
+		cacheable bool
+	}{
+		{"tracks/a", true},
+		{"tracks/a?market=SE", true},
+		{"tracks/a?market=from_token", false},
+		{"shows/a", false},
+		{"episodes/a", false},
+		{"me/tracks", false},
+	}
+	for _, test := range tests {
+		if _, ok := client.cacheKey(client.baseURL + test.path); ok != test.cacheable {
+			t.Errorf("%s: expected cacheable %v, got %v", test.path, test.cacheable, ok)
+		}
+	}
+}
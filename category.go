@@ -2,7 +2,11 @@ package spotify
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strconv"
 )
 
 // Category is used by Spotify to tag items in.  For example, on the Spotify
@@ -11,7 +15,7 @@ type Category struct {
 	// A link to the Web API endpoint returning full details of the category
 	Endpoint string `json:"href"`
 	// The category icon, in various sizes
-	Icons []Image `json:"icons"`
+	Icons Images `json:"icons"`
 	// The Spotify category ID.  This isn't a base-62 Spotify ID, its just
 	// a short string that describes and identifies the category (ie "party").
 	ID string `json:"id"`
@@ -19,13 +23,36 @@ type Category struct {
 	Name string `json:"name"`
 }
 
+// Icon returns the rendition of the category's icon whose width is closest to
+// width, or the largest one if width is zero.  It returns the zero Image if
+// the category has no icon.
+func (c Category) Icon(width int) Image {
+	if width == 0 {
+		return c.Icons.Largest()
+	}
+	return c.Icons.Closest(width)
+}
+
+// DownloadIcon downloads the rendition of the category's icon selected by
+// [Category.Icon] and writes it to dst.  If client is nil, [http.DefaultClient]
+// is used.
+func (c Category) DownloadIcon(ctx context.Context, dst io.Writer, width int, client *http.Client) error {
+	icon := c.Icon(width)
+	if icon.URL == "" {
+		return errors.New("spotify: category has no icon")
+	}
+	return icon.DownloadContext(ctx, dst, client)
+}
+
 // GetCategory gets a single category used to tag items in Spotify.
 //
 // Supported options: [Country], [Locale].
 func (c *Client) GetCategory(ctx context.Context, id string, opts ...RequestOption) (Category, error) {
 	cat := Category{}
 	spotifyURL := fmt.Sprintf("%sbrowse/categories/%s", c.baseURL, id)
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	if params := o.urlParams.Encode(); params != "" {
 		spotifyURL += "?" + params
 	}
 
@@ -35,10 +62,21 @@ func (c *Client) GetCategory(ctx context.Context, id string, opts ...RequestOpti
 
 // GetCategoryPlaylists gets a list of Spotify playlists tagged with a particular category.
 //
-// Supported options: [Country], [Limit], [Offset].
+// Spotify has restricted this endpoint for most apps.  With the
+// [SearchFallback] option, if Spotify refuses the request, the category's
+// name is looked up and public playlists matching it are searched for
+// instead.  The result then has its SearchFallback field set, and no Next or
+// Previous URLs; use the [Offset] option to get further pages.
+//
+// Supported options: [Country], [Limit], [Offset], [SearchFallback].
 func (c *Client) GetCategoryPlaylists(ctx context.Context, catID string, opts ...RequestOption) (*SimplePlaylistPage, error) {
 	spotifyURL := fmt.Sprintf("%sbrowse/categories/%s/playlists", c.baseURL, catID)
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	if params := o.urlParams.Encode(); params != "" {
 		spotifyURL += "?" + params
 	}
 
@@ -46,7 +84,11 @@ func (c *Client) GetCategoryPlaylists(ctx context.Context, catID string, opts ..
 		Playlists SimplePlaylistPage `json:"playlists"`
 	}{}
 
-	err := c.get(ctx, spotifyURL, &wrapper)
+	err = c.get(ctx, spotifyURL, &wrapper)
+	var e Error
+	if o.searchFallback && errors.As(err, &e) && (e.Status == http.StatusForbidden || e.Status == http.StatusNotFound) {
+		return c.searchCategoryPlaylists(ctx, catID, o)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -54,12 +96,50 @@ func (c *Client) GetCategoryPlaylists(ctx context.Context, catID string, opts ..
 	return &wrapper.Playlists, nil
 }
 
+// searchCategoryPlaylists searches for public playlists matching the name of
+// the category, with the country, limit and offset in o.
+func (c *Client) searchCategoryPlaylists(ctx context.Context, catID string, o requestOptions) (*SimplePlaylistPage, error) {
+	var catOpts, searchOpts []RequestOption
+	if country := o.urlParams.Get("country"); country != "" {
+		catOpts = append(catOpts, Country(country))
+		searchOpts = append(searchOpts, Market(country))
+	}
+	if limit, err := strconv.Atoi(o.urlParams.Get("limit")); err == nil {
+		searchOpts = append(searchOpts, Limit(limit))
+	}
+	if offset, err := strconv.Atoi(o.urlParams.Get("offset")); err == nil {
+		searchOpts = append(searchOpts, Offset(offset))
+	}
+
+	cat, err := c.GetCategory(ctx, catID, catOpts...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.Search(ctx, cat.Name, SearchTypePlaylist, searchOpts...)
+	if err != nil {
+		return nil, err
+	}
+	page := result.Playlists
+	if page == nil {
+		page = &SimplePlaylistPage{}
+	}
+	page.Next = ""
+	page.Previous = ""
+	page.SearchFallback = true
+	return page, nil
+}
+
 // GetCategories gets a list of categories used to tag items in Spotify
 //
 // Supported options: [Country], [Locale], [Limit], [Offset].
 func (c *Client) GetCategories(ctx context.Context, opts ...RequestOption) (*CategoryPage, error) {
 	spotifyURL := c.baseURL + "browse/categories"
-	if query := processOptions(opts...).urlParams.Encode(); query != "" {
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	if query := o.urlParams.Encode(); query != "" {
 		spotifyURL += "?" + query
 	}
 
@@ -67,10 +147,38 @@ func (c *Client) GetCategories(ctx context.Context, opts ...RequestOption) (*Cat
 		Categories CategoryPage `json:"categories"`
 	}{}
 
-	err := c.get(ctx, spotifyURL, &wrapper)
+	err = c.get(ctx, spotifyURL, &wrapper)
 	if err != nil {
 		return nil, err
 	}
 
 	return &wrapper.Categories, nil
 }
+
+// GetCategoriesAll gets all of the categories used to tag items in Spotify,
+// following the pages of [Client.GetCategories].  The pages of this endpoint
+// are wrapped in an object, so they can't be followed with [Client.NextPage].
+//
+// Supported options: [Country], [Locale], [Limit], [Offset].
+func (c *Client) GetCategoriesAll(ctx context.Context, opts ...RequestOption) ([]Category, error) {
+	page, err := c.GetCategories(ctx, append([]RequestOption{Limit(50)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]Category, 0, page.Total)
+	for {
+		categories = append(categories, page.Categories...)
+		if page.Next == "" {
+			return categories, nil
+		}
+
+		var next struct {
+			Categories CategoryPage `json:"categories"`
+		}
+		if err := c.get(ctx, page.Next, &next); err != nil {
+			return nil, err
+		}
+		page = &next.Categories
+	}
+}
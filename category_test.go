@@ -1,8 +1,11 @@
 package spotify
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -92,7 +95,7 @@ func TestGetCategoryPlaylistsOpt(t *testing.T) {
 	defer server.Close()
 
 	_, err := client.GetCategoryPlaylists(context.Background(), "id", Limit(5), Offset(10))
-	if want := "Not Found"; err == nil || err.Error() != want {
+	if want := "Not Found (GET /browse/categories/id/playlists)"; err == nil || err.Error() != want {
 		t.Errorf("Expected error: want %v, got %v", want, err)
 	}
 }
@@ -235,3 +238,93 @@ var invalidToken = `
     "message": "Invalid access token"
   }
 }`
+
+func TestGetCategoriesAll(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("locale") != "sv_SE" {
+			t.Error("Expected the locale to be kept on every page")
+		}
+		if r.URL.Query().Get("offset") == "1" {
+			fmt.Fprint(w, `{"categories": {"items": [{"id": "party", "name": "Fest"}], "offset": 1, "total": 2}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"categories": {"items": [{"id": "pop", "name": "Pop"}], "total": 2, "next": "%s/browse/categories?locale=sv_SE&offset=1"}}`, server.URL)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	categories, err := client.GetCategoriesAll(context.Background(), Locale("sv_SE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(categories) != 2 || categories[0].ID != "pop" || categories[1].ID != "party" {
+		t.Errorf("Unexpected categories: %v", categories)
+	}
+}
+
+func TestCategoryIcon(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer server.Close()
+
+	cat := Category{Icons: Images{
+		{Width: 64, URL: server.URL + "/small"},
+		{Width: 274, URL: server.URL + "/large"},
+	}}
+	if icon := cat.Icon(0); icon.Width != 274 {
+		t.Errorf("Expected largest icon, got width %d", icon.Width)
+	}
+	if icon := cat.Icon(50); icon.Width != 64 {
+		t.Errorf("Expected small icon, got width %d", icon.Width)
+	}
+
+	var buf bytes.Buffer
+	if err := cat.DownloadIcon(context.Background(), &buf, 64, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "/small" {
+		t.Errorf("Downloaded wrong icon: %s", buf.String())
+	}
+
+	if err := (Category{}).DownloadIcon(context.Background(), &buf, 0, nil); err == nil {
+		t.Error("Expected an error for a category without an icon")
+	}
+}
+
+func TestGetCategoryPlaylistsSearchFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/browse/categories/party/playlists":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"status": 404, "message": "Not found."}}`)
+		case "/browse/categories/party":
+			fmt.Fprint(w, `{"id": "party", "name": "Party"}`)
+		case "/search":
+			q := r.URL.Query()
+			if q.Get("q") != "Party" || q.Get("type") != "playlist" || q.Get("market") != "DE" || q.Get("offset") != "10" {
+				t.Errorf("Unexpected search %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"playlists": {"total": 1, "next": "https://api.spotify.com/v1/search?offset=11", "items": [{"id": "p1"}]}}`)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+	if _, err := client.GetCategoryPlaylists(context.Background(), "party"); err == nil {
+		t.Error("Expected an error without the fallback")
+	}
+
+	page, err := client.GetCategoryPlaylists(context.Background(), "party", Country("DE"), Offset(10), SearchFallback())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !page.SearchFallback || len(page.Playlists) != 1 || page.Playlists[0].ID != "p1" || page.Next != "" {
+		t.Errorf("Unexpected page %+v", page)
+	}
+}
@@ -0,0 +1,81 @@
+// Command spotify-auth obtains a Spotify OAuth2 token for use in scripts and
+// examples.
+//
+// It runs the authorization code flow with a local callback server and prints
+// the resulting token as JSON, or writes it to a file.  When SPOTIFY_SECRET is
+// not set, the PKCE flow is used instead, which only requires SPOTIFY_ID.
+//
+// Usage:
+//
+//	spotify-auth [-scopes user-read-private,...] [-redirect URL] [-o token.json]
+//	spotify-auth -refresh -o token.json
+//
+// The redirect URL (http://localhost:8080/callback by default) must be
+// registered for your application in Spotify's developer dashboard.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/zmb3/spotify/v2/authcli"
+)
+
+func main() {
+	var (
+		scopes   = flag.String("scopes", "", "comma-separated list of scopes to request")
+		redirect = flag.String("redirect", authcli.DefaultRedirectURL, "redirect URL registered for the application")
+		output   = flag.String("o", "", "file to write the token to (default: standard output)")
+		refresh  = flag.Bool("refresh", false, "refresh the token stored in the file given by -o instead of logging in")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cfg := authcli.Config{RedirectURL: *redirect}
+	if *scopes != "" {
+		cfg.Scopes = strings.Split(*scopes, ",")
+	}
+
+	if *refresh {
+		if *output == "" {
+			log.Fatal("spotify-auth: -refresh requires -o")
+		}
+		token, err := authcli.LoadToken(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		token, err = authcli.Refresh(ctx, cfg, token)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := authcli.SaveToken(*output, token); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	token, err := authcli.Login(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *output != "" {
+		if err := authcli.SaveToken(*output, token); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(token); err != nil {
+		log.Fatal(err)
+	}
+}
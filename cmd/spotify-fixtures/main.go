@@ -0,0 +1,141 @@
+// Command spotify-fixtures refreshes the test data used by this package's
+// tests with live responses from the Spotify Web API.
+//
+// It requests a representative response from a set of endpoints, removes
+// personal information about the authenticated user, and writes each response
+// to a file in the output directory, using the same file names as the
+// test_data directory of this repository.
+//
+// Usage:
+//
+//	spotify-fixtures -token token.json [-o test_data] [-only find_artist.txt,...]
+//
+// The token can be obtained with the spotify-auth command.  Endpoints that
+// require scopes which the token wasn't granted are reported and skipped.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
+	"github.com/zmb3/spotify/v2/authcli"
+)
+
+// fixture is a test data file and the endpoint whose response it contains.
+type fixture struct {
+	file string
+	path string
+}
+
+// fixtures lists the responses that are requested.  The IDs are those of
+// public catalog items that the tests expect.
+var fixtures = []fixture{
+	{"find_artist.txt", "artists/0TnOYISbd1XYRBk9myaseg"},
+	{"artist_top_tracks.txt", "artists/43ZHCT0cAZBISjO8DG9PnE/top-tracks?country=SE"},
+	{"related_artists.txt", "artists/43ZHCT0cAZBISjO8DG9PnE/related-artists"},
+	{"find_album.txt", "albums/0sNOF9WDwhWunNAHPD3Baj"},
+	{"find_album_tracks.txt", "albums/0sNOF9WDwhWunNAHPD3Baj/tracks?limit=1"},
+	{"find_track.txt", "tracks/1zHlj4dQ8ZAtrayhuDDmkY"},
+	{"get_show.txt", "shows/5CfCWKI5pZ28U0uOzXkDHe?market=US"},
+	{"get_show_episodes.txt", "shows/5CfCWKI5pZ28U0uOzXkDHe/episodes?market=US&limit=2"},
+	{"get_episode.txt", "episodes/512ojhOuo1ktJprKbVcKyQ?market=US"},
+	{"new_releases.txt", "browse/new-releases?limit=2"},
+	{"search_artist.txt", "search?q=tania+bowra&type=artist"},
+	{"current_users_tracks.txt", "me/tracks?limit=2"},
+	{"current_users_albums.txt", "me/albums?limit=2"},
+	{"current_users_playlists.txt", "me/playlists?limit=2"},
+	{"current_users_top_artists.txt", "me/top/artists?limit=2"},
+	{"current_users_top_tracks.txt", "me/top/tracks?limit=2"},
+	{"player_recently_played.txt", "me/player/recently-played?limit=2"},
+	{"player_available_devices.txt", "me/player/devices"},
+	{"player_state.txt", "me/player"},
+	{"player_currently_playing.txt", "me/player/currently-playing"},
+	{"get_queue.txt", "me/player/queue"},
+}
+
+func main() {
+	var (
+		tokenFile = flag.String("token", "", "file containing the token, as written by spotify-auth")
+		output    = flag.String("o", "test_data", "directory to write the fixtures to")
+		only      = flag.String("only", "", "comma-separated list of fixture files to refresh (default: all)")
+		baseURL   = flag.String("base", "https://api.spotify.com/v1/", "base URL of the Web API")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+
+	if *tokenFile == "" {
+		log.Fatal("spotify-fixtures: -token is required")
+	}
+	token, err := authcli.LoadToken(*tokenFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	selected := make(map[string]bool)
+	if *only != "" {
+		for _, file := range strings.Split(*only, ",") {
+			selected[file] = true
+		}
+	}
+
+	client := spotifyauth.New().Client(ctx, token)
+	failed := false
+	for _, f := range fixtures {
+		if len(selected) > 0 && !selected[f.file] {
+			continue
+		}
+		if err := refresh(ctx, client, *baseURL, *output, f); err != nil {
+			log.Printf("%s: %v", f.file, err)
+			failed = true
+			continue
+		}
+		log.Printf("%s: ok", f.file)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// refresh requests the response for f and writes it, sanitized, to dir.
+func refresh(ctx context.Context, client *http.Client, baseURL, dir string, f fixture) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+f.path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, body)
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fmt.Errorf("couldn't decode response: %w", err)
+	}
+	data, err := json.MarshalIndent(sanitize(v), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, f.file), append(data, '\n'), 0o644)
+}
@@ -0,0 +1,55 @@
+package main
+
+// privateUserFields are removed from user objects, as they describe the
+// authenticated user rather than the shape of the response.
+var privateUserFields = []string{"email", "birthdate", "country", "product", "explicit_content"}
+
+// sanitize replaces personal information in a decoded JSON response with
+// placeholders.  User objects are anonymized, and the names and IDs of the
+// user's devices are replaced.  Catalog data is left untouched.
+func sanitize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = sanitize(value)
+		}
+		if v["type"] == "user" {
+			anonymizeUser(v)
+		}
+		if _, ok := v["is_active"]; ok {
+			if _, ok := v["volume_percent"]; ok {
+				// a device
+				v["id"] = "device-id"
+				v["name"] = "Device"
+			}
+		}
+		return v
+	case []interface{}:
+		for i, value := range v {
+			v[i] = sanitize(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// anonymizeUser replaces the identity of a user object with a placeholder.
+func anonymizeUser(user map[string]interface{}) {
+	const id = "fixture-user"
+	user["id"] = id
+	user["uri"] = "spotify:user:" + id
+	user["href"] = "https://api.spotify.com/v1/users/" + id
+	user["external_urls"] = map[string]interface{}{
+		"spotify": "https://open.spotify.com/user/" + id,
+	}
+	if _, ok := user["display_name"]; ok {
+		user["display_name"] = "Fixture User"
+	}
+	if _, ok := user["images"]; ok {
+		user["images"] = []interface{}{}
+	}
+	for _, field := range privateUserFields {
+		delete(user, field)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	const response = `{
+		"owner": {"type": "user", "id": "realuser", "display_name": "Real Name", "email": "me@example.com"},
+		"devices": [{"id": "abc123", "name": "Kitchen", "is_active": true, "volume_percent": 50}],
+		"name": "Playlist"
+	}`
+	var v interface{}
+	if err := json.Unmarshal([]byte(response), &v); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(sanitize(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, private := range []string{"realuser", "Real Name", "me@example.com", "abc123", "Kitchen"} {
+		if strings.Contains(got, private) {
+			t.Errorf("Expected %q to be removed from %s", private, got)
+		}
+	}
+	if !strings.Contains(got, `"name":"Playlist"`) {
+		t.Errorf("Expected catalog data to be kept, got %s", got)
+	}
+}
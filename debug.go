@@ -0,0 +1,68 @@
+package spotify
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+)
+
+// WithDebug configures the client to write every request it sends and every
+// response it receives to w, in HTTP/1.1 wire format.  Responses are written
+// after they have been decompressed.
+//
+// Credentials are redacted: the values of the Authorization and Cookie
+// headers, and any client secrets, codes, code verifiers and tokens in
+// request and response bodies, are replaced by "[REDACTED]".  Note that the
+// Authorization header is usually added by the [http.Client]'s transport,
+// after the request has been written to w.
+//
+// Bodies are read into memory in order to be written, so debugging should not
+// be enabled in production.
+func WithDebug(w io.Writer) ClientOption {
+	return func(client *Client) {
+		client.debug = w
+	}
+}
+
+const redacted = "[REDACTED]"
+
+var (
+	// sensitiveHeader matches the values of headers that carry credentials.
+	sensitiveHeader = regexp.MustCompile(`(?im)^((?:Authorization|Proxy-Authorization|Cookie|Set-Cookie):[ \t]*)[^\r\n]*`)
+	// sensitiveParam matches credentials in form-encoded and JSON bodies.
+	sensitiveParam = regexp.MustCompile(`(\b(?:client_secret|access_token|refresh_token|code_verifier|code)(?:=|"\s*:\s*"))[^&"\s]*`)
+)
+
+// redact replaces the credentials in an HTTP dump.
+func redact(dump []byte) []byte {
+	dump = sensitiveHeader.ReplaceAll(dump, []byte("${1}"+redacted))
+	return sensitiveParam.ReplaceAll(dump, []byte("${1}"+redacted))
+}
+
+// dumpRequest writes req to the client's debug writer, if any.
+func (c *Client) dumpRequest(req *http.Request) {
+	if c.debug == nil {
+		return
+	}
+	dump, err := httputil.DumpRequestOut(req, true)
+	if err != nil {
+		fmt.Fprintf(c.debug, "spotify: couldn't dump request: %v\n\n", err)
+		return
+	}
+	fmt.Fprintf(c.debug, "%s\n\n", redact(dump))
+}
+
+// dumpResponse writes resp to the client's debug writer, if any.
+func (c *Client) dumpResponse(resp *http.Response) {
+	if c.debug == nil {
+		return
+	}
+	dump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		fmt.Fprintf(c.debug, "spotify: couldn't dump response: %v\n\n", err)
+		return
+	}
+	fmt.Fprintf(c.debug, "%s\n\n", redact(dump))
+}
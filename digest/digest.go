@@ -0,0 +1,286 @@
+// Package digest builds a list of the recent releases of the artists that a
+// Spotify user follows, the typical workload of a new release notifier.
+//
+// A [Builder] walks the user's followed artists, fetches the releases of each
+// artist concurrently, keeps those released since a given date, and removes
+// releases that are credited to several followed artists.  Requests that are
+// rate limited are retried after the delay requested by Spotify.
+//
+// Example:
+//
+//	b := digest.New(client, digest.WithMarket(spotify.MarketFromToken))
+//	releases, err := b.Build(ctx, time.Now().AddDate(0, 0, -7))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, r := range releases {
+//		fmt.Println(r.Album.ReleaseDate, r.Album.Name)
+//	}
+package digest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// DefaultConcurrency is the number of artists whose releases are fetched at
+// once if [WithConcurrency] isn't used.
+const DefaultConcurrency = 4
+
+// Release is a release by one or more of the followed artists.
+type Release struct {
+	Album spotify.SimpleAlbum
+	// Artists are the followed artists that the release is credited to.
+	Artists []spotify.FullArtist
+}
+
+// Builder builds digests for the user that its client is authenticated as.
+// You should always use [New] to make them.
+type Builder struct {
+	client      *spotify.Client
+	concurrency int
+	types       spotify.AlbumType
+	market      string
+}
+
+// Option configures a [Builder] made by [New].
+type Option func(b *Builder)
+
+// WithConcurrency sets the number of artists whose releases are fetched at
+// once.
+func WithConcurrency(n int) Option {
+	return func(b *Builder) {
+		b.concurrency = n
+	}
+}
+
+// WithAlbumTypes sets the types of releases that are included.  By default,
+// albums and singles are included.
+func WithAlbumTypes(types spotify.AlbumType) Option {
+	return func(b *Builder) {
+		b.types = types
+	}
+}
+
+// WithMarket only includes releases that are available in market, which can
+// be [spotify.MarketFromToken].
+func WithMarket(market string) Option {
+	return func(b *Builder) {
+		b.market = market
+	}
+}
+
+// New creates a builder that uses client, which needs the
+// [spotifyauth.ScopeUserFollowRead] scope.
+func New(client *spotify.Client, opts ...Option) *Builder {
+	b := &Builder{
+		client:      client,
+		concurrency: DefaultConcurrency,
+		types:       spotify.AlbumTypeAlbum | spotify.AlbumTypeSingle,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.concurrency < 1 {
+		b.concurrency = 1
+	}
+
+	return b
+}
+
+// Build returns the releases of the followed artists that were released on or
+// after since, newest first.  Releases whose dates are only known to the year
+// or month are included if that year or month isn't before since.
+func (b *Builder) Build(ctx context.Context, since time.Time) ([]Release, error) {
+	artists, err := b.followedArtists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		releases = make(map[spotify.ID]*Release)
+		keys     = make(map[string]spotify.ID)
+		firstErr error
+		wg       sync.WaitGroup
+		work     = make(chan spotify.FullArtist)
+	)
+	for i := 0; i < b.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for artist := range work {
+				albums, err := b.recentAlbums(ctx, artist.ID, since)
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+					mu.Unlock()
+					continue
+				}
+				for _, album := range albums {
+					// the same release can have several IDs, for example in
+					// different markets
+					if id, ok := keys[releaseKey(album)]; ok {
+						album.ID = id
+					}
+					r, ok := releases[album.ID]
+					if !ok {
+						r = &Release{Album: album}
+						releases[album.ID] = r
+						keys[releaseKey(album)] = album.ID
+					}
+					if !credited(r, artist.ID) {
+						r.Artists = append(r.Artists, artist)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, artist := range artists {
+		select {
+		case work <- artist:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]Release, 0, len(releases))
+	for _, r := range releases {
+		sort.Slice(r.Artists, func(i, j int) bool { return r.Artists[i].Name < r.Artists[j].Name })
+		result = append(result, *r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Album.ReleaseDate != result[j].Album.ReleaseDate {
+			return result[i].Album.ReleaseDate > result[j].Album.ReleaseDate
+		}
+		return result[i].Album.Name < result[j].Album.Name
+	})
+	return result, nil
+}
+
+// followedArtists returns every artist that the user follows.
+func (b *Builder) followedArtists(ctx context.Context) ([]spotify.FullArtist, error) {
+	var artists []spotify.FullArtist
+	opts := []spotify.RequestOption{spotify.Limit(50)}
+	for {
+		var page *spotify.FullArtistCursorPage
+		err := spotify.RetryRateLimited(ctx, func() error {
+			var err error
+			page, err = b.client.CurrentUsersFollowedArtists(ctx, opts...)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		artists = append(artists, page.Artists...)
+		if page.Cursor.After == "" || len(page.Artists) == 0 {
+			return artists, nil
+		}
+		opts = []spotify.RequestOption{spotify.Limit(50), spotify.After(page.Cursor.After)}
+	}
+}
+
+// recentAlbums returns the albums of the artist released since the given
+// time.  Each type of album is fetched separately, as Spotify lists the albums
+// of each type newest first, so that fetching can stop at the first page that
+// reaches an older album.
+func (b *Builder) recentAlbums(ctx context.Context, artist spotify.ID, since time.Time) ([]spotify.SimpleAlbum, error) {
+	var albums []spotify.SimpleAlbum
+	for _, t := range []spotify.AlbumType{spotify.AlbumTypeAlbum, spotify.AlbumTypeSingle, spotify.AlbumTypeAppearsOn, spotify.AlbumTypeCompilation} {
+		if b.types&t == 0 {
+			continue
+		}
+		opts := []spotify.RequestOption{spotify.IncludeGroups(t), spotify.Limit(50)}
+		if b.market != "" {
+			opts = append(opts, spotify.Market(b.market))
+		}
+
+		var page *spotify.SimpleAlbumPage
+		err := spotify.RetryRateLimited(ctx, func() error {
+			var err error
+			page, err = b.client.GetArtistAlbums(ctx, artist, opts...)
+			return err
+		})
+		for err == nil {
+			reachedOlder := false
+			for _, album := range page.Albums {
+				if releasedSince(album, since) {
+					albums = append(albums, album)
+				} else {
+					reachedOlder = true
+				}
+			}
+			if reachedOlder {
+				break
+			}
+			err = spotify.RetryRateLimited(ctx, func() error {
+				return b.client.NextPage(ctx, page)
+			})
+		}
+		if err != nil && !errors.Is(err, spotify.ErrNoMorePages) {
+			return nil, err
+		}
+	}
+	return albums, nil
+}
+
+// releasedSince reports whether album was released on or after since, to the
+// precision of its release date.
+func releasedSince(album spotify.SimpleAlbum, since time.Time) bool {
+	released := album.ReleaseDateTime()
+	if released.IsZero() {
+		return false
+	}
+	switch album.ReleaseDatePrecision {
+	case "year":
+		return released.Year() >= since.Year()
+	case "month":
+		return !released.Before(time.Date(since.Year(), since.Month(), 1, 0, 0, 0, 0, time.UTC))
+	}
+	return !released.Before(time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC))
+}
+
+// credited reports whether the artist is already listed in r.
+func credited(r *Release, artist spotify.ID) bool {
+	for _, a := range r.Artists {
+		if a.ID == artist {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseKey identifies a release across its IDs, by its name, release date
+// and artists.
+func releaseKey(album spotify.SimpleAlbum) string {
+	parts := []string{strings.ToLower(album.Name), album.ReleaseDate}
+	for _, a := range album.Artists {
+		parts = append(parts, string(a.ID))
+	}
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,70 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+func serve(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	switch r.URL.Path {
+	case "/me/following":
+		if q.Get("after") == "" {
+			fmt.Fprint(w, `{"artists": {"items": [{"id": "a1", "name": "One"}], "cursors": {"after": "a1"}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"artists": {"items": [{"id": "a2", "name": "Two"}], "cursors": {"after": null}}}`)
+	case "/artists/a1/albums":
+		switch q.Get("include_groups") {
+		case "album":
+			fmt.Fprint(w, `{"items": [
+				{"id": "old", "name": "Old", "release_date": "2020-01-01", "release_date_precision": "day"}
+			]}`)
+		case "single":
+			fmt.Fprint(w, `{"items": [
+				{"id": "duet", "name": "Duet", "release_date": "2024-03-08", "release_date_precision": "day", "artists": [{"id": "a1"}, {"id": "a2"}]},
+				{"id": "new", "name": "New", "release_date": "2024-03-01", "release_date_precision": "day"}
+			]}`)
+		}
+	case "/artists/a2/albums":
+		if q.Get("include_groups") == "single" {
+			fmt.Fprint(w, `{"items": [
+				{"id": "duet-se", "name": "Duet", "release_date": "2024-03-08", "release_date_precision": "day", "artists": [{"id": "a1"}, {"id": "a2"}]}
+			]}`)
+			return
+		}
+		fmt.Fprint(w, `{"items": []}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(serve))
+	defer server.Close()
+
+	client := spotify.New(http.DefaultClient, spotify.WithBaseURL(server.URL+"/"))
+	releases, err := New(client, WithConcurrency(2)).Build(context.Background(), time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, r := range releases {
+		s := r.Album.Name
+		for _, a := range r.Artists {
+			s += " " + a.Name
+		}
+		got = append(got, s)
+	}
+	want := []string{"Duet One Two", "New One"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected releases %q, got %q", want, got)
+	}
+}
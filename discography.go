@@ -0,0 +1,161 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// discographyWorkers is the number of requests that
+// [Client.GetArtistDiscography] makes concurrently.
+const discographyWorkers = 4
+
+// Discography is an artist's albums, along with all of their tracks.
+type Discography struct {
+	ArtistID ID
+	// Albums are the artist's albums, in the order returned by
+	// [Client.GetArtistAlbums].  The Tracks page of each album contains all
+	// of the album's tracks, and has no next page.
+	Albums []FullAlbum
+}
+
+// GetArtistDiscography gets all of an artist's albums and all of their tracks.
+// Regional duplicates are removed with [DedupeAlbums].
+//
+// This takes a request for every 20 albums, and one for every 50 tracks of
+// albums with more than 50 tracks, on top of the requests needed to list the
+// albums.  A few requests are made concurrently, and requests that are rate
+// limited are retried after the delay requested by Spotify.  If any request
+// fails, the remaining requests are cancelled and the error is returned.
+//
+// Supported options: [IncludeGroups], [Market].
+func (c *Client) GetArtistDiscography(ctx context.Context, artistID ID, opts ...RequestOption) (*Discography, error) {
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	albums, err := c.discographyAlbums(ctx, artistID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var itemOpts []RequestOption
+	if market := o.urlParams.Get("market"); market != "" {
+		itemOpts = append(itemOpts, Market(market))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		full     = make([]FullAlbum, len(albums))
+		batches  = make(chan int)
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	for i := 0; i < discographyWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range batches {
+				end := start + 20
+				if end > len(albums) {
+					end = len(albums)
+				}
+				if err := c.hydrateAlbums(ctx, albums[start:end], full[start:end], itemOpts); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+feed:
+	for start := 0; start < len(albums); start += 20 {
+		select {
+		case batches <- start:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(batches)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &Discography{ArtistID: artistID, Albums: full}, nil
+}
+
+// discographyAlbums lists an artist's albums, with regional duplicates
+// removed.
+func (c *Client) discographyAlbums(ctx context.Context, artistID ID, opts ...RequestOption) ([]SimpleAlbum, error) {
+	var albums []SimpleAlbum
+	err := RetryRateLimited(ctx, func() error {
+		var err error
+		albums, err = c.GetArtistAlbumsAll(ctx, artistID, true, nil, append([]RequestOption{Limit(50)}, opts...)...)
+		return err
+	})
+	return albums, err
+}
+
+// hydrateAlbums gets the full albums for a batch of up to 20 albums, and all
+// of their tracks, and writes them into full.
+func (c *Client) hydrateAlbums(ctx context.Context, albums []SimpleAlbum, full []FullAlbum, opts []RequestOption) error {
+	ids := make([]ID, len(albums))
+	for i, a := range albums {
+		ids[i] = a.ID
+	}
+
+	var fetched []*FullAlbum
+	err := RetryRateLimited(ctx, func() error {
+		var err error
+		fetched, err = c.GetAlbums(ctx, ids, opts...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(fetched) != len(ids) {
+		return errors.New("spotify: unexpected number of albums in response")
+	}
+
+	for i, album := range fetched {
+		if album == nil {
+			// the album isn't available anymore, so keep what we know
+			full[i].SimpleAlbum = albums[i]
+			continue
+		}
+		tracks := album.Tracks.Tracks
+		for len(tracks) < int(album.Tracks.Total) {
+			var page *SimpleTrackPage
+			err := RetryRateLimited(ctx, func() error {
+				var err error
+				page, err = c.GetAlbumTracks(ctx, album.ID, append([]RequestOption{Limit(50), Offset(len(tracks))}, opts...)...)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if len(page.Tracks) == 0 {
+				break
+			}
+			tracks = append(tracks, page.Tracks...)
+		}
+		album.Tracks.Tracks = tracks
+		album.Tracks.Limit = Numeric(len(tracks))
+		album.Tracks.Next = ""
+		full[i] = *album
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetArtistDiscography(t *testing.T) {
+	// 25 albums, so that two batches are needed; album "a0" has 60 tracks
+	const numAlbums = 25
+	var rateLimited int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/artists/artist/albums":
+			if r.URL.Query().Get("market") != "SE" {
+				t.Error("Expected market to be passed on")
+			}
+			var items []string
+			for i := 0; i < numAlbums; i++ {
+				items = append(items, fmt.Sprintf(`{"id": "a%d", "name": "Album %d"}`, i, i))
+			}
+			fmt.Fprintf(w, `{"items": [%s], "total": %d}`, strings.Join(items, ","), numAlbums)
+		case r.URL.Path == "/albums":
+			// the first request is rate limited, and must be retried
+			if atomic.AddInt32(&rateLimited, 1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(rateLimitExceededStatusCode)
+				fmt.Fprint(w, `{"error": {"status": 429, "message": "API rate limit exceeded"}}`)
+				return
+			}
+			var albums []string
+			for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+				tracks, total := 1, 1
+				if id == "a0" {
+					tracks, total = 50, 60
+				}
+				albums = append(albums, fmt.Sprintf(`{"id": "%s", "tracks": {"items": [%s], "total": %d, "next": "more"}}`,
+					id, strings.TrimSuffix(strings.Repeat(`{"name": "track"},`, tracks), ","), total))
+			}
+			fmt.Fprintf(w, `{"albums": [%s]}`, strings.Join(albums, ","))
+		case r.URL.Path == "/albums/a0/tracks":
+			if offset, _ := strconv.Atoi(r.URL.Query().Get("offset")); offset != 50 {
+				t.Errorf("Expected tracks from offset 50, got %d", offset)
+			}
+			fmt.Fprintf(w, `{"items": [%s], "offset": 50, "total": 60}`,
+				strings.TrimSuffix(strings.Repeat(`{"name": "track"},`, 10), ","))
+		default:
+			t.Errorf("Unexpected request for %s", r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	d, err := client.GetArtistDiscography(context.Background(), "artist", Market("SE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Albums) != numAlbums {
+		t.Fatalf("Expected %d albums, got %d", numAlbums, len(d.Albums))
+	}
+	for i, album := range d.Albums {
+		if want := ID(fmt.Sprintf("a%d", i)); album.ID != want {
+			t.Errorf("Expected album %s at position %d, got %s", want, i, album.ID)
+		}
+		if album.Tracks.Next != "" {
+			t.Errorf("Expected album %s to have no next page", album.ID)
+		}
+	}
+	if n := len(d.Albums[0].Tracks.Tracks); n != 60 {
+		t.Errorf("Expected 60 tracks, got %d", n)
+	}
+}
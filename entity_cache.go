@@ -0,0 +1,118 @@
+package spotify
+
+import (
+	"container/list"
+	"context"
+	"net/url"
+	"sync"
+)
+
+// WithEntityCache configures the client to keep the last size tracks, artists
+// and albums that it fetched in memory, by ID.  [Client.GetTrack],
+// [Client.GetTracks], [Client.GetArtist], [Client.GetArtists],
+// [Client.GetAlbum] and [Client.GetAlbums] return cached entities without
+// requesting them, and only request the IDs that aren't cached.
+//
+// Entities are cached separately for each combination of options and
+// language, as those change the response.  Unlike [WithCache], entities are
+// cached until they are evicted, so the cache suits short-lived processes
+// that look up the same entities repeatedly.  The entities returned are
+// copies, but share their slices with the cache, so they must not be modified.
+//
+// A size below 1 disables the entity cache, including one configured by an
+// earlier option.
+func WithEntityCache(size int) ClientOption {
+	return func(client *Client) {
+		if size < 1 {
+			client.entities = nil
+			return
+		}
+		client.entities = newEntityCache(size)
+	}
+}
+
+// entityCache is a least recently used cache of entities.
+type entityCache struct {
+	size int
+
+	mu    sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+}
+
+type entityCacheItem struct {
+	key    string
+	entity interface{}
+}
+
+func newEntityCache(size int) *entityCache {
+	return &entityCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (e *entityCache) get(key string) (interface{}, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	el, ok := e.items[key]
+	if !ok {
+		return nil, false
+	}
+	e.order.MoveToFront(el)
+	return el.Value.(*entityCacheItem).entity, true
+}
+
+func (e *entityCache) add(key string, entity interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if el, ok := e.items[key]; ok {
+		el.Value.(*entityCacheItem).entity = entity
+		e.order.MoveToFront(el)
+		return
+	}
+	e.items[key] = e.order.PushFront(&entityCacheItem{key: key, entity: entity})
+	for e.order.Len() > e.size {
+		oldest := e.order.Back()
+		e.order.Remove(oldest)
+		delete(e.items, oldest.Value.(*entityCacheItem).key)
+	}
+}
+
+// entityKey returns the key under which the entity of the given kind with id
+// is cached, for a request made with query.
+func (c *Client) entityKey(kind string, query url.Values, id ID) string {
+	return kind + " " + c.acceptLanguage + " " + query.Encode() + " " + string(id)
+}
+
+// cachedEntity returns the cached entity of the given kind with id.  Requests
+// with custom headers aren't served from the cache.
+func (c *Client) cachedEntity(ctx context.Context, kind string, query url.Values, id ID) (interface{}, bool) {
+	if c.entities == nil || hasHeaders(ctx) {
+		return nil, false
+	}
+	return c.entities.get(c.entityKey(kind, query, id))
+}
+
+// cacheEntity adds an entity of the given kind with id to the cache.
+func (c *Client) cacheEntity(ctx context.Context, kind string, query url.Values, id ID, entity interface{}) {
+	if c.entities == nil || hasHeaders(ctx) {
+		return
+	}
+	c.entities.add(c.entityKey(kind, query, id), entity)
+}
+
+// uncachedEntities calls hit with the position and entity of each of ids
+// that is cached, and returns the IDs that aren't, with their positions.
+func (c *Client) uncachedEntities(ctx context.Context, kind string, query url.Values, ids []ID, hit func(i int, entity interface{})) (missing []ID, positions []int) {
+	for i, id := range ids {
+		if entity, ok := c.cachedEntity(ctx, kind, query, id); ok {
+			hit(i, entity)
+			continue
+		}
+		missing = append(missing, id)
+		positions = append(positions, i)
+	}
+	return missing, positions
+}
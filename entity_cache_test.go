@@ -0,0 +1,78 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEntityCache(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		if id := strings.TrimPrefix(r.URL.Path, "/tracks/"); id != r.URL.Path {
+			fmt.Fprintf(w, `{"id": %q}`, id)
+			return
+		}
+		var tracks []string
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			tracks = append(tracks, fmt.Sprintf(`{"id": %q}`, id))
+		}
+		fmt.Fprintf(w, `{"tracks": [%s]}`, strings.Join(tracks, ","))
+	}))
+	defer server.Close()
+
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithEntityCache(2))
+	ctx := context.Background()
+	if _, err := client.GetTrack(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	tracks, err := client.GetTracks(ctx, []ID{"a", "b", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 3 || tracks[0].ID != "a" || tracks[1].ID != "b" || tracks[2].ID != "c" {
+		t.Errorf("Unexpected tracks %v", tracks)
+	}
+	// a was evicted by b and c
+	if _, err := client.GetTrack(ctx, "c"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetTrack(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetTrack(ctx, "a", Market("DE")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"/tracks/a", "/tracks?ids=b%2Cc", "/tracks/a", "/tracks/a?market=DE"}
+	if fmt.Sprint(requests) != fmt.Sprint(want) {
+		t.Errorf("Expected requests %v, got %v", want, requests)
+	}
+}
+
+func TestEntityCacheInvalidSize(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			fmt.Fprint(w, `{"id": "a"}`)
+		}))
+		client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithEntityCache(size))
+		if client.entities != nil {
+			t.Errorf("Expected a size of %d to disable the cache", size)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := client.GetTrack(context.Background(), "a"); err != nil {
+				t.Error(err)
+			}
+		}
+		server.Close()
+		if requests != 2 {
+			t.Errorf("Expected 2 requests with a size of %d, got %d", size, requests)
+		}
+	}
+}
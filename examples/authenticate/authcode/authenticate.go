@@ -13,6 +13,7 @@ import (
 	"github.com/zmb3/spotify/v2/auth"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/zmb3/spotify/v2"
 )
@@ -23,13 +24,21 @@ import (
 const redirectURI = "http://localhost:8080/callback"
 
 var (
-	auth  = spotifyauth.New(spotifyauth.WithRedirectURL(redirectURI), spotifyauth.WithScopes(spotifyauth.ScopeUserReadPrivate))
-	ch    = make(chan *spotify.Client)
-	state = "abc123"
+	auth = spotifyauth.New(
+		spotifyauth.WithRedirectURL(redirectURI),
+		spotifyauth.WithScopes(spotifyauth.ScopeUserReadPrivate),
+		spotifyauth.WithStateStore(spotifyauth.NewStateStore(10*time.Minute)),
+	)
+	ch = make(chan *spotify.Client)
 )
 
+// stateCookie holds the state of the authorization request made by the
+// browser, so that the callback only accepts redirects for the same browser.
+const stateCookie = "oauth_state"
+
 func main() {
 	// first start an HTTP server
+	http.HandleFunc("/login", login)
 	http.HandleFunc("/callback", completeAuth)
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		log.Println("Got request for:", r.URL.String())
@@ -41,8 +50,7 @@ func main() {
 		}
 	}()
 
-	url := auth.AuthURL(state)
-	fmt.Println("Please log in to Spotify by visiting the following page in your browser:", url)
+	fmt.Println("Please log in to Spotify by visiting the following page in your browser: http://localhost:8080/login")
 
 	// wait for auth to complete
 	client := <-ch
@@ -55,16 +63,29 @@ func main() {
 	fmt.Println("You are logged in as:", user.ID)
 }
 
+func login(w http.ResponseWriter, r *http.Request) {
+	state, err := auth.NewState()
+	if err != nil {
+		http.Error(w, "Couldn't start login", http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: stateCookie, Value: state, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+	http.Redirect(w, r, auth.AuthURL(state), http.StatusFound)
+}
+
 func completeAuth(w http.ResponseWriter, r *http.Request) {
-	tok, err := auth.Token(r.Context(), state, r)
+	cookie, err := r.Cookie(stateCookie)
+	if err != nil {
+		http.Error(w, "Login wasn't started from this browser", http.StatusForbidden)
+		return
+	}
+	// the state must match this browser's cookie, and is consumed from the
+	// store so that it can't be used again
+	tok, err := auth.Token(r.Context(), cookie.Value, r)
 	if err != nil {
 		http.Error(w, "Couldn't get token", http.StatusForbidden)
 		log.Fatal(err)
 	}
-	if st := r.FormValue("state"); st != state {
-		http.NotFound(w, r)
-		log.Fatalf("State mismatch: %s != %s\n", st, state)
-	}
 
 	// use the token to get an authenticated client
 	client := spotify.New(auth.Client(r.Context(), tok))
@@ -0,0 +1,211 @@
+// Package followers tracks the follower counts and popularity of a set of
+// artists over time, for label and marketing dashboards.
+//
+// A [Tracker] periodically takes a snapshot of the artists, appends it to the
+// time series kept in a [Storage], and reports how each artist changed since
+// the previous snapshot.
+//
+// Example:
+//
+//	t := followers.New(client, artistIDs, followers.FileStorage("followers.json"),
+//		followers.WithInterval(time.Hour))
+//	log.Fatal(t.Run(ctx, func(deltas []followers.Delta) {
+//		for _, d := range deltas {
+//			fmt.Printf("%s: %+d followers\n", d.Name, d.Followers)
+//		}
+//	}))
+package followers
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// DefaultInterval is the time between snapshots if [WithInterval] isn't used.
+const DefaultInterval = 24 * time.Hour
+
+// Sample is the state of an artist at a point in time.
+type Sample struct {
+	Artist     spotify.ID `json:"artist"`
+	Name       string     `json:"name"`
+	Time       time.Time  `json:"time"`
+	Followers  int        `json:"followers"`
+	Popularity int        `json:"popularity"`
+}
+
+// Delta is the change of an artist between two samples.
+type Delta struct {
+	Artist spotify.ID
+	Name   string
+	// From and To are the times of the two samples.
+	From, To time.Time
+	// Followers and Popularity are the changes in follower count and
+	// popularity from the first sample to the second.
+	Followers  int
+	Popularity int
+}
+
+// Tracker takes snapshots of a set of artists.  A Tracker must not be used
+// concurrently.  You should always use [New] to make them.
+type Tracker struct {
+	client   *spotify.Client
+	artists  []spotify.ID
+	storage  Storage
+	interval time.Duration
+	onError  func(error)
+	now      func() time.Time
+}
+
+// Option configures a [Tracker] made by [New].
+type Option func(t *Tracker)
+
+// WithInterval sets the time between snapshots taken by [Tracker.Run].
+// Intervals that aren't positive are replaced by [DefaultInterval].
+func WithInterval(d time.Duration) Option {
+	return func(t *Tracker) {
+		t.interval = d
+	}
+}
+
+// WithErrorHandler sets a function that [Tracker.Run] calls with the error of
+// every snapshot that fails, for example to log it.
+func WithErrorHandler(fn func(error)) Option {
+	return func(t *Tracker) {
+		t.onError = fn
+	}
+}
+
+// New creates a tracker that keeps the samples of artists in storage.  If
+// storage is nil, a [MemoryStorage] is used.
+func New(client *spotify.Client, artists []spotify.ID, storage Storage, opts ...Option) *Tracker {
+	if storage == nil {
+		storage = &MemoryStorage{}
+	}
+	t := &Tracker{
+		client:   client,
+		artists:  artists,
+		storage:  storage,
+		interval: DefaultInterval,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.interval <= 0 {
+		t.interval = DefaultInterval
+	}
+
+	return t
+}
+
+// Run takes a snapshot, and then another one every interval until ctx is
+// done, calling fn with the changes found by each of them.  It then returns
+// ctx.Err().  Failed snapshots don't stop the tracker; their errors are
+// passed to the function set with [WithErrorHandler], if any.
+func (t *Tracker) Run(ctx context.Context, fn func([]Delta)) error {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		deltas, err := t.Snapshot(ctx)
+		switch {
+		case err != nil && t.onError != nil && ctx.Err() == nil:
+			t.onError(err)
+		case err == nil && fn != nil:
+			fn(deltas)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Snapshot fetches the artists, stores a sample of each, and returns how each
+// artist changed since its previous sample.  Artists sampled for the first
+// time, and artists that weren't found, are left out of the result.
+func (t *Tracker) Snapshot(ctx context.Context) ([]Delta, error) {
+	samples, err := t.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+	last := make(map[spotify.ID]Sample)
+	for _, s := range samples {
+		if prev, ok := last[s.Artist]; !ok || s.Time.After(prev.Time) {
+			last[s.Artist] = s
+		}
+	}
+
+	now := t.now()
+	var deltas []Delta
+	for start := 0; start < len(t.artists); start += 50 {
+		end := start + 50
+		if end > len(t.artists) {
+			end = len(t.artists)
+		}
+		artists, err := t.client.GetArtists(ctx, t.artists[start:end]...)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range artists {
+			if a == nil {
+				continue
+			}
+			s := Sample{
+				Artist:     a.ID,
+				Name:       a.Name,
+				Time:       now,
+				Followers:  int(a.Followers.Count),
+				Popularity: int(a.Popularity),
+			}
+			samples = append(samples, s)
+			if prev, ok := last[a.ID]; ok {
+				deltas = append(deltas, delta(prev, s))
+			}
+		}
+	}
+
+	if err := t.storage.Save(samples); err != nil {
+		return nil, err
+	}
+	return deltas, nil
+}
+
+// Series returns the changes of artist between each of its consecutive stored
+// samples, oldest first.
+func (t *Tracker) Series(artist spotify.ID) ([]Delta, error) {
+	samples, err := t.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+	var own []Sample
+	for _, s := range samples {
+		if s.Artist == artist {
+			own = append(own, s)
+		}
+	}
+	sort.SliceStable(own, func(i, j int) bool { return own[i].Time.Before(own[j].Time) })
+
+	var deltas []Delta
+	for i := 1; i < len(own); i++ {
+		deltas = append(deltas, delta(own[i-1], own[i]))
+	}
+	return deltas, nil
+}
+
+func delta(from, to Sample) Delta {
+	return Delta{
+		Artist:     to.Artist,
+		Name:       to.Name,
+		From:       from.Time,
+		To:         to.Time,
+		Followers:  to.Followers - from.Followers,
+		Popularity: to.Popularity - from.Popularity,
+	}
+}
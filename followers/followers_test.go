@@ -0,0 +1,87 @@
+package followers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+func TestSnapshot(t *testing.T) {
+	followers := 100
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"artists": [{"id": "a", "name": "A", "popularity": 50, "followers": {"total": %d}}, null]}`, followers)
+	}))
+	defer server.Close()
+
+	client := spotify.New(http.DefaultClient, spotify.WithBaseURL(server.URL+"/"))
+	storage := FileStorage(filepath.Join(t.TempDir(), "followers.json"))
+	tracker := New(client, []spotify.ID{"a", "missing"}, storage)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return start }
+
+	deltas, err := tracker.Snapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deltas) != 0 {
+		t.Errorf("Expected no deltas for the first snapshot, got %v", deltas)
+	}
+
+	for day := 1; day <= 2; day++ {
+		followers += 10 * day
+		tracker.now = func() time.Time { return start.AddDate(0, 0, day) }
+		deltas, err = tracker.Snapshot(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(deltas) != 1 || deltas[0].Followers != 20 || deltas[0].Name != "A" || !deltas[0].From.Equal(start.AddDate(0, 0, 1)) {
+		t.Errorf("Unexpected deltas %+v", deltas)
+	}
+
+	series, err := New(client, nil, storage).Series("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 2 || series[0].Followers != 10 || series[1].Followers != 20 {
+		t.Errorf("Unexpected series %+v", series)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"error": {"status": 403, "message": "Forbidden"}}`)
+	}))
+	defer server.Close()
+	client := spotify.New(http.DefaultClient, spotify.WithBaseURL(server.URL+"/"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var errs []error
+	tracker := New(client, []spotify.ID{"a"}, nil,
+		WithInterval(0),
+		WithErrorHandler(func(err error) {
+			errs = append(errs, err)
+			cancel()
+		}))
+	if tracker.interval != DefaultInterval {
+		t.Errorf("Expected an interval of 0 to be replaced by the default, got %v", tracker.interval)
+	}
+
+	err := tracker.Run(ctx, func([]Delta) {
+		t.Error("Expected no deltas from a failed snapshot")
+	})
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected 1 error, got %v", errs)
+	}
+}
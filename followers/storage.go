@@ -0,0 +1,53 @@
+package followers
+
+import (
+	"fmt"
+
+	"github.com/zmb3/spotify/v2/internal/store"
+)
+
+// Storage persists the samples taken by a [Tracker].
+type Storage interface {
+	// Load returns the samples saved by the last call to Save, or nil if
+	// nothing has been saved yet.
+	Load() ([]Sample, error)
+	// Save replaces the stored samples.
+	Save(samples []Sample) error
+}
+
+// MemoryStorage is a [Storage] that keeps the samples in memory.  It is the
+// default storage of a [Tracker], and doesn't survive restarts.
+type MemoryStorage struct {
+	m store.Memory
+}
+
+// Load implements [Storage].
+func (m *MemoryStorage) Load() ([]Sample, error) {
+	samples, _ := m.m.Load().([]Sample)
+	return append([]Sample(nil), samples...), nil
+}
+
+// Save implements [Storage].
+func (m *MemoryStorage) Save(samples []Sample) error {
+	m.m.Save(append([]Sample(nil), samples...))
+	return nil
+}
+
+// FileStorage is a [Storage] that keeps the samples in the named file, encoded
+// as JSON.  A missing file is treated as having no samples.
+type FileStorage string
+
+// Load implements [Storage].
+func (f FileStorage) Load() ([]Sample, error) {
+	var samples []Sample
+	if _, err := store.LoadFile(string(f), &samples); err != nil {
+		return nil, fmt.Errorf("followers: %w", err)
+	}
+	return samples, nil
+}
+
+// Save implements [Storage].  The samples are written to a temporary file
+// first, so that a crash doesn't leave a partially written file behind.
+func (f FileStorage) Save(samples []Sample) error {
+	return store.SaveFile(string(f), samples)
+}
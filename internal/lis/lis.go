@@ -0,0 +1,35 @@
+// Package lis finds longest increasing subsequences, which tell which items of
+// a reordered list kept their relative order, and so don't need to be moved.
+package lis
+
+import "sort"
+
+// Longest reports, for each element of seq, whether it is part of a longest
+// strictly increasing subsequence of seq.  If there are several, the one that
+// ends with the smallest elements is chosen.
+func Longest(seq []int) []bool {
+	// tails[k] is the index in seq of the smallest possible last element of
+	// an increasing subsequence of length k+1
+	tails := make([]int, 0, len(seq))
+	prev := make([]int, len(seq))
+	for i, v := range seq {
+		k := sort.Search(len(tails), func(k int) bool { return seq[tails[k]] >= v })
+		prev[i] = -1
+		if k > 0 {
+			prev[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	in := make([]bool, len(seq))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+			in[i] = true
+		}
+	}
+	return in
+}
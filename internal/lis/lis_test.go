@@ -0,0 +1,25 @@
+package lis
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLongest(t *testing.T) {
+	tests := []struct {
+		seq  []int
+		want string
+	}{
+		{nil, "[]"},
+		{[]int{0, 1, 2}, "[true true true]"},
+		{[]int{2, 1, 0}, "[false false true]"},
+		{[]int{1, 2, 3, 0}, "[true true true false]"},
+		{[]int{3, 0, 2, 1}, "[false true false true]"},
+		{[]int{0, 4, 1, 3, 2}, "[true false true false true]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(Longest(tt.seq)); got != tt.want {
+			t.Errorf("Longest(%v) = %s, expected %s", tt.seq, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package store implements the parts shared by the storage of the
+// subpackages, which keep a single value either in memory or in a JSON file.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Memory holds a value in memory.  It is safe for concurrent use.
+type Memory struct {
+	mu sync.Mutex
+	v  interface{}
+}
+
+// Load returns the value passed to the last call to Save, or nil if Save
+// hasn't been called yet.
+func (m *Memory) Load() interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.v
+}
+
+// Save replaces the value.
+func (m *Memory) Save(v interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.v = v
+}
+
+// LoadFile decodes the JSON in the named file into v.  It reports whether the
+// file exists; a missing file isn't an error, and leaves v unchanged.
+func LoadFile(name string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("couldn't decode %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// SaveFile encodes v as JSON and writes it to the named file.  It is written to
+// a temporary file first, so that a crash doesn't leave a partially written
+// file behind.
+func SaveFile(name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+"-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
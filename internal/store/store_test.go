@@ -0,0 +1,54 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "data.json")
+
+	var got []string
+	if ok, err := LoadFile(name, &got); ok || err != nil || got != nil {
+		t.Errorf("Expected a missing file to load nothing, got %v, %v, %v", got, ok, err)
+	}
+
+	want := []string{"a", "b"}
+	if err := SaveFile(name, want); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := LoadFile(name, &got); !ok || err != nil {
+		t.Fatalf("Expected the file to load, got %v, %v", ok, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected the temporary file to be removed, got %d files", len(entries))
+	}
+
+	if err := os.WriteFile(name, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(name, &got); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+func TestMemory(t *testing.T) {
+	var m Memory
+	if v := m.Load(); v != nil {
+		t.Errorf("Expected nil, got %v", v)
+	}
+	m.Save(42)
+	if v := m.Load(); v != 42 {
+		t.Errorf("Expected 42, got %v", v)
+	}
+}
@@ -3,8 +3,8 @@ package spotify
 import (
 	"context"
 	"errors"
-	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
@@ -24,11 +24,15 @@ func (c *Client) libraryContains(ctx context.Context, typ string, ids ...ID) ([]
 	if l := len(ids); l == 0 || l > 50 {
 		return nil, errors.New("spotify: supports 1 to 50 IDs per call")
 	}
-	spotifyURL := fmt.Sprintf("%sme/%s/contains?ids=%s", c.baseURL, typ, strings.Join(toStringSlice(ids), ","))
+	ids, err := resolveIDs(ids, strings.TrimSuffix(typ, "s"))
+	if err != nil {
+		return nil, err
+	}
+	spotifyURL := c.apiURL(url.Values{"ids": {joinIDs(ids)}}, "me/", typ, "/contains")
 
 	var result []bool
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +74,11 @@ func (c *Client) modifyLibrary(ctx context.Context, typ string, add bool, ids ..
 	if l := len(ids); l == 0 || l > 50 {
 		return errors.New("spotify: this call supports 1 to 50 IDs per call")
 	}
-	spotifyURL := fmt.Sprintf("%sme/%s?ids=%s", c.baseURL, typ, strings.Join(toStringSlice(ids), ","))
+	ids, err := resolveIDs(ids, strings.TrimSuffix(typ, "s"))
+	if err != nil {
+		return err
+	}
+	spotifyURL := c.apiURL(url.Values{"ids": {joinIDs(ids)}}, "me/", typ)
 	method := "DELETE"
 	if add {
 		method = "PUT"
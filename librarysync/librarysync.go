@@ -0,0 +1,431 @@
+// Package librarysync maintains a local copy of a Spotify user's library.
+//
+// A [Syncer] keeps the user's saved tracks, saved albums and playlists in a
+// [Storage], and brings them up to date with [Syncer.Sync], which reports what
+// was added and removed since the previous sync.  Syncs are incremental: saved
+// tracks and albums are fetched newest first, and fetching stops as soon as a
+// known item is reached, unless the total count shows that something was
+// removed.  Playlists are only fetched again if their snapshot ID changed.
+//
+// Example:
+//
+//	s := librarysync.New(client, librarysync.FileStorage("library.json"))
+//	changes, err := s.Sync(ctx)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, t := range changes.TracksAdded {
+//		fmt.Println("saved", t.Name)
+//	}
+package librarysync
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// Library is the local copy of a user's library.
+type Library struct {
+	// Tracks are the user's saved tracks, most recently saved first.
+	Tracks []spotify.SavedTrack `json:"tracks"`
+	// Albums are the user's saved albums, most recently saved first.
+	Albums []spotify.SavedAlbum `json:"albums"`
+	// Playlists are the playlists owned or followed by the user.
+	Playlists []Playlist `json:"playlists"`
+}
+
+// Playlist is a playlist along with its items.
+type Playlist struct {
+	Playlist spotify.SimplePlaylist `json:"playlist"`
+	Items    []spotify.PlaylistItem `json:"items"`
+}
+
+// Changes describes the differences found by a sync.
+type Changes struct {
+	TracksAdded      []spotify.SavedTrack
+	TracksRemoved    []spotify.SavedTrack
+	AlbumsAdded      []spotify.SavedAlbum
+	AlbumsRemoved    []spotify.SavedAlbum
+	PlaylistsAdded   []spotify.SimplePlaylist
+	PlaylistsRemoved []spotify.SimplePlaylist
+	// PlaylistsChanged lists the changes to the items of playlists that
+	// were already part of the library.
+	PlaylistsChanged []PlaylistChanges
+}
+
+// PlaylistChanges describes the items added to and removed from a playlist.
+type PlaylistChanges struct {
+	Playlist spotify.SimplePlaylist
+	Added    []spotify.PlaylistItem
+	Removed  []spotify.PlaylistItem
+}
+
+// Empty reports whether the sync found no differences.
+func (c *Changes) Empty() bool {
+	return len(c.TracksAdded) == 0 && len(c.TracksRemoved) == 0 &&
+		len(c.AlbumsAdded) == 0 && len(c.AlbumsRemoved) == 0 &&
+		len(c.PlaylistsAdded) == 0 && len(c.PlaylistsRemoved) == 0 &&
+		len(c.PlaylistsChanged) == 0
+}
+
+// Syncer keeps a local copy of the library of the user that its client is
+// authenticated as.  A Syncer must not be used concurrently.  You should
+// always use [New] to make them.
+type Syncer struct {
+	client  *spotify.Client
+	storage Storage
+
+	tracks    bool
+	albums    bool
+	playlists bool
+}
+
+// Option configures a [Syncer] made by [New].
+type Option func(s *Syncer)
+
+// WithoutTracks stops the syncer from keeping the user's saved tracks.
+func WithoutTracks() Option {
+	return func(s *Syncer) {
+		s.tracks = false
+	}
+}
+
+// WithoutAlbums stops the syncer from keeping the user's saved albums.
+func WithoutAlbums() Option {
+	return func(s *Syncer) {
+		s.albums = false
+	}
+}
+
+// WithoutPlaylists stops the syncer from keeping the user's playlists.
+func WithoutPlaylists() Option {
+	return func(s *Syncer) {
+		s.playlists = false
+	}
+}
+
+// New creates a syncer that keeps the library in storage.  If storage is nil,
+// a [MemoryStorage] is used.
+//
+// Depending on what is being synced, the client needs the
+// [spotifyauth.ScopeUserLibraryRead] and [spotifyauth.ScopePlaylistReadPrivate]
+// scopes.
+func New(client *spotify.Client, storage Storage, opts ...Option) *Syncer {
+	if storage == nil {
+		storage = &MemoryStorage{}
+	}
+	s := &Syncer{
+		client:    client,
+		storage:   storage,
+		tracks:    true,
+		albums:    true,
+		playlists: true,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Sync brings the stored library up to date, and returns what changed.  On the
+// first sync, everything in the library is reported as added.  The stored
+// library is only updated if the whole sync succeeds.
+func (s *Syncer) Sync(ctx context.Context) (*Changes, error) {
+	old, err := s.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+	if old == nil {
+		old = &Library{}
+	}
+
+	var (
+		lib     Library
+		changes Changes
+	)
+	if s.tracks {
+		lib.Tracks, changes.TracksAdded, changes.TracksRemoved, err = s.syncTracks(ctx, old.Tracks)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if s.albums {
+		lib.Albums, changes.AlbumsAdded, changes.AlbumsRemoved, err = s.syncAlbums(ctx, old.Albums)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if s.playlists {
+		lib.Playlists, err = s.syncPlaylists(ctx, old.Playlists, &changes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.storage.Save(&lib); err != nil {
+		return nil, err
+	}
+	return &changes, nil
+}
+
+func (s *Syncer) syncTracks(ctx context.Context, old []spotify.SavedTrack) (tracks, added, removed []spotify.SavedTrack, err error) {
+	oldIDs := make([]spotify.ID, len(old))
+	for i, t := range old {
+		oldIDs[i] = t.ID
+	}
+	var (
+		page    *spotify.SavedTrackPage
+		fetched []spotify.SavedTrack
+	)
+	all, a, r, err := syncSaved(ctx, oldIDs, func(ctx context.Context, first bool) ([]spotify.ID, int, error) {
+		if first {
+			var err error
+			if page, err = s.client.CurrentUsersTracks(ctx, spotify.Limit(50)); err != nil {
+				return nil, 0, err
+			}
+			fetched = fetched[:0]
+		} else if err := s.client.NextPage(ctx, page); err != nil {
+			return nil, 0, err
+		}
+		ids := make([]spotify.ID, len(page.Tracks))
+		for i, t := range page.Tracks {
+			ids[i] = t.ID
+		}
+		fetched = append(fetched, page.Tracks...)
+		return ids, int(page.Total), nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	items := append(fetched, old...)
+	pick := func(indices []int) []spotify.SavedTrack {
+		if len(indices) == 0 {
+			return nil
+		}
+		picked := make([]spotify.SavedTrack, len(indices))
+		for i, j := range indices {
+			picked[i] = items[j]
+		}
+		return picked
+	}
+	return pick(all), pick(a), pick(r), nil
+}
+
+func (s *Syncer) syncAlbums(ctx context.Context, old []spotify.SavedAlbum) (albums, added, removed []spotify.SavedAlbum, err error) {
+	oldIDs := make([]spotify.ID, len(old))
+	for i, a := range old {
+		oldIDs[i] = a.ID
+	}
+	var (
+		page    *spotify.SavedAlbumPage
+		fetched []spotify.SavedAlbum
+	)
+	all, a, r, err := syncSaved(ctx, oldIDs, func(ctx context.Context, first bool) ([]spotify.ID, int, error) {
+		if first {
+			var err error
+			if page, err = s.client.CurrentUsersAlbums(ctx, spotify.Limit(50)); err != nil {
+				return nil, 0, err
+			}
+			fetched = fetched[:0]
+		} else if err := s.client.NextPage(ctx, page); err != nil {
+			return nil, 0, err
+		}
+		ids := make([]spotify.ID, len(page.Albums))
+		for i, a := range page.Albums {
+			ids[i] = a.ID
+		}
+		fetched = append(fetched, page.Albums...)
+		return ids, int(page.Total), nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	items := append(fetched, old...)
+	pick := func(indices []int) []spotify.SavedAlbum {
+		if len(indices) == 0 {
+			return nil
+		}
+		picked := make([]spotify.SavedAlbum, len(indices))
+		for i, j := range indices {
+			picked[i] = items[j]
+		}
+		return picked
+	}
+	return pick(all), pick(a), pick(r), nil
+}
+
+// pageFunc fetches a page of a collection of saved items, newest first: the
+// first page if first is true, and otherwise the page after the one fetched
+// by the previous call.  It returns the IDs of the items on the page and the
+// total number of items in the collection, or [spotify.ErrNoMorePages] once
+// the last page has been fetched.
+type pageFunc func(ctx context.Context, first bool) (ids []spotify.ID, total int, err error)
+
+// syncSaved compares a collection of saved items, such as the user's saved
+// tracks, against old, the IDs of the stored items, newest first.  The newest
+// items are fetched until a known one is reached, and the whole collection is
+// only fetched again if its total shows that something was removed.
+//
+// Items are referred to by their index in the items fetched by the last pass
+// over the collection, followed by the stored items.  all lists the items of
+// the collection, newest first, and added and removed the differences.
+func syncSaved(ctx context.Context, old []spotify.ID, fetch pageFunc) (all, added, removed []int, err error) {
+	known := make(map[spotify.ID]bool, len(old))
+	for _, id := range old {
+		known[id] = true
+	}
+
+	// fetch the newest items until a known one is reached
+	ids, total, err := fetchIDs(ctx, fetch, func(id spotify.ID) bool { return known[id] })
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fresh := 0
+	for fresh < len(ids) && !known[ids[fresh]] {
+		fresh++
+	}
+	if total == len(old)+fresh {
+		// nothing was removed
+		all = make([]int, 0, fresh+len(old))
+		for i := 0; i < fresh; i++ {
+			all = append(all, i)
+		}
+		for i := range old {
+			all = append(all, len(ids)+i)
+		}
+		return all, all[:fresh:fresh], nil, nil
+	}
+
+	// something was removed, so the whole collection has to be compared
+	ids, _, err = fetchIDs(ctx, fetch, func(spotify.ID) bool { return false })
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	current := make(map[spotify.ID]bool, len(ids))
+	all = make([]int, len(ids))
+	for i, id := range ids {
+		all[i] = i
+		current[id] = true
+		if !known[id] {
+			added = append(added, i)
+		}
+	}
+	for i, id := range old {
+		if !current[id] {
+			removed = append(removed, len(ids)+i)
+		}
+	}
+	return all, added, removed, nil
+}
+
+// fetchIDs fetches the pages of a collection until the last one, or until one
+// that contains an item for which stop returns true.  It returns the IDs of
+// the items on the pages fetched and the total number of items.
+func fetchIDs(ctx context.Context, fetch pageFunc, stop func(spotify.ID) bool) (ids []spotify.ID, total int, err error) {
+	for first := true; ; first = false {
+		page, n, err := fetch(ctx, first)
+		if errors.Is(err, spotify.ErrNoMorePages) {
+			return ids, total, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		ids, total = append(ids, page...), n
+		for _, id := range page {
+			if stop(id) {
+				return ids, total, nil
+			}
+		}
+	}
+}
+
+func (s *Syncer) syncPlaylists(ctx context.Context, old []Playlist, changes *Changes) ([]Playlist, error) {
+	previous := make(map[spotify.ID]Playlist, len(old))
+	for _, p := range old {
+		previous[p.Playlist.ID] = p
+	}
+
+	page, err := s.client.CurrentUsersPlaylists(ctx, spotify.Limit(50))
+	if err != nil {
+		return nil, err
+	}
+	var playlists []Playlist
+	current := make(map[spotify.ID]bool)
+	for {
+		for _, p := range page.Playlists {
+			current[p.ID] = true
+			prev, ok := previous[p.ID]
+			// the snapshot ID changes whenever a playlist is modified, so
+			// there's no need to fetch the items of unchanged playlists
+			if ok && prev.Playlist.SnapshotID == p.SnapshotID {
+				playlists = append(playlists, prev)
+				continue
+			}
+			items, err := s.client.GetAllPlaylistItems(ctx, p.ID)
+			if err != nil {
+				return nil, err
+			}
+			playlists = append(playlists, Playlist{Playlist: p, Items: items})
+			if !ok {
+				changes.PlaylistsAdded = append(changes.PlaylistsAdded, p)
+				continue
+			}
+			added, removed := diffItems(prev.Items, items)
+			if len(added) > 0 || len(removed) > 0 {
+				changes.PlaylistsChanged = append(changes.PlaylistsChanged, PlaylistChanges{
+					Playlist: p,
+					Added:    added,
+					Removed:  removed,
+				})
+			}
+		}
+
+		err = s.client.NextPage(ctx, page)
+		if errors.Is(err, spotify.ErrNoMorePages) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, p := range old {
+		if !current[p.Playlist.ID] {
+			changes.PlaylistsRemoved = append(changes.PlaylistsRemoved, p.Playlist)
+		}
+	}
+	return playlists, nil
+}
+
+// diffItems compares two versions of a playlist's items.  A playlist may
+// contain the same item more than once, so items are counted rather than just
+// checked for presence.
+func diffItems(old, new []spotify.PlaylistItem) (added, removed []spotify.PlaylistItem) {
+	counts := make(map[spotify.URI]int, len(old))
+	for _, item := range old {
+		counts[item.URI()]++
+	}
+	for _, item := range new {
+		uri := item.URI()
+		if counts[uri] > 0 {
+			counts[uri]--
+			continue
+		}
+		added = append(added, item)
+	}
+	// whatever is left over in counts was removed
+	for _, item := range old {
+		uri := item.URI()
+		if counts[uri] > 0 {
+			counts[uri]--
+			removed = append(removed, item)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,211 @@
+package librarysync
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// library is a fake Spotify library served over HTTP.  Saved tracks are
+// listed most recently saved first, and are paged like the real API.
+type library struct {
+	tracks   []string
+	snapshot string
+	items    []string
+
+	trackRequests int
+}
+
+func (l *library) serve(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/me/tracks":
+		l.trackRequests++
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := offset + limit
+		if end > len(l.tracks) {
+			end = len(l.tracks)
+		}
+		fmt.Fprint(w, `{"items": [`)
+		for i, id := range l.tracks[offset:end] {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"added_at": "2021-01-01T00:00:00Z", "track": {"id": %q, "type": "track"}}`, id)
+		}
+		next := "null"
+		if end < len(l.tracks) {
+			next = fmt.Sprintf(`"http://%s/me/tracks?offset=%d&limit=%d"`, r.Host, end, limit)
+		}
+		fmt.Fprintf(w, `], "total": %d, "next": %s}`, len(l.tracks), next)
+	case "/me/playlists":
+		fmt.Fprintf(w, `{"items": [{"id": "p1", "name": "Mix", "snapshot_id": %q}], "next": null}`, l.snapshot)
+	case "/playlists/p1/tracks":
+		fmt.Fprint(w, `{"items": [`)
+		for i, id := range l.items {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"track": {"id": %q, "uri": "spotify:track:%s", "type": "track"}}`, id, id)
+		}
+		fmt.Fprint(w, `], "next": null}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func ids(tracks []spotify.SavedTrack) []spotify.ID {
+	var ids []spotify.ID
+	for _, t := range tracks {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+func TestSync(t *testing.T) {
+	lib := &library{snapshot: "s1", items: []string{"a"}}
+	for i := 0; i < 60; i++ {
+		lib.tracks = append(lib.tracks, fmt.Sprintf("t%d", i))
+	}
+	server := httptest.NewServer(http.HandlerFunc(lib.serve))
+	defer server.Close()
+
+	client := spotify.New(http.DefaultClient, spotify.WithBaseURL(server.URL+"/"))
+	storage := FileStorage(filepath.Join(t.TempDir(), "library.json"))
+	s := New(client, storage, WithoutAlbums())
+
+	changes, err := s.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes.TracksAdded) != 60 || len(changes.PlaylistsAdded) != 1 {
+		t.Fatalf("Expected the whole library to be added, got %d tracks and %d playlists",
+			len(changes.TracksAdded), len(changes.PlaylistsAdded))
+	}
+
+	// a new track is saved, so only the first page is needed
+	lib.tracks = append([]string{"new"}, lib.tracks...)
+	lib.trackRequests = 0
+	changes, err = s.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(changes.TracksAdded); len(got) != 1 || got[0] != "new" {
+		t.Errorf("Expected one added track, got %v", got)
+	}
+	if lib.trackRequests != 1 {
+		t.Errorf("Expected an incremental sync, got %d requests", lib.trackRequests)
+	}
+	if !(len(changes.TracksRemoved) == 0 && len(changes.PlaylistsChanged) == 0) {
+		t.Errorf("Unexpected changes: %+v", changes)
+	}
+
+	// a track is removed, and an item is added to the playlist
+	lib.tracks = append(lib.tracks[:10], lib.tracks[11:]...)
+	lib.snapshot = "s2"
+	lib.items = append(lib.items, "b")
+	changes, err = s.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(changes.TracksRemoved); len(got) != 1 || got[0] != "t9" {
+		t.Errorf("Expected t9 to be removed, got %v", got)
+	}
+	if len(changes.TracksAdded) != 0 {
+		t.Errorf("Expected no added tracks, got %v", ids(changes.TracksAdded))
+	}
+	if len(changes.PlaylistsChanged) != 1 || len(changes.PlaylistsChanged[0].Added) != 1 {
+		t.Errorf("Expected one item to be added to the playlist, got %+v", changes.PlaylistsChanged)
+	}
+
+	// nothing changed
+	changes, err = s.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changes.Empty() {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+	stored, err := storage.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Tracks) != 60 || len(stored.Playlists[0].Items) != 2 {
+		t.Errorf("Unexpected stored library: %d tracks", len(stored.Tracks))
+	}
+}
+
+// pages returns a pageFunc that serves ids two at a time.
+func pages(ids ...spotify.ID) (pageFunc, *int) {
+	var offset, requests int
+	return func(ctx context.Context, first bool) ([]spotify.ID, int, error) {
+		if first {
+			offset = 0
+		} else if offset+2 >= len(ids) {
+			return nil, 0, spotify.ErrNoMorePages
+		} else {
+			offset += 2
+		}
+		requests++
+		end := offset + 2
+		if end > len(ids) {
+			end = len(ids)
+		}
+		return ids[offset:end], len(ids), nil
+	}, &requests
+}
+
+func TestSyncSaved(t *testing.T) {
+	tests := []struct {
+		name                string
+		old, current        []spotify.ID
+		all, added, removed []int
+		requests            int
+	}{
+		{
+			name:     "first sync",
+			current:  []spotify.ID{"a", "b", "c"},
+			all:      []int{0, 1, 2},
+			added:    []int{0, 1, 2},
+			requests: 2,
+		},
+		{
+			name:     "added",
+			old:      []spotify.ID{"b", "c", "d"},
+			current:  []spotify.ID{"a", "b", "c", "d"},
+			all:      []int{0, 2, 3, 4},
+			added:    []int{0},
+			requests: 1,
+		},
+		{
+			name:     "removed",
+			old:      []spotify.ID{"a", "b", "c"},
+			current:  []spotify.ID{"x", "a", "c"},
+			all:      []int{0, 1, 2},
+			added:    []int{0},
+			removed:  []int{4},
+			requests: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetch, requests := pages(tt.current...)
+			all, added, removed, err := syncSaved(context.Background(), tt.old, fetch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(all, added, removed) != fmt.Sprint(tt.all, tt.added, tt.removed) {
+				t.Errorf("Expected %v %v %v, got %v %v %v", tt.all, tt.added, tt.removed, all, added, removed)
+			}
+			if *requests != tt.requests {
+				t.Errorf("Expected %d requests, got %d", tt.requests, *requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package librarysync
+
+import (
+	"fmt"
+
+	"github.com/zmb3/spotify/v2/internal/store"
+)
+
+// Storage persists the local copy of a library between syncs.
+type Storage interface {
+	// Load returns the library saved by the last call to Save, or nil if
+	// nothing has been saved yet.
+	Load() (*Library, error)
+	// Save replaces the stored library.
+	Save(lib *Library) error
+}
+
+// MemoryStorage is a [Storage] that keeps the library in memory.  It is the
+// default storage of a [Syncer], and doesn't survive restarts.
+type MemoryStorage struct {
+	m store.Memory
+}
+
+// Load implements [Storage].
+func (m *MemoryStorage) Load() (*Library, error) {
+	lib, _ := m.m.Load().(*Library)
+	return lib, nil
+}
+
+// Save implements [Storage].
+func (m *MemoryStorage) Save(lib *Library) error {
+	m.m.Save(lib)
+	return nil
+}
+
+// FileStorage is a [Storage] that keeps the library in the named file, encoded
+// as JSON.  A missing file is treated as an empty library.
+type FileStorage string
+
+// Load implements [Storage].
+func (f FileStorage) Load() (*Library, error) {
+	var lib Library
+	ok, err := store.LoadFile(string(f), &lib)
+	if err != nil {
+		return nil, fmt.Errorf("librarysync: %w", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return &lib, nil
+}
+
+// Save implements [Storage].  The library is written to a temporary file
+// first, so that a crash doesn't leave a partially written file behind.
+func (f FileStorage) Save(lib *Library) error {
+	return store.SaveFile(string(f), lib)
+}
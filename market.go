@@ -0,0 +1,134 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// WithUserMarket configures the client to add the current user's country as
+// the market parameter of every request that accepts one, unless the request
+// already specifies a market.  This enables [track relinking] without every
+// call passing the [Market] option.
+//
+// The user's profile is fetched with [Client.CurrentUser] before the first
+// such request.  If the profile doesn't include a country, because the token
+// wasn't granted [ScopeUserReadPrivate], [MarketFromToken] is used instead.
+// If the token doesn't belong to a user, as with the client credentials flow,
+// no market is added.  If the profile can't be fetched for another reason,
+// the request fails, and the profile is fetched again by the next one.
+//
+// [track relinking]: https://developer.spotify.com/documentation/web-api/concepts/track-relinking
+func WithUserMarket() ClientOption {
+	return func(client *Client) {
+		client.userMarket = &userMarket{}
+	}
+}
+
+// userMarket holds the market found by WithUserMarket.
+type userMarket struct {
+	mu      sync.Mutex
+	fetched bool
+	market  string
+}
+
+// marketEndpoints lists the endpoints, as named by [Metrics], that accept a
+// market parameter.
+var marketEndpoints = map[string]bool{
+	"GET albums":                      true,
+	"GET albums/*":                    true,
+	"GET albums/*/tracks":             true,
+	"GET artists/*/albums":            true,
+	"GET artists/*/top-tracks":        true,
+	"GET audiobooks":                  true,
+	"GET audiobooks/*":                true,
+	"GET audiobooks/*/chapters":       true,
+	"GET chapters":                    true,
+	"GET chapters/*":                  true,
+	"GET episodes":                    true,
+	"GET episodes/*":                  true,
+	"GET me/albums":                   true,
+	"GET me/episodes":                 true,
+	"GET me/player":                   true,
+	"GET me/player/currently-playing": true,
+	"GET me/tracks":                   true,
+	"GET playlists/*":                 true,
+	"GET playlists/*/tracks":          true,
+	"GET recommendations":             true,
+	"GET search":                      true,
+	"GET shows":                       true,
+	"GET shows/*":                     true,
+	"GET shows/*/episodes":            true,
+	"GET tracks":                      true,
+	"GET tracks/*":                    true,
+}
+
+// addUserMarket adds the user's market to rawURL, if the client was
+// configured with WithUserMarket and the endpoint accepts a market.
+func (c *Client) addUserMarket(ctx context.Context, rawURL string) (string, error) {
+	if c.userMarket == nil {
+		return rawURL, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL, nil
+	}
+	query := u.Query()
+	if query.Get("market") != "" || !marketEndpoints[c.endpointName(&http.Request{Method: "GET", URL: u})] {
+		return rawURL, nil
+	}
+
+	market, err := c.userMarket.get(ctx, c)
+	if err != nil || market == "" {
+		return rawURL, err
+	}
+	query.Set("market", market)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// get returns the user's market, fetching the user's profile the first time
+// it is called.  Only the responses that mean that the token doesn't belong to
+// a user are remembered; if the profile can't be fetched for another reason,
+// such as a network error, a server error or rate limiting, the error is
+// returned and the profile is fetched again by the next request.
+//
+// m.mu is held while the profile is fetched, so that concurrent requests wait
+// for a single lookup instead of each fetching the profile.
+func (m *userMarket) get(ctx context.Context, c *Client) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fetched {
+		return m.market, nil
+	}
+
+	user, err := c.CurrentUser(ctx)
+	switch {
+	case isNoUser(err):
+		// the token doesn't belong to a user
+	case err != nil:
+		return "", err
+	case user.Country != "":
+		m.market = user.Country
+	default:
+		m.market = MarketFromToken
+	}
+	m.fetched = true
+	return m.market, nil
+}
+
+// isNoUser reports whether err is Spotify's response to a request for the
+// current user made with a token that doesn't belong to a user, such as a
+// client credentials token.  Other authorization errors, such as an expired
+// or revoked token, aren't included, as they can go away once the token is
+// refreshed.
+func isNoUser(err error) bool {
+	var e Error
+	if !errors.As(err, &e) || (e.Status != http.StatusUnauthorized && e.Status != http.StatusForbidden) {
+		return false
+	}
+	return strings.Contains(strings.ToLower(e.Message), "requires user authentication")
+}
@@ -0,0 +1,128 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestWithUserMarket(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.String())
+		mu.Unlock()
+		if r.URL.Path == "/me" {
+			fmt.Fprint(w, `{"id": "user", "country": "SE"}`)
+			return
+		}
+		fmt.Fprint(w, `{"id": "track"}`)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithUserMarket())
+
+	ctx := context.Background()
+	if _, err := client.GetTrack(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetTrack(ctx, "b", Market("US")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetUsersPublicProfile(ctx, "c"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"/me", "/tracks/a?market=SE", "/tracks/b?market=US", "/users/c"}
+	if fmt.Sprint(requests) != fmt.Sprint(want) {
+		t.Errorf("Expected requests %v, got %v", want, requests)
+	}
+}
+
+func TestWithUserMarketNoUser(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.String())
+		if r.URL.Path == "/me" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": {"status": 401, "message": "This request requires user authentication."}}`)
+			return
+		}
+		fmt.Fprint(w, `{"id": "track"}`)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithUserMarket())
+
+	for _, id := range []ID{"a", "b"} {
+		if _, err := client.GetTrack(context.Background(), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"/me", "/tracks/a", "/tracks/b"}
+	if fmt.Sprint(requests) != fmt.Sprint(want) {
+		t.Errorf("Expected requests %v, got %v", want, requests)
+	}
+}
+
+func TestWithUserMarketTransientError(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.String())
+		if r.URL.Path == "/me" {
+			if len(requests) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprint(w, `{"error": {"status": 503, "message": "Service unavailable"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"id": "user", "country": "SE"}`)
+			return
+		}
+		fmt.Fprint(w, `{"id": "track"}`)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithUserMarket())
+
+	if _, err := client.GetTrack(context.Background(), "a"); err == nil {
+		t.Error("Expected the failed profile lookup to be reported")
+	}
+	if _, err := client.GetTrack(context.Background(), "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"/me", "/me", "/tracks/b?market=SE"}
+	if fmt.Sprint(requests) != fmt.Sprint(want) {
+		t.Errorf("Expected requests %v, got %v", want, requests)
+	}
+}
+
+func TestWithUserMarketUnauthorized(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.String())
+		if r.URL.Path == "/me" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": {"status": 401, "message": "The access token expired"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"id": "track"}`)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithUserMarket())
+
+	for _, id := range []ID{"a", "b"} {
+		if _, err := client.GetTrack(context.Background(), id); err == nil {
+			t.Error("Expected the failed profile lookup to be reported")
+		}
+	}
+
+	want := []string{"/me", "/me"}
+	if fmt.Sprint(requests) != fmt.Sprint(want) {
+		t.Errorf("Expected requests %v, got %v", want, requests)
+	}
+}
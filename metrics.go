@@ -0,0 +1,87 @@
+package spotify
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Metrics receives measurements of the requests made by a client, so that
+// they can be exported to a monitoring system such as Prometheus.
+// Implementations must be safe for concurrent use, and should return quickly.
+//
+// Endpoints are identified by the request method and the path relative to the
+// client's base URL, with IDs replaced by "*", for example "GET tracks/*" or
+// "PUT playlists/*/tracks".  This keeps the number of distinct endpoints
+// small enough to be used as a metric label.
+type Metrics interface {
+	// ObserveRequest is called after each request, including requests that
+	// are retried.  The status is 0 if no response was received.
+	ObserveRequest(endpoint string, status int, duration time.Duration)
+	// CountRetry is called when a request is retried because it failed
+	// with the given status, for example after being rate limited.  The
+	// status is 0 if the request failed with a network error.
+	CountRetry(endpoint string, status int)
+}
+
+// WithMetrics configures the client to report the requests it makes to m.
+// Responses served from the cache configured with [WithCache], and requests
+// skipped because of [WithDryRun], are not reported.
+func WithMetrics(m Metrics) ClientOption {
+	return func(client *Client) {
+		client.metrics = m
+	}
+}
+
+// idCollections lists the top-level paths whose second segment is an ID.
+var idCollections = map[string]bool{
+	"albums":         true,
+	"artists":        true,
+	"audio-analysis": true,
+	"audio-features": true,
+	"audiobooks":     true,
+	"chapters":       true,
+	"episodes":       true,
+	"playlists":      true,
+	"shows":          true,
+	"tracks":         true,
+	"users":          true,
+}
+
+// endpointName identifies the endpoint that req is sent to, as described by
+// [Metrics].
+func (c *Client) endpointName(req *http.Request) string {
+	path := req.URL.Path
+	if base, err := url.Parse(c.baseURL); err == nil {
+		path = strings.TrimPrefix(path, base.Path)
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := range segments {
+		isID := (i == 1 && idCollections[segments[0]]) ||
+			(i == 2 && segments[0] == "browse" && segments[1] == "categories")
+		if isID {
+			segments[i] = "*"
+		}
+	}
+	return req.Method + " " + strings.Join(segments, "/")
+}
+
+// observeRequest reports a request that was sent at start, and received resp.
+func (c *Client) observeRequest(req *http.Request, resp *http.Response, start time.Time) {
+	if c.metrics == nil {
+		return
+	}
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	c.metrics.ObserveRequest(c.endpointName(req), status, time.Since(start))
+}
+
+// countRetry reports that req is retried after failing with status.
+func (c *Client) countRetry(req *http.Request, status int) {
+	if c.metrics != nil {
+		c.metrics.CountRetry(c.endpointName(req), status)
+	}
+}
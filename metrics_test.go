@@ -0,0 +1,79 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	mu       sync.Mutex
+	requests []string
+	retries  []string
+}
+
+func (m *recordingMetrics) ObserveRequest(endpoint string, status int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, fmt.Sprintf("%s %d", endpoint, status))
+}
+
+func (m *recordingMetrics) CountRetry(endpoint string, status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries = append(m.retries, fmt.Sprintf("%s %d", endpoint, status))
+}
+
+func TestMetrics(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error": {"status": 429, "message": "slow down"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"id": "track"}`)
+	}))
+	defer server.Close()
+
+	m := &recordingMetrics{}
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/v1/"), WithRetry(true), WithMetrics(m))
+	if _, err := client.GetTrack(context.Background(), "4uLU6hMCjMI75M1A2tKUQC"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"GET tracks/* 429", "GET tracks/* 200"}
+	if fmt.Sprint(m.requests) != fmt.Sprint(want) {
+		t.Errorf("Expected requests %v, got %v", want, m.requests)
+	}
+	if len(m.retries) != 1 || m.retries[0] != "GET tracks/* 429" {
+		t.Errorf("Expected one retry, got %v", m.retries)
+	}
+}
+
+func TestEndpointName(t *testing.T) {
+	client := New(http.DefaultClient)
+	tests := []struct {
+		method, url, want string
+	}{
+		{"GET", "https://api.spotify.com/v1/me/tracks/contains?ids=a", "GET me/tracks/contains"},
+		{"PUT", "https://api.spotify.com/v1/playlists/abc/tracks", "PUT playlists/*/tracks"},
+		{"GET", "https://api.spotify.com/v1/browse/categories/party/playlists", "GET browse/categories/*/playlists"},
+		{"GET", "https://api.spotify.com/v1/users/wizzler", "GET users/*"},
+	}
+	for _, test := range tests {
+		req, err := http.NewRequest(test.method, test.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := client.endpointName(req); got != test.want {
+			t.Errorf("%s: expected %q, got %q", test.url, test.want, got)
+		}
+	}
+}
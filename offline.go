@@ -0,0 +1,105 @@
+package spotify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultOfflineTTL is the time that the last responses are kept if
+// [WithOfflineMode] is given a ttl of zero.
+const DefaultOfflineTTL = 24 * time.Hour
+
+// WithOfflineMode configures the client to keep the last response to every
+// GET request in backend for up to ttl, and to return it instead of an error
+// when the API can't be reached, fails with a server error, or is rate
+// limiting the client for longer than the request's deadline allows to wait.
+// Dashboards and other long-running displays can then degrade gracefully
+// while Spotify is unavailable.
+//
+// Unlike the responses cached by [WithCache], the last responses are kept for
+// every endpoint, including user data and playback state.  They aren't keyed
+// by user, so backend must only be used by clients acting for the same user,
+// and shouldn't be a backend shared with WithCache.  As it holds personal
+// data, prefer a backend that doesn't outlive the process over one such as
+// [NewFileCache].  Use [WithResponseInfo] to find out whether a result is
+// stale.
+func WithOfflineMode(backend CacheBackend, ttl time.Duration) ClientOption {
+	return func(client *Client) {
+		if ttl == 0 {
+			ttl = DefaultOfflineTTL
+		}
+		client.offline = backend
+		client.offlineTTL = ttl
+	}
+}
+
+// ResponseInfo describes the responses to the requests made with a context
+// returned by [WithResponseInfo].
+type ResponseInfo struct {
+	mu    sync.Mutex
+	stale bool
+}
+
+// Stale reports whether any of the responses was served from the cache kept
+// by [WithOfflineMode], because the API couldn't be reached.
+func (i *ResponseInfo) Stale() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.stale
+}
+
+type responseInfoKey struct{}
+
+// WithResponseInfo returns a context that records information about the
+// responses to the requests made with it in info:
+//
+//	var info spotify.ResponseInfo
+//	state, err := client.PlayerState(spotify.WithResponseInfo(ctx, &info))
+//	if err == nil && info.Stale() {
+//		// show the state as outdated
+//	}
+func WithResponseInfo(ctx context.Context, info *ResponseInfo) context.Context {
+	return context.WithValue(ctx, responseInfoKey{}, info)
+}
+
+// staleKey returns the key under which the last response for url is kept in
+// offline mode, and whether it should be kept.
+func (c *Client) staleKey(ctx context.Context, url string) (string, bool) {
+	if c.offline == nil || hasHeaders(ctx) {
+		return "", false
+	}
+	return "stale " + c.acceptLanguage + " " + url, true
+}
+
+// serveStale decodes the last response kept under key into result, and
+// reports whether there was one.
+func (c *Client) serveStale(ctx context.Context, key string, result interface{}) bool {
+	data, ok, err := c.offline.Get(key)
+	if err != nil || !ok {
+		return false
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return false
+	}
+	if info, ok := ctx.Value(responseInfoKey{}).(*ResponseInfo); ok {
+		info.mu.Lock()
+		info.stale = true
+		info.mu.Unlock()
+	}
+	return true
+}
+
+// isOfflineStatus reports whether status means that the API is unavailable,
+// rather than that the request was wrong.
+func isOfflineStatus(status int) bool {
+	return status == http.StatusTooManyRequests || isTransientStatus(status)
+}
+
+// canWait reports whether ctx allows waiting for d before retrying.
+func canWait(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > d
+}
@@ -0,0 +1,75 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestOfflineMode(t *testing.T) {
+	down := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"is_playing": true, "progress_ms": 1000}`)
+	}))
+	defer server.Close()
+
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithOfflineMode(cache, 0))
+
+	var info ResponseInfo
+	ctx := WithResponseInfo(context.Background(), &info)
+	if _, err := client.PlayerState(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if info.Stale() {
+		t.Error("Expected a fresh response")
+	}
+
+	down = true
+	state, err := client.PlayerState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.Playing || state.Progress != 1000 {
+		t.Errorf("Expected the last response, got %+v", state.CurrentlyPlaying)
+	}
+	if !info.Stale() {
+		t.Error("Expected the response to be stale")
+	}
+
+	if _, err := client.PlayerState(context.Background(), Market("DE")); err == nil {
+		t.Error("Expected an error for a request without a kept response")
+	}
+}
+
+func TestOfflineModeKeepsResponsesApart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"is_playing": true}`)
+	}))
+	defer server.Close()
+
+	sharedDir, offlineDir := t.TempDir(), t.TempDir()
+	shared, _ := NewFileCache(sharedDir)
+	offline, _ := NewFileCache(offlineDir)
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithCache(shared, 0), WithOfflineMode(offline, 0))
+	if _, err := client.PlayerState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if files, _ := os.ReadDir(sharedDir); len(files) != 0 {
+		t.Errorf("Expected the shared cache to stay empty, got %d files", len(files))
+	}
+	if files, _ := os.ReadDir(offlineDir); len(files) != 1 {
+		t.Errorf("Expected the response to be kept in the offline backend, got %d files", len(files))
+	}
+}
@@ -2,9 +2,13 @@ package spotify
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"reflect"
+	"sort"
+	"strings"
+	"sync"
 )
 
 // ErrNoMorePages is the error returned when you attempt to get the next
@@ -63,6 +67,42 @@ type SavedShowPage struct {
 type SimplePlaylistPage struct {
 	basePage
 	Playlists []SimplePlaylist `json:"items"`
+	// Unavailable is the number of playlists on this page that Spotify
+	// returned as null, such as Spotify-owned playlists in search results,
+	// which are left out of Playlists.  They are still counted by Total and
+	// the paging offsets.
+	Unavailable int `json:"-"`
+	// SearchFallback is true if the playlists were found by searching,
+	// because the endpoint that lists them isn't available.  See
+	// [SearchFallback].
+	SearchFallback bool `json:"-"`
+}
+
+// UnmarshalJSON skips the null entries that Spotify returns in place of some
+// playlists, counting them in Unavailable.
+func (p *SimplePlaylistPage) UnmarshalJSON(data []byte) error {
+	var page struct {
+		basePage
+		Playlists []*SimplePlaylist `json:"items"`
+	}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return err
+	}
+	p.basePage = page.basePage
+	p.Playlists = nil
+	p.Unavailable = 0
+	p.SearchFallback = false
+	if page.Playlists != nil {
+		p.Playlists = make([]SimplePlaylist, 0, len(page.Playlists))
+	}
+	for _, playlist := range page.Playlists {
+		if playlist == nil {
+			p.Unavailable++
+			continue
+		}
+		p.Playlists = append(p.Playlists, *playlist)
+	}
+	return nil
 }
 
 // SimpleTrackPage contains [SimpleTracks] returned by the Web API.
@@ -107,56 +147,268 @@ type SimpleShowPage struct {
 	Shows []FullShow `json:"items"`
 }
 
+// SimpleAudiobookPage contains [SimpleAudiobooks] returned by the Web API.
+type SimpleAudiobookPage struct {
+	basePage
+	Audiobooks []SimpleAudiobook `json:"items"`
+}
+
+//go:generate go run page_gen.go
+
 // pageable is an internal interface for types that support paging
-// by embedding basePage.
-type pageable interface{ canPage() }
+// by embedding basePage.  The methods of the page types are generated by
+// page_gen.go, which must be run again whenever a page type is added.
+type pageable interface {
+	// page returns the paging fields, or nil if the page is a nil pointer.
+	page() *basePage
+	// reset zeroes the page, so that it can be overwritten.  This is
+	// necessary because encoding/json does not clear out existing values
+	// when unmarshaling JSON null.
+	reset()
+}
+
+func (b *basePage) page() *basePage { return b }
 
-func (b *basePage) canPage() {}
+func (b *basePage) reset() { *b = basePage{} }
 
 // NextPage fetches the next page of items and writes them into p.
 // It returns [ErrNoMorePages] if p already contains the last page.
 func (c *Client) NextPage(ctx context.Context, p pageable) error {
-	if p == nil || reflect.ValueOf(p).IsNil() {
+	if p == nil || p.page() == nil {
 		return fmt.Errorf("spotify: p must be a non-nil pointer to a page")
 	}
 
-	val := reflect.ValueOf(p).Elem()
-	field := val.FieldByName("Next")
-	nextURL := field.Interface().(string)
-
+	nextURL := p.page().Next
 	if len(nextURL) == 0 {
 		return ErrNoMorePages
 	}
 
-	// Zero out the page so that we can overwrite it in the next
-	// call to get. This is necessary because encoding/json does
-	// not clear out existing values when unmarshaling JSON null.
-	zero := reflect.Zero(val.Type())
-	val.Set(zero)
+	p.reset()
+	if data, ok := c.takePrefetched(ctx, nextURL); ok {
+		if err := json.Unmarshal(data, p); err == nil {
+			c.prefetchNext(ctx, p)
+			c.resolveRelinks(p)
+			return nil
+		}
+		p.reset()
+	}
 
-	return c.get(ctx, nextURL, p)
+	err := c.get(ctx, nextURL, p)
+	if err != nil {
+		return err
+	}
+	c.resolveRelinks(p)
+	return nil
 }
 
 // PreviousPage fetches the previous page of items and writes them into p.
 // It returns [ErrNoMorePages] if p already contains the last page.
 func (c *Client) PreviousPage(ctx context.Context, p pageable) error {
-	if p == nil || reflect.ValueOf(p).IsNil() {
+	if p == nil || p.page() == nil {
 		return fmt.Errorf("spotify: p must be a non-nil pointer to a page")
 	}
 
-	val := reflect.ValueOf(p).Elem()
-	field := val.FieldByName("Previous")
-	prevURL := field.Interface().(string)
-
+	prevURL := p.page().Previous
 	if len(prevURL) == 0 {
 		return ErrNoMorePages
 	}
 
-	// Zero out the page so that we can overwrite it in the next
-	// call to get. This is necessary because encoding/json does
-	// not clear out existing values when unmarshaling JSON null.
-	zero := reflect.Zero(val.Type())
-	val.Set(zero)
+	p.reset()
+	err := c.get(ctx, prevURL, p)
+	if err != nil {
+		return err
+	}
+	c.resolveRelinks(p)
+	return nil
+}
+
+// resumeToken is the content of the tokens returned by ResumeToken.
+type resumeToken struct {
+	Version int    `json:"v"`
+	Next    string `json:"next"`
+}
+
+// ResumeToken returns an opaque token that can be stored to checkpoint a long
+// listing, and passed to [Client.ResumePage] to fetch the page that follows
+// this one, for example after a crash.  It returns an empty string if this is
+// the last page.
+func (b *basePage) ResumeToken() string {
+	if b.Next == "" {
+		return ""
+	}
+	data, _ := json.Marshal(resumeToken{Version: 1, Next: b.Next})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ResumePage fetches the page following the one whose ResumeToken method
+// returned token, and writes it into p, which must be of the same type as
+// that page.  It returns [ErrNoMorePages] if token is empty.
+//
+// Tokens are only valid for the client's base URL, so that a tampered token
+// can't send the client's credentials to another host.
+func (c *Client) ResumePage(ctx context.Context, token string, p pageable) error {
+	if p == nil || p.page() == nil {
+		return fmt.Errorf("spotify: p must be a non-nil pointer to a page")
+	}
+	if token == "" {
+		return ErrNoMorePages
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return fmt.Errorf("spotify: invalid resume token: %w", err)
+	}
+	var t resumeToken
+	if err := json.Unmarshal(data, &t); err != nil {
+		return fmt.Errorf("spotify: invalid resume token: %w", err)
+	}
+	if t.Version != 1 {
+		return fmt.Errorf("spotify: unsupported resume token version %d", t.Version)
+	}
+	if !strings.HasPrefix(t.Next, c.baseURL) {
+		return fmt.Errorf("spotify: resume token is not for %s", c.baseURL)
+	}
+
+	p.reset()
+	if err := c.get(ctx, t.Next, p); err != nil {
+		return err
+	}
+	c.resolveRelinks(p)
+	return nil
+}
+
+// PageFetchFunc fetches the limit items starting at offset of an offset-based
+// listing, stores them, and returns the total number of items in the listing.
+// It is called concurrently by [FetchAllPagesParallel], so it must be safe for
+// concurrent use.
+type PageFetchFunc func(ctx context.Context, offset, limit int) (total int, err error)
+
+// FetchAllPagesParallel fetches every page of an offset-based listing with
+// fetch.  The first page is fetched on its own to learn the total number of
+// items; the offsets of the remaining pages are then fetched by up to
+// concurrency workers at once.  Pages are fetched in no particular order, so
+// fetch should store the items by offset, for example in [OrderedPages].
+// Requests that are rate limited are
+// retried after the delay requested by Spotify.  If a page fails, the
+// remaining pages are cancelled and the error is returned.
+//
+// For example, to download the user's saved tracks:
+//
+//	var pages spotify.OrderedPages
+//	err := spotify.FetchAllPagesParallel(ctx, func(ctx context.Context, offset, limit int) (int, error) {
+//		page, err := client.CurrentUsersTracks(ctx, spotify.Offset(offset), spotify.Limit(limit))
+//		if err != nil {
+//			return 0, err
+//		}
+//		pages.Add(offset, page.Tracks)
+//		return int(page.Total), nil
+//	}, 50, 4)
+//	if err != nil {
+//		return err
+//	}
+//	var tracks []spotify.SavedTrack
+//	pages.Each(func(page interface{}) {
+//		tracks = append(tracks, page.([]spotify.SavedTrack)...)
+//	})
+func FetchAllPagesParallel(ctx context.Context, fetch PageFetchFunc, limit, concurrency int) error {
+	if limit < 1 {
+		return fmt.Errorf("spotify: limit must be positive, got %d", limit)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var total int
+	err := RetryRateLimited(ctx, func() error {
+		var err error
+		total, err = fetch(ctx, 0, limit)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		offsets  = make(chan int)
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := range offsets {
+				err := RetryRateLimited(ctx, func() error {
+					_, err := fetch(ctx, offset, limit)
+					return err
+				})
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
 
-	return c.get(ctx, prevURL, p)
+feed:
+	for offset := limit; offset < total; offset += limit {
+		select {
+		case offsets <- offset:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(offsets)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// OrderedPages collects pages that are fetched in no particular order, such
+// as those fetched by [FetchAllPagesParallel], so that they can be read back
+// in order of offset.  It is safe for concurrent use, and the zero value is
+// ready to use.
+type OrderedPages struct {
+	mu    sync.Mutex
+	pages map[int]interface{}
+}
+
+// Add stores the page found at offset, replacing any page already stored
+// there.
+func (p *OrderedPages) Add(offset int, page interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pages == nil {
+		p.pages = make(map[int]interface{})
+	}
+	p.pages[offset] = page
+}
+
+// Each calls fn with every page, lowest offset first.
+func (p *OrderedPages) Each(fn func(page interface{})) {
+	p.mu.Lock()
+	offsets := make([]int, 0, len(p.pages))
+	for offset := range p.pages {
+		offsets = append(offsets, offset)
+	}
+	sort.Ints(offsets)
+	pages := make([]interface{}, len(offsets))
+	for i, offset := range offsets {
+		pages[i] = p.pages[offset]
+	}
+	p.mu.Unlock()
+
+	for _, page := range pages {
+		fn(page)
+	}
 }
@@ -0,0 +1,84 @@
+//go:build ignore
+// +build ignore
+
+// This program generates page_methods.go, which implements the pageable
+// interface for every type that embeds basePage.  Run it with go generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+const output = "page_methods.go"
+
+func main() {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi os.FileInfo) bool {
+		name := fi.Name()
+		return !strings.HasSuffix(name, "_test.go") && name != output
+	}, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var pages []string
+	for _, f := range pkgs["spotify"].Files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if st, ok := ts.Type.(*ast.StructType); ok && embedsBasePage(st) {
+					pages = append(pages, ts.Name.Name)
+				}
+			}
+		}
+	}
+	sort.Strings(pages)
+
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "// Code generated by page_gen.go; DO NOT EDIT.")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "package spotify")
+	for _, p := range pages {
+		fmt.Fprintf(&b, `
+func (p *%[1]s) page() *basePage {
+	if p == nil {
+		return nil
+	}
+	return &p.basePage
+}
+
+func (p *%[1]s) reset() { *p = %[1]s{} }
+`, p)
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// embedsBasePage reports whether st embeds basePage.
+func embedsBasePage(st *ast.StructType) bool {
+	for _, field := range st.Fields.List {
+		if id, ok := field.Type.(*ast.Ident); ok && len(field.Names) == 0 && id.Name == "basePage" {
+			return true
+		}
+	}
+	return false
+}
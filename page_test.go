@@ -3,9 +3,19 @@ package spotify
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestClient_NextPage(t *testing.T) {
@@ -117,3 +127,209 @@ func TestClient_PreviousPage(t *testing.T) {
 		})
 	}
 }
+
+func TestResumePage(t *testing.T) {
+	client, server := testClientString(200, `{"total": 100, "offset": 50}`, func(r *http.Request) {
+		assert.Equal(t, "/v1/me/tracks?offset=50", r.URL.RequestURI())
+	})
+	defer server.Close()
+	client.baseURL = server.URL + "/v1/"
+
+	page := SavedTrackPage{basePage: basePage{Next: server.URL + "/v1/me/tracks?offset=50"}}
+	token := page.ResumeToken()
+
+	var resumed SavedTrackPage
+	if err := client.ResumePage(context.Background(), token, &resumed); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 50, int(resumed.Offset))
+
+	assert.Equal(t, "", resumed.ResumeToken())
+	assert.Equal(t, ErrNoMorePages, client.ResumePage(context.Background(), "", &resumed))
+
+	other := SavedTrackPage{basePage: basePage{Next: "https://example.com/v1/me/tracks?offset=50"}}
+	assert.Error(t, client.ResumePage(context.Background(), other.ResumeToken(), &resumed))
+	assert.Error(t, client.ResumePage(context.Background(), "not a token", &resumed))
+}
+
+func TestPagePrefetch(t *testing.T) {
+	var requests int32
+	prefetched := make(chan struct{})
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Query().Get("offset") == "1" {
+			fmt.Fprint(w, `{"items": [{"id": "t2"}], "offset": 1, "total": 2}`)
+			close(prefetched)
+			return
+		}
+		fmt.Fprintf(w, `{"items": [{"id": "t1"}], "total": 2, "next": "%s/albums/album/tracks?offset=1"}`, server.URL)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithPagePrefetch())
+
+	page, err := client.GetAlbumTracks(context.Background(), "album")
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-prefetched:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Next page wasn't prefetched")
+	}
+
+	if err := client.NextPage(context.Background(), page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Tracks) != 1 || page.Tracks[0].ID != "t2" {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("Expected 2 requests, got %d", n)
+	}
+	if err := client.NextPage(context.Background(), page); err != ErrNoMorePages {
+		t.Errorf("Expected ErrNoMorePages, got %v", err)
+	}
+}
+
+func TestPagePrefetchWithoutHeaders(t *testing.T) {
+	prefetched := make(chan string, 1)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") == "1" {
+			fmt.Fprint(w, `{"items": [{"id": "t2"}], "offset": 1, "total": 2}`)
+			prefetched <- r.Header.Get("X-Experiment")
+			return
+		}
+		if got := r.Header.Get("X-Experiment"); got != "preview" {
+			t.Errorf("Expected the custom header on the first page, got %q", got)
+		}
+		fmt.Fprintf(w, `{"items": [{"id": "t1"}], "total": 2, "next": "%s/albums/album/tracks?offset=1"}`, server.URL)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithPagePrefetch())
+
+	if _, err := client.GetAlbumTracks(context.Background(), "album", WithHeader("X-Experiment", "preview")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-prefetched:
+		if got != "" {
+			t.Errorf("Expected the next page to be prefetched without the custom header, got %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Next page wasn't prefetched")
+	}
+}
+
+func TestFetchAllPagesParallel(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		offsets []int
+	)
+	err := FetchAllPagesParallel(context.Background(), func(ctx context.Context, offset, limit int) (int, error) {
+		if limit != 10 {
+			t.Errorf("Expected limit 10, got %d", limit)
+		}
+		mu.Lock()
+		offsets = append(offsets, offset)
+		mu.Unlock()
+		return 35, nil
+	}, 10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Ints(offsets)
+	if fmt.Sprint(offsets) != "[0 10 20 30]" {
+		t.Errorf("Unexpected offsets: %v", offsets)
+	}
+
+	failure := errors.New("failed")
+	err = FetchAllPagesParallel(context.Background(), func(ctx context.Context, offset, limit int) (int, error) {
+		if offset == 20 {
+			return 0, failure
+		}
+		return 100, nil
+	}, 10, 2)
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected the page's error, got %v", err)
+	}
+}
+
+func TestOrderedPages(t *testing.T) {
+	var pages OrderedPages
+	pages.Each(func(interface{}) {
+		t.Error("Expected no pages")
+	})
+
+	var wg sync.WaitGroup
+	for _, offset := range []int{40, 0, 20, 10, 30} {
+		wg.Add(1)
+		go func(offset int) {
+			defer wg.Done()
+			pages.Add(offset, offset/10)
+		}(offset)
+	}
+	wg.Wait()
+
+	var got []int
+	pages.Each(func(page interface{}) {
+		got = append(got, page.(int))
+	})
+	if fmt.Sprint(got) != "[0 1 2 3 4]" {
+		t.Errorf("Expected the pages in order of offset, got %v", got)
+	}
+}
+
+// TestPageMethodsGenerated checks that page_methods.go is up to date, as a
+// page type without its own reset method would fall back to the one of
+// basePage, and keep the items of the previous page.
+func TestPageMethodsGenerated(t *testing.T) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := make(map[string]bool)
+	resets := make(map[string]bool)
+	for name, f := range pkgs["spotify"].Files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					for _, field := range st.Fields.List {
+						if id, ok := field.Type.(*ast.Ident); ok && len(field.Names) == 0 && id.Name == "basePage" {
+							pages[ts.Name.Name] = true
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Name.Name != "reset" || d.Recv == nil {
+					continue
+				}
+				if star, ok := d.Recv.List[0].Type.(*ast.StarExpr); ok {
+					resets[star.X.(*ast.Ident).Name] = true
+				}
+			}
+		}
+	}
+	if len(pages) == 0 {
+		t.Fatal("Expected to find page types")
+	}
+	for page := range pages {
+		if !resets[page] {
+			t.Errorf("%s has no reset method; run go generate", page)
+		}
+	}
+}
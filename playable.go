@@ -0,0 +1,58 @@
+package spotify
+
+// FilterPlayable returns the tracks that Spotify reported as playable.  Tracks
+// are only reported as unplayable when they were requested with the [Market]
+// option, so tracks without [FullTrack.IsPlayable] are kept.  The order of the
+// tracks is preserved, and tracks isn't modified.
+func FilterPlayable(tracks []FullTrack) []FullTrack {
+	return filterTracks(tracks, func(t *FullTrack) bool {
+		return t.IsPlayable == nil || *t.IsPlayable
+	})
+}
+
+// FilterPlayableForUser is like [FilterPlayable], but also drops the tracks
+// that user can't play:
+//
+//   - tracks requested without a market are dropped if their
+//     AvailableMarkets don't include the user's country.
+//   - explicit tracks are dropped if the user has enabled the explicit
+//     content filter.
+//
+// user should be the result of [Client.CurrentUser] with the
+// [ScopeUserReadPrivate] scope; without it, the user's country and settings
+// are unknown, and FilterPlayableForUser behaves like FilterPlayable.  It also
+// does if user is nil.
+func FilterPlayableForUser(tracks []FullTrack, user *PrivateUser) []FullTrack {
+	if user == nil {
+		return FilterPlayable(tracks)
+	}
+	return filterTracks(tracks, func(t *FullTrack) bool {
+		if t.IsPlayable != nil {
+			if !*t.IsPlayable {
+				return false
+			}
+		} else if user.Country != "" && len(t.AvailableMarkets) > 0 && !containsString(t.AvailableMarkets, user.Country) {
+			return false
+		}
+		return !(t.Explicit && user.ExplicitContent.FilterEnabled)
+	})
+}
+
+func filterTracks(tracks []FullTrack, keep func(*FullTrack) bool) []FullTrack {
+	var result []FullTrack
+	for i := range tracks {
+		if keep(&tracks[i]) {
+			result = append(result, tracks[i])
+		}
+	}
+	return result
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package spotify
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFilterPlayable(t *testing.T) {
+	yes, no := true, false
+	track := func(id ID, playable *bool, explicit bool, markets ...string) FullTrack {
+		return FullTrack{
+			SimpleTrack: SimpleTrack{ID: id, Explicit: explicit, AvailableMarkets: markets},
+			IsPlayable:  playable,
+		}
+	}
+	tracks := []FullTrack{
+		track("playable", &yes, false),
+		track("unplayable", &no, false),
+		track("unknown", nil, false),
+		track("explicit", &yes, true),
+		track("elsewhere", nil, false, "US", "CA"),
+		track("here", nil, false, "SE"),
+	}
+	ids := func(tracks []FullTrack) string {
+		var ids []ID
+		for _, t := range tracks {
+			ids = append(ids, t.ID)
+		}
+		return fmt.Sprint(ids)
+	}
+
+	if got, want := ids(FilterPlayable(tracks)), "[playable unknown explicit elsewhere here]"; got != want {
+		t.Errorf("FilterPlayable: expected %s, got %s", want, got)
+	}
+
+	user := &PrivateUser{Country: "SE", ExplicitContent: ExplicitContentSettings{FilterEnabled: true}}
+	if got, want := ids(FilterPlayableForUser(tracks, user)), "[playable unknown here]"; got != want {
+		t.Errorf("FilterPlayableForUser: expected %s, got %s", want, got)
+	}
+
+	if got, want := ids(FilterPlayableForUser(tracks, &PrivateUser{})), ids(FilterPlayable(tracks)); got != want {
+		t.Errorf("FilterPlayableForUser without settings: expected %s, got %s", want, got)
+	}
+
+	if got, want := ids(FilterPlayableForUser(tracks, nil)), ids(FilterPlayable(tracks)); got != want {
+		t.Errorf("FilterPlayableForUser without a user: expected %s, got %s", want, got)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -21,12 +23,58 @@ type PlayerDevice struct {
 	Restricted bool `json:"is_restricted"`
 	// Name The name of the device.
 	Name string `json:"name"`
-	// Type of device, such as "Computer", "Smartphone" or "Speaker".
-	Type string `json:"type"`
+	// Type of device, such as [DeviceTypeComputer], [DeviceTypeSmartphone]
+	// or [DeviceTypeSpeaker].
+	Type DeviceType `json:"type"`
 	// Volume The current volume in percent.
 	Volume Numeric `json:"volume_percent"`
 }
 
+// DeviceType is the type of a [PlayerDevice].
+type DeviceType string
+
+// DeviceType values reported by the Web API.  Spotify may add new types, so
+// don't assume that a device has one of these.
+const (
+	DeviceTypeComputer    DeviceType = "Computer"
+	DeviceTypeTablet      DeviceType = "Tablet"
+	DeviceTypeSmartphone  DeviceType = "Smartphone"
+	DeviceTypeSpeaker     DeviceType = "Speaker"
+	DeviceTypeTV          DeviceType = "TV"
+	DeviceTypeAVR         DeviceType = "AVR"
+	DeviceTypeSTB         DeviceType = "STB"
+	DeviceTypeAudioDongle DeviceType = "AudioDongle"
+	DeviceTypeGameConsole DeviceType = "GameConsole"
+	DeviceTypeCastVideo   DeviceType = "CastVideo"
+	DeviceTypeCastAudio   DeviceType = "CastAudio"
+	DeviceTypeAutomobile  DeviceType = "Automobile"
+	DeviceTypeUnknown     DeviceType = "Unknown"
+)
+
+// Is reports whether t is other, ignoring case.
+func (t DeviceType) Is(other DeviceType) bool {
+	return strings.EqualFold(string(t), string(other))
+}
+
+// IsSpeaker reports whether the device only plays audio through speakers:
+// a speaker, an AV receiver, an audio dongle or an audio cast target.
+func (t DeviceType) IsSpeaker() bool {
+	return t.Is(DeviceTypeSpeaker) || t.Is(DeviceTypeAVR) ||
+		t.Is(DeviceTypeAudioDongle) || t.Is(DeviceTypeCastAudio)
+}
+
+// IsMobile reports whether the device is a smartphone or a tablet.
+func (t DeviceType) IsMobile() bool {
+	return t.Is(DeviceTypeSmartphone) || t.Is(DeviceTypeTablet)
+}
+
+// IsTV reports whether the device is connected to a screen: a TV, a set-top
+// box, a game console or a video cast target.
+func (t DeviceType) IsTV() bool {
+	return t.Is(DeviceTypeTV) || t.Is(DeviceTypeSTB) ||
+		t.Is(DeviceTypeGameConsole) || t.Is(DeviceTypeCastVideo)
+}
+
 // PlayerState contains information about the current playback.
 type PlayerState struct {
 	CurrentlyPlaying
@@ -34,6 +82,9 @@ type PlayerState struct {
 	Device PlayerDevice `json:"device"`
 	// ShuffleState Shuffle is on or off
 	ShuffleState bool `json:"shuffle_state"`
+	// SmartShuffle is true if smart shuffle, which mixes recommendations
+	// into the context, is on.  ShuffleState is also true in that case.
+	SmartShuffle bool `json:"smart_shuffle"`
 	// RepeatState off, track, context
 	RepeatState string `json:"repeat_state"`
 }
@@ -62,6 +113,26 @@ type CurrentlyPlaying struct {
 	Playing bool `json:"is_playing"`
 	// The currently playing track. Can be null.
 	Item *FullTrack `json:"item"`
+	// CurrentlyPlayingType is the type of the item that is playing: "track",
+	// "episode", "ad" or "unknown".  Item is null while an ad is playing.
+	CurrentlyPlayingType string `json:"currently_playing_type"`
+	// Actions lists the playback commands that are currently not allowed,
+	// for example skipping during an ad.
+	Actions PlayerActions `json:"actions"`
+}
+
+// PlayerActions describes which playback commands are allowed in the current
+// context.
+type PlayerActions struct {
+	// Disallows maps actions, such as "pausing", "skipping_next" or
+	// "toggling_shuffle", to true if they are not allowed.  Actions that
+	// are allowed are usually omitted.
+	Disallows map[string]bool `json:"disallows"`
+}
+
+// Allowed reports whether the action, such as "skipping_next", is allowed.
+func (a PlayerActions) Allowed(action string) bool {
+	return !a.Disallows[action]
 }
 
 type RecentlyPlayedItem struct {
@@ -153,15 +224,89 @@ func (c *Client) PlayerDevices(ctx context.Context) ([]PlayerDevice, error) {
 	return result.PlayerDevices, nil
 }
 
+// ErrDeviceNotFound is returned by [Client.FindDeviceByName] and
+// [Client.WaitForDevice] when the user has no device with the given name.
+var ErrDeviceNotFound = errors.New("spotify: device not found")
+
+// FindDeviceByName returns the user's device with the given name, which is
+// matched case-insensitively.  If several devices have the name, the active
+// one is preferred.  It returns [ErrDeviceNotFound] if there is none.
+//
+// Requires the [ScopeUserReadPlaybackState] scope in order to read information
+func (c *Client) FindDeviceByName(ctx context.Context, name string) (*PlayerDevice, error) {
+	devices, err := c.PlayerDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var found *PlayerDevice
+	for i := range devices {
+		if !strings.EqualFold(devices[i].Name, name) {
+			continue
+		}
+		if found == nil || devices[i].Active {
+			found = &devices[i]
+		}
+	}
+	if found == nil {
+		return nil, ErrDeviceNotFound
+	}
+	return found, nil
+}
+
+// deviceWaitInterval is the time between polls in [Client.WaitForDevice].
+var deviceWaitInterval = time.Second
+
+// WaitForDevice waits until a device with the given name is available to the
+// user, which is useful for headless speakers such as librespot that take a
+// while to register with Spotify after they start.  The devices are polled
+// every second, until the device appears or timeout elapses, in which case
+// [ErrDeviceNotFound] is returned.  A timeout of zero waits until ctx is done.
+//
+// If transfer is true, playback is then transferred to the device, keeping the
+// current playback state; this requires [ScopeUserModifyPlaybackState].
+//
+// Requires the [ScopeUserReadPlaybackState] scope in order to read information
+func (c *Client) WaitForDevice(ctx context.Context, name string, timeout time.Duration, transfer bool) (*PlayerDevice, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	for {
+		device, err := c.FindDeviceByName(ctx, name)
+		if err == nil {
+			if transfer && !device.Active {
+				if err := c.TransferPlayback(ctx, device.ID, false); err != nil {
+					return nil, err
+				}
+				device.Active = true
+			}
+			return device, nil
+		}
+		if !errors.Is(err, ErrDeviceNotFound) && ctx.Err() == nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && timeout > 0 {
+				return nil, ErrDeviceNotFound
+			}
+			return nil, ctx.Err()
+		case <-time.After(deviceWaitInterval):
+		}
+	}
+}
+
 // PlayerState gets information about the playing state for the current user
 // Requires the [ScopeUserReadPlaybackState] scope in order to read information
 //
 // Supported options: [Market].
 func (c *Client) PlayerState(ctx context.Context, opts ...RequestOption) (*PlayerState, error) {
-	spotifyURL := c.baseURL + "me/player"
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
-		spotifyURL += "?" + params
-	}
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	spotifyURL := c.apiURL(o.urlParams, "me/player")
 
 	var result PlayerState
 
@@ -181,11 +326,9 @@ func (c *Client) PlayerState(ctx context.Context, opts ...RequestOption) (*Playe
 //
 // Supported options: [Market].
 func (c *Client) PlayerCurrentlyPlaying(ctx context.Context, opts ...RequestOption) (*CurrentlyPlaying, error) {
-	spotifyURL := c.baseURL + "me/player/currently-playing"
-
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
-		spotifyURL += "?" + params
-	}
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	spotifyURL := c.apiURL(o.urlParams, "me/player/currently-playing")
 
 	req, err := http.NewRequestWithContext(ctx, "GET", spotifyURL, nil)
 	if err != nil {
@@ -203,16 +346,30 @@ func (c *Client) PlayerCurrentlyPlaying(ctx context.Context, opts ...RequestOpti
 
 // PlayerRecentlyPlayed gets a list of recently-played tracks for the current
 // user. This call requires [ScopeUserReadRecentlyPlayed].
-func (c *Client) PlayerRecentlyPlayed(ctx context.Context) ([]RecentlyPlayedItem, error) {
-	return c.PlayerRecentlyPlayedOpt(ctx, nil)
+//
+// Supported options: [Limit], [After], [Before].
+func (c *Client) PlayerRecentlyPlayed(ctx context.Context, opts ...RequestOption) ([]RecentlyPlayedItem, error) {
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	spotifyURL := c.apiURL(o.urlParams, "me/player/recently-played")
+
+	result := RecentlyPlayedResult{}
+	err = c.get(ctx, spotifyURL, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Items, nil
 }
 
 // PlayerRecentlyPlayedOpt is like [PlayerRecentlyPlayed], but it accepts
 // additional options for sorting and filtering the results.
 func (c *Client) PlayerRecentlyPlayedOpt(ctx context.Context, opt *RecentlyPlayedOptions) ([]RecentlyPlayedItem, error) {
-	spotifyURL := c.baseURL + "me/player/recently-played"
+	v := url.Values{}
 	if opt != nil {
-		v := url.Values{}
 		if opt.Limit != 0 {
 			v.Set("limit", strconv.FormatInt(int64(opt.Limit), 10))
 		}
@@ -222,10 +379,8 @@ func (c *Client) PlayerRecentlyPlayedOpt(ctx context.Context, opt *RecentlyPlaye
 		if opt.AfterEpochMs != 0 {
 			v.Set("after", strconv.FormatInt(int64(opt.AfterEpochMs), 10))
 		}
-		if params := v.Encode(); params != "" {
-			spotifyURL += "?" + params
-		}
 	}
+	spotifyURL := c.apiURL(v, "me/player/recently-played")
 
 	result := RecentlyPlayedResult{}
 	err := c.get(ctx, spotifyURL, &result)
@@ -246,16 +401,57 @@ func (c *Client) PlayerRecentlyPlayedOpt(ctx context.Context, opt *RecentlyPlaye
 //
 // Requires the [ScopeUserModifyPlaybackState] in order to modify the player state.
 func (c *Client) TransferPlayback(ctx context.Context, deviceID ID, play bool) error {
-	reqData := struct {
-		DeviceID []ID `json:"device_ids"`
-		Play     bool `json:"play"`
-	}{
-		DeviceID: []ID{deviceID},
-		Play:     play,
+	return c.TransferPlaybackOpt(ctx, &TransferOptions{DeviceIDs: []ID{deviceID}, Play: play})
+}
+
+// TransferOptions configures [Client.TransferPlaybackOpt].
+type TransferOptions struct {
+	// DeviceIDs are the devices that playback should be transferred to.
+	// The Web API accepts a list, but at the time of writing, only a single
+	// device is supported.
+	DeviceIDs []ID `json:"device_ids"`
+	// Play starts playback on the new device if true.  Otherwise, the
+	// current playback state is kept.
+	Play bool `json:"play"`
+}
+
+// ErrRestrictedDevice is matched by errors.Is when a playback command fails
+// because the device doesn't accept commands from the Web API.
+var ErrRestrictedDevice = errors.New("spotify: restricted device")
+
+// RestrictedDeviceError is returned by [Client.TransferPlaybackOpt] when
+// Spotify rejects the transfer because the device is restricted, as reported
+// by [PlayerDevice.Restricted].
+type RestrictedDeviceError struct {
+	Err Error
+}
+
+func (e RestrictedDeviceError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying [Error].
+func (e RestrictedDeviceError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is [ErrRestrictedDevice].
+func (e RestrictedDeviceError) Is(target error) bool {
+	return target == ErrRestrictedDevice
+}
+
+// TransferPlaybackOpt is like [Client.TransferPlayback], but accepts several
+// devices.  If a device is restricted, an error matching [ErrRestrictedDevice]
+// is returned.
+//
+// Requires the [ScopeUserModifyPlaybackState] in order to modify the player state.
+func (c *Client) TransferPlaybackOpt(ctx context.Context, opt *TransferOptions) error {
+	if opt == nil || len(opt.DeviceIDs) == 0 {
+		return errors.New("spotify: TransferPlaybackOpt requires at least one device")
 	}
 
 	buf := new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(reqData)
+	err := json.NewEncoder(buf).Encode(opt)
 	if err != nil {
 		return err
 	}
@@ -263,10 +459,16 @@ func (c *Client) TransferPlayback(ctx context.Context, deviceID ID, play bool) e
 	if err != nil {
 		return err
 	}
-	return c.execute(req, nil,
+	err = c.execute(req, nil,
 		http.StatusAccepted,
 		http.StatusNoContent,
 	)
+	var e Error
+	if errors.As(err, &e) && e.Status == http.StatusForbidden &&
+		strings.Contains(strings.ToLower(e.Message), "restrict") {
+		return RestrictedDeviceError{Err: e}
+	}
+	return err
 }
 
 // Play Start a new context or resume current playback on the user's active
@@ -277,23 +479,19 @@ func (c *Client) Play(ctx context.Context) error {
 
 // PlayOpt is like [Play] but with more options.
 func (c *Client) PlayOpt(ctx context.Context, opt *PlayOptions) error {
-	spotifyURL := c.baseURL + "me/player/play"
 	buf := new(bytes.Buffer)
-
+	v := url.Values{}
 	if opt != nil {
-		v := url.Values{}
 		if opt.DeviceID != nil {
 			v.Set("device_id", opt.DeviceID.String())
 		}
-		if params := v.Encode(); params != "" {
-			spotifyURL += "?" + params
-		}
 
 		err := json.NewEncoder(buf).Encode(opt)
 		if err != nil {
 			return err
 		}
 	}
+	spotifyURL := c.apiURL(v, "me/player/play")
 	req, err := http.NewRequestWithContext(ctx, http.MethodPut, spotifyURL, buf)
 	if err != nil {
 		return err
@@ -315,17 +513,11 @@ func (c *Client) Pause(ctx context.Context) error {
 //
 // Only expects [PlayOptions.DeviceID], all other options will be ignored.
 func (c *Client) PauseOpt(ctx context.Context, opt *PlayOptions) error {
-	spotifyURL := c.baseURL + "me/player/pause"
-
-	if opt != nil {
-		v := url.Values{}
-		if opt.DeviceID != nil {
-			v.Set("device_id", opt.DeviceID.String())
-		}
-		if params := v.Encode(); params != "" {
-			spotifyURL += "?" + params
-		}
+	v := url.Values{}
+	if opt != nil && opt.DeviceID != nil {
+		v.Set("device_id", opt.DeviceID.String())
 	}
+	spotifyURL := c.apiURL(v, "me/player/pause")
 	req, err := http.NewRequestWithContext(ctx, http.MethodPut, spotifyURL, nil)
 	if err != nil {
 		return err
@@ -336,15 +528,88 @@ func (c *Client) PauseOpt(ctx context.Context, opt *PlayOptions) error {
 	)
 }
 
+// ErrNoActiveDevice is matched by errors.Is when a playback command fails
+// because the user has no active device.
+var ErrNoActiveDevice = errors.New("spotify: no active device")
+
+// NoActiveDeviceError is returned by [Client.TogglePlayback] when the user
+// has no active device to send a playback command to.
+type NoActiveDeviceError struct {
+	// Err is the error returned by Spotify if it rejected the playback
+	// command.  It is empty if TogglePlayback found that no device was active
+	// without sending one.
+	Err Error
+}
+
+func (e NoActiveDeviceError) Error() string {
+	if e.Err.Status == 0 {
+		return ErrNoActiveDevice.Error()
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying [Error], if any.
+func (e NoActiveDeviceError) Unwrap() error {
+	if e.Err.Status == 0 {
+		return nil
+	}
+	return e.Err
+}
+
+// Is reports whether target is [ErrNoActiveDevice].
+func (e NoActiveDeviceError) Is(target error) bool {
+	return target == ErrNoActiveDevice
+}
+
+// TogglePlayback pauses playback if the user is listening to something, and
+// resumes it otherwise.  It reports whether playback was resumed.
+//
+// If the [DeviceID] option is set and that device isn't the active one,
+// playback is started on it.  Otherwise, if the user has no active device,
+// a [NoActiveDeviceError] is returned, which matches [ErrNoActiveDevice].
+//
+// This call requires [ScopeUserReadPlaybackState] and
+// [ScopeUserModifyPlaybackState].
+//
+// Supported options: [DeviceID].
+func (c *Client) TogglePlayback(ctx context.Context, opts ...RequestOption) (playing bool, err error) {
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	state, err := c.PlayerState(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var deviceOpt *PlayOptions
+	if id := o.urlParams.Get("device_id"); id != "" {
+		device := ID(id)
+		deviceOpt = &PlayOptions{DeviceID: &device}
+	}
+	onDevice := state.Device.ID != "" && (deviceOpt == nil || *deviceOpt.DeviceID == state.Device.ID)
+	if !onDevice && deviceOpt == nil {
+		return false, NoActiveDeviceError{}
+	}
+
+	if onDevice && state.Playing {
+		err = c.PauseOpt(ctx, deviceOpt)
+	} else {
+		playing = true
+		err = c.PlayOpt(ctx, deviceOpt)
+	}
+	var e Error
+	if isNoActiveDevice(err) && errors.As(err, &e) {
+		return false, NoActiveDeviceError{Err: e}
+	}
+	if err != nil {
+		return false, err
+	}
+	return playing, nil
+}
+
 // GetQueue gets the user's queue on the user's currently
 // active device. This call requires [ScopeUserReadPlaybackState]
 func (c *Client) GetQueue(ctx context.Context) (*Queue, error) {
-	spotifyURL := c.baseURL + "me/player/queue"
-	v := url.Values{}
-
-	if params := v.Encode(); params != "" {
-		spotifyURL += "?" + params
-	}
+	spotifyURL := c.apiURL(nil, "me/player/queue")
 
 	var q Queue
 	err := c.get(ctx, spotifyURL, &q)
@@ -366,21 +631,20 @@ func (c *Client) QueueSong(ctx context.Context, trackID ID) error {
 //
 // Only expects [PlayOptions.DeviceID], all other options will be ignored.
 func (c *Client) QueueSongOpt(ctx context.Context, trackID ID, opt *PlayOptions) error {
-	uri := "spotify:track:" + trackID
-	spotifyURL := c.baseURL + "me/player/queue"
-	v := url.Values{}
-
-	v.Set("uri", uri.String())
-
-	if opt != nil {
-		if opt.DeviceID != nil {
-			v.Set("device_id", opt.DeviceID.String())
-		}
+	trackID, err := resolveID(trackID, "track")
+	if err != nil {
+		return err
 	}
+	return c.queueItem(ctx, URI("spotify:track:"+trackID), opt)
+}
 
-	if params := v.Encode(); params != "" {
-		spotifyURL += "?" + params
+func (c *Client) queueItem(ctx context.Context, uri URI, opt *PlayOptions) error {
+	v := url.Values{}
+	v.Set("uri", string(uri))
+	if opt != nil && opt.DeviceID != nil {
+		v.Set("device_id", opt.DeviceID.String())
 	}
+	spotifyURL := c.apiURL(v, "me/player/queue")
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, spotifyURL, nil)
 	if err != nil {
@@ -393,6 +657,190 @@ func (c *Client) QueueSongOpt(ctx context.Context, trackID ID, opt *PlayOptions)
 	)
 }
 
+// DefaultQueueDelay is the time that [Client.QueueItems] waits between items
+// if [QueueOptions.Delay] isn't set.
+const DefaultQueueDelay = 250 * time.Millisecond
+
+// QueueOptions configures [Client.QueueItems].
+type QueueOptions struct {
+	// DeviceID is the device to queue the items on.  If nil, the user's
+	// currently active device is used.
+	DeviceID *ID
+	// Delay is the time to wait between items.  If zero,
+	// [DefaultQueueDelay] is used.
+	Delay time.Duration
+}
+
+// QueueItems adds tracks or episodes, identified by their URIs, to the end of
+// the user's queue, in order.  The Web API only queues one item per request,
+// so the requests are paced by [QueueOptions.Delay], and requests that are
+// rate limited are retried after the delay requested by Spotify.  opt may be
+// nil.
+//
+// If some items couldn't be queued, a *[BatchError] is returned, with one
+// failure per item.  If there is no active device, or ctx is done, the
+// remaining items aren't attempted, and are listed together in the last
+// failure.
+//
+// This call requires [ScopeUserModifyPlaybackState].
+func (c *Client) QueueItems(ctx context.Context, uris []URI, opt *QueueOptions) error {
+	var playOpt *PlayOptions
+	delay := DefaultQueueDelay
+	if opt != nil {
+		if opt.DeviceID != nil {
+			playOpt = &PlayOptions{DeviceID: opt.DeviceID}
+		}
+		if opt.Delay > 0 {
+			delay = opt.Delay
+		}
+	}
+
+	var failures []BatchFailure
+	for i, uri := range uris {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(delay):
+			}
+		}
+		err := ctx.Err()
+		if err == nil {
+			err = RetryRateLimited(ctx, func() error {
+				return c.queueItem(ctx, uri, playOpt)
+			})
+		}
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil || isNoActiveDevice(err) {
+			// nothing else will succeed either
+			failures = append(failures, BatchFailure{URIs: uris[i:], Err: err})
+			break
+		}
+		failures = append(failures, BatchFailure{URIs: []URI{uri}, Err: err})
+	}
+	if len(failures) > 0 {
+		return &BatchError{Failures: failures}
+	}
+	return nil
+}
+
+// DefaultPlayNextPollInterval is the time that [Client.PlayNextOpt] waits
+// between checks for the end of the current track if
+// [PlayNextOptions.PollInterval] isn't set.
+const DefaultPlayNextPollInterval = 2 * time.Second
+
+// PlayNextOptions configures [Client.PlayNextOpt].
+type PlayNextOptions struct {
+	// DeviceID is the device to queue the item on.  If nil, the user's
+	// currently active device is used.
+	DeviceID *ID
+	// SkipQueued makes the item play next even if other items were already
+	// queued, by skipping those items when the current track ends.  The
+	// skipped items are dropped from the queue.
+	SkipQueued bool
+	// PollInterval is the time to wait between checks for the end of the
+	// current track when SkipQueued is set.  If zero,
+	// [DefaultPlayNextPollInterval] is used.
+	PollInterval time.Duration
+}
+
+// PlayNext adds a track or episode to the user's queue, so that it plays
+// after the current track unless other items are already queued.  It is
+// shorthand for PlayNextOpt(ctx, uri, nil).
+func (c *Client) PlayNext(ctx context.Context, uri URI) error {
+	return c.PlayNextOpt(ctx, uri, nil)
+}
+
+// PlayNextOpt is like [Client.PlayNext] but with more options.  opt may be
+// nil.
+//
+// The Web API can only add items to the end of the queue, so "play next" is
+// emulated when [PlayNextOptions.SkipQueued] is set: the queue is read, and
+// if other items are ahead of uri, PlayNextOpt waits for the current track to
+// end and then skips them.  In that case PlayNextOpt blocks until uri starts
+// playing, or ctx is done, so it should usually be called in its own
+// goroutine with a deadline.  This is best effort:
+//
+//   - the queue only lists the next 20 or so items; if uri isn't among them,
+//     nothing is skipped.
+//   - the end of the track is found by polling, so the skipped items may
+//     start playing briefly.
+//   - if the user changes the playback while PlayNextOpt is waiting, or the
+//     current track repeats, the wrong items may be skipped, or PlayNextOpt
+//     may wait until ctx is done.
+//
+// This call requires [ScopeUserModifyPlaybackState], and also
+// [ScopeUserReadPlaybackState] and [ScopeUserReadCurrentlyPlaying] when
+// SkipQueued is set.
+func (c *Client) PlayNextOpt(ctx context.Context, uri URI, opt *PlayNextOptions) error {
+	var playOpt *PlayOptions
+	if opt != nil && opt.DeviceID != nil {
+		playOpt = &PlayOptions{DeviceID: opt.DeviceID}
+	}
+	err := RetryRateLimited(ctx, func() error {
+		return c.queueItem(ctx, uri, playOpt)
+	})
+	if err != nil || opt == nil || !opt.SkipQueued {
+		return err
+	}
+
+	queue, err := c.GetQueue(ctx)
+	if err != nil {
+		return err
+	}
+	ahead := -1
+	for i, item := range queue.Items {
+		if item.URI == uri {
+			ahead = i
+			break
+		}
+	}
+	if ahead <= 0 {
+		return nil
+	}
+
+	interval := opt.PollInterval
+	if interval <= 0 {
+		interval = DefaultPlayNextPollInterval
+	}
+	for current := queue.CurrentlyPlaying.URI; current != ""; {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		state, err := c.PlayerCurrentlyPlaying(ctx)
+		if err != nil {
+			return err
+		}
+		if state.Item == nil || state.Item.URI != current {
+			break
+		}
+	}
+
+	for i := 0; i < ahead; i++ {
+		err := RetryRateLimited(ctx, func() error {
+			return c.NextOpt(ctx, playOpt)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isNoActiveDevice reports whether err is Spotify's response to a playback
+// request made while the user has no active device.  Other 404 responses,
+// such as those for unknown devices, don't count.
+func isNoActiveDevice(err error) bool {
+	var e Error
+	if !errors.As(err, &e) || e.Status != http.StatusNotFound {
+		return false
+	}
+	return e.Reason == "NO_ACTIVE_DEVICE" || strings.Contains(strings.ToLower(e.Message), "no active device")
+}
+
 // Next skips to the next track in the user's queue in the user's
 // currently active device. This call requires [ScopeUserModifyPlaybackState]
 // in order to modify the player state.
@@ -404,17 +852,11 @@ func (c *Client) Next(ctx context.Context) error {
 //
 // Only expects [PlayOptions.DeviceID], all other options will be ignored.
 func (c *Client) NextOpt(ctx context.Context, opt *PlayOptions) error {
-	spotifyURL := c.baseURL + "me/player/next"
-
-	if opt != nil {
-		v := url.Values{}
-		if opt.DeviceID != nil {
-			v.Set("device_id", opt.DeviceID.String())
-		}
-		if params := v.Encode(); params != "" {
-			spotifyURL += "?" + params
-		}
+	v := url.Values{}
+	if opt != nil && opt.DeviceID != nil {
+		v.Set("device_id", opt.DeviceID.String())
 	}
+	spotifyURL := c.apiURL(v, "me/player/next")
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, spotifyURL, nil)
 	if err != nil {
 		return err
@@ -436,17 +878,11 @@ func (c *Client) Previous(ctx context.Context) error {
 //
 // Only expects [PlayOptions.DeviceID], all other options will be ignored.
 func (c *Client) PreviousOpt(ctx context.Context, opt *PlayOptions) error {
-	spotifyURL := c.baseURL + "me/player/previous"
-
-	if opt != nil {
-		v := url.Values{}
-		if opt.DeviceID != nil {
-			v.Set("device_id", opt.DeviceID.String())
-		}
-		if params := v.Encode(); params != "" {
-			spotifyURL += "?" + params
-		}
+	v := url.Values{}
+	if opt != nil && opt.DeviceID != nil {
+		v.Set("device_id", opt.DeviceID.String())
 	}
+	spotifyURL := c.apiURL(v, "me/player/previous")
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, spotifyURL, nil)
 	if err != nil {
 		return err
@@ -550,17 +986,12 @@ func (c *Client) ShuffleOpt(ctx context.Context, shuffle bool, opt *PlayOptions)
 }
 
 func (c *Client) playerFuncWithOpt(ctx context.Context, urlSuffix string, values url.Values, opt *PlayOptions) error {
-	spotifyURL := c.baseURL + urlSuffix
-
 	if opt != nil {
 		if opt.DeviceID != nil {
 			values.Set("device_id", opt.DeviceID.String())
 		}
 	}
-
-	if params := values.Encode(); params != "" {
-		spotifyURL += "?" + params
-	}
+	spotifyURL := c.apiURL(values, urlSuffix)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPut, spotifyURL, nil)
 	if err != nil {
@@ -571,3 +1002,77 @@ func (c *Client) playerFuncWithOpt(ctx context.Context, urlSuffix string, values
 		http.StatusNoContent,
 	)
 }
+
+// NowPlayingChange describes a change of the item being played, as reported by
+// [Client.WatchNowPlaying].
+type NowPlayingChange struct {
+	// Previous is the playback state in which the previous item was last
+	// seen.  It is nil for the first change reported.
+	Previous *CurrentlyPlaying
+	// Current is the playback state in which the new item was first seen.
+	// Its Item is nil if nothing is playing anymore.
+	Current *CurrentlyPlaying
+}
+
+// DefaultNowPlayingInterval is the time that [Client.WatchNowPlaying] waits
+// between polls if the interval passed to it isn't positive.
+const DefaultNowPlayingInterval = 5 * time.Second
+
+// WatchNowPlaying polls [Client.PlayerCurrentlyPlaying] every interval and
+// reports whenever the item being played changes.  Seeking, pausing or
+// resuming the same item does not produce a change.  The first state observed
+// is always reported, with a nil Previous value.  If interval isn't positive,
+// [DefaultNowPlayingInterval] is used.
+//
+// Failed polls are skipped.  The returned channel is closed once ctx is done.
+//
+// Requires the [ScopeUserReadCurrentlyPlaying] scope or the [ScopeUserReadPlaybackState]
+// scope in order to read information.
+//
+// Supported options: [Market].
+func (c *Client) WatchNowPlaying(ctx context.Context, interval time.Duration, opts ...RequestOption) <-chan NowPlayingChange {
+	if interval <= 0 {
+		interval = DefaultNowPlayingInterval
+	}
+	changes := make(chan NowPlayingChange)
+	go func() {
+		defer close(changes)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var last *CurrentlyPlaying
+		first := true
+		for {
+			current, err := c.PlayerCurrentlyPlaying(ctx, opts...)
+			if err == nil {
+				if first || nowPlayingID(last) != nowPlayingID(current) {
+					select {
+					case changes <- NowPlayingChange{Previous: last, Current: current}:
+					case <-ctx.Done():
+						return
+					}
+					first = false
+				}
+				last = current
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return changes
+}
+
+// nowPlayingID identifies the item in cp, or returns the empty string if
+// nothing is playing.
+func nowPlayingID(cp *CurrentlyPlaying) URI {
+	if cp == nil || cp.Item == nil {
+		return ""
+	}
+	return cp.Item.URI
+}
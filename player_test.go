@@ -2,8 +2,13 @@ package spotify
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestTransferPlaybackDeviceUnavailable(t *testing.T) {
@@ -156,6 +161,20 @@ func TestPlayerRecentlyPlayed(t *testing.T) {
 	}
 }
 
+func TestPlayerRecentlyPlayedBefore(t *testing.T) {
+	client, server := testClientFile(http.StatusOK, "test_data/player_recently_played.txt", func(r *http.Request) {
+		if before := r.URL.Query().Get("before"); before != "1495915674721" {
+			t.Errorf("Expected before cursor, got %q", before)
+		}
+	})
+	defer server.Close()
+
+	_, err := client.PlayerRecentlyPlayed(context.Background(), Before("1495915674721"))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestPlayArgsError(t *testing.T) {
 	json := `{
 		"error" : {
@@ -197,3 +216,374 @@ func TestGetQueue(t *testing.T) {
 		t.Error("Expected 'Know Your Enemy', got", p.Name)
 	}
 }
+
+func TestWatchNowPlaying(t *testing.T) {
+	responses := []string{
+		`{"progress_ms": 1000, "is_playing": true, "item": {"id": "a", "uri": "spotify:track:a"}}`,
+		`{"progress_ms": 50000, "is_playing": true, "item": {"id": "a", "uri": "spotify:track:a"}}`,
+		`{"progress_ms": 0, "is_playing": true, "item": {"id": "b", "uri": "spotify:track:b"}}`,
+	}
+	var i int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&i, 1)) - 1
+		if n >= len(responses) {
+			n = len(responses) - 1
+		}
+		_, _ = io.WriteString(w, responses[n])
+	}))
+	defer server.Close()
+
+	client := &Client{http: http.DefaultClient, baseURL: server.URL + "/"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := client.WatchNowPlaying(ctx, time.Millisecond)
+
+	first := <-changes
+	if first.Previous != nil || first.Current.Item.ID != "a" {
+		t.Errorf("Expected initial change to a, got %+v", first)
+	}
+	second := <-changes
+	if second.Previous.Item.ID != "a" || second.Current.Item.ID != "b" {
+		t.Errorf("Expected change from a to b, got %+v", second)
+	}
+	if second.Previous.Progress != 50000 {
+		t.Error("Expected previous state to be the last one seen, got progress", second.Previous.Progress)
+	}
+
+	cancel()
+	for range changes {
+	}
+}
+
+func TestWatchNowPlayingInvalidInterval(t *testing.T) {
+	client, server := testClientString(http.StatusOK, `{"is_playing": true, "item": {"id": "a", "uri": "spotify:track:a"}}`)
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	changes := client.WatchNowPlaying(ctx, 0)
+	if first := <-changes; first.Current.Item.ID != "a" {
+		t.Errorf("Expected initial change to a, got %+v", first)
+	}
+	cancel()
+	for range changes {
+	}
+}
+
+func TestQueueItems(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		switch {
+		case n == 1:
+			w.Header().Set("Retry-After", "0")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"error": {"status": 429, "message": "slow down"}}`)
+		case r.URL.Query().Get("uri") == "spotify:track:bad":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error": {"status": 400, "message": "invalid uri"}}`)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	uris := []URI{"spotify:track:a", "spotify:track:bad", "spotify:episode:c"}
+	err := client.QueueItems(context.Background(), uris, &QueueOptions{Delay: time.Millisecond})
+	batchErr, ok := err.(*BatchError)
+	if !ok {
+		t.Fatalf("Expected a batch error, got %v", err)
+	}
+	if len(batchErr.Failures) != 1 || len(batchErr.Failures[0].URIs) != 1 || batchErr.Failures[0].URIs[0] != "spotify:track:bad" {
+		t.Errorf("Expected only the bad track to fail, got %+v", batchErr.Failures)
+	}
+	if calls != 4 {
+		t.Errorf("Expected 4 requests, got %d", calls)
+	}
+}
+
+func TestQueueItemsNoActiveDevice(t *testing.T) {
+	client, server := testClientString(http.StatusNotFound, `{"error": {"status": 404, "message": "Player command failed: No active device found"}}`)
+	defer server.Close()
+
+	uris := []URI{"spotify:track:a", "spotify:track:b"}
+	err := client.QueueItems(context.Background(), uris, nil)
+	batchErr, ok := err.(*BatchError)
+	if !ok {
+		t.Fatalf("Expected a batch error, got %v", err)
+	}
+	if len(batchErr.Failures) != 1 || len(batchErr.Failures[0].URIs) != 2 {
+		t.Errorf("Expected both tracks in one failure, got %+v", batchErr.Failures)
+	}
+}
+
+func TestPlayNextSkipQueued(t *testing.T) {
+	var polls, skips int
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "POST /me/player/queue":
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /me/player/queue":
+			_, _ = io.WriteString(w, `{
+				"currently_playing": {"uri": "spotify:track:now"},
+				"queue": [{"uri": "spotify:track:a"}, {"uri": "spotify:track:b"}, {"uri": "spotify:track:next"}]
+			}`)
+		case "GET /me/player/currently-playing":
+			polls++
+			if polls == 1 {
+				_, _ = io.WriteString(w, `{"item": {"uri": "spotify:track:now"}}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"item": {"uri": "spotify:track:a"}}`)
+		case "POST /me/player/next":
+			skips++
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	opt := &PlayNextOptions{SkipQueued: true, PollInterval: time.Millisecond}
+	if err := client.PlayNextOpt(context.Background(), "spotify:track:next", opt); err != nil {
+		t.Fatal(err)
+	}
+	if polls != 2 {
+		t.Errorf("Expected to poll twice, got %d", polls)
+	}
+	if skips != 2 {
+		t.Errorf("Expected to skip the 2 queued tracks, got %d skips (%v)", skips, requests)
+	}
+}
+
+func TestPlayNextWithoutSkipping(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.String())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	if err := client.PlayNext(context.Background(), "spotify:episode:e"); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 || requests[0] != "POST /me/player/queue?uri=spotify%3Aepisode%3Ae" {
+		t.Errorf("Expected a single queue request, got %v", requests)
+	}
+}
+
+func TestTogglePlayback(t *testing.T) {
+	tests := []struct {
+		state   string
+		opts    []RequestOption
+		want    string
+		playing bool
+	}{
+		{`{"is_playing": true, "device": {"id": "d1"}}`, nil, "/me/player/pause", false},
+		{`{"is_playing": false, "device": {"id": "d1"}}`, nil, "/me/player/play", true},
+		{`{"is_playing": true, "device": {"id": "d2"}}`, []RequestOption{DeviceID("d2")}, "/me/player/pause?device_id=d2", false},
+		{``, []RequestOption{DeviceID("d2")}, "/me/player/play?device_id=d2", true},
+	}
+	for _, test := range tests {
+		var got string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				if test.state == "" {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				_, _ = io.WriteString(w, test.state)
+				return
+			}
+			got = r.URL.String()
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+		playing, err := client.TogglePlayback(context.Background(), test.opts...)
+		server.Close()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got != test.want || playing != test.playing {
+			t.Errorf("Expected %s (playing %t), got %s (playing %t)", test.want, test.playing, got, playing)
+		}
+	}
+}
+
+func TestTogglePlaybackNoActiveDevice(t *testing.T) {
+	client, server := testClientString(http.StatusNoContent, "")
+	defer server.Close()
+
+	_, err := client.TogglePlayback(context.Background())
+	if !errors.Is(err, ErrNoActiveDevice) || !errors.As(err, &NoActiveDeviceError{}) {
+		t.Errorf("Expected a NoActiveDeviceError, got %v", err)
+	}
+	if err != nil && err.Error() != ErrNoActiveDevice.Error() {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+	if errors.As(err, &Error{}) {
+		t.Error("Expected no Error when no command was sent")
+	}
+}
+
+func TestTogglePlaybackRejected(t *testing.T) {
+	tests := []struct {
+		body           string
+		noActiveDevice bool
+	}{
+		{`{"error": {"status": 404, "message": "Player command failed: No active device found", "reason": "NO_ACTIVE_DEVICE"}}`, true},
+		{`{"error": {"status": 404, "message": "Player command failed: No active device found"}}`, true},
+		{`{"error": {"status": 404, "message": "Device not found"}}`, false},
+	}
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, test.body)
+		}))
+		client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+		_, err := client.TogglePlayback(context.Background(), DeviceID("d2"))
+		server.Close()
+		var noDevice NoActiveDeviceError
+		if got := errors.As(err, &noDevice); got != test.noActiveDevice {
+			t.Errorf("%s: expected NoActiveDeviceError %t, got %v", test.body, test.noActiveDevice, err)
+		}
+		if !errors.As(err, &Error{}) {
+			t.Errorf("%s: expected the Error from Spotify, got %v", test.body, err)
+		}
+	}
+}
+
+func TestFindDeviceByName(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"devices": [
+			{"id": "1", "name": "Kitchen", "is_active": false},
+			{"id": "2", "name": "kitchen", "is_active": true},
+			{"id": "3", "name": "Office", "is_active": false}
+		]}`)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	device, err := client.FindDeviceByName(context.Background(), "KITCHEN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if device.ID != "2" {
+		t.Errorf("Expected the active kitchen device, got %s", device.ID)
+	}
+	if _, err := client.FindDeviceByName(context.Background(), "Garage"); err != ErrDeviceNotFound {
+		t.Errorf("Expected ErrDeviceNotFound, got %v", err)
+	}
+}
+
+func TestWaitForDevice(t *testing.T) {
+	defer func(d time.Duration) { deviceWaitInterval = d }(deviceWaitInterval)
+	deviceWaitInterval = time.Millisecond
+
+	var polls int32
+	var transferred int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			atomic.AddInt32(&transferred, 1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if atomic.AddInt32(&polls, 1) < 3 {
+			_, _ = io.WriteString(w, `{"devices": []}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"devices": [{"id": "pi", "name": "raspotify"}]}`)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	device, err := client.WaitForDevice(context.Background(), "raspotify", time.Second, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if device.ID != "pi" || !device.Active || transferred != 1 {
+		t.Errorf("Expected playback to be transferred to pi, got %+v after %d transfers", device, transferred)
+	}
+
+	if _, err := client.WaitForDevice(context.Background(), "missing", 10*time.Millisecond, false); err != ErrDeviceNotFound {
+		t.Errorf("Expected ErrDeviceNotFound, got %v", err)
+	}
+}
+
+func TestTransferPlaybackOpt(t *testing.T) {
+	client, server := testClientString(http.StatusNoContent, "", func(r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"device_ids":["a","b"],"play":true}` + "\n"; string(body) != want {
+			t.Errorf("Expected body %s, got %s", want, body)
+		}
+	})
+	defer server.Close()
+
+	err := client.TransferPlaybackOpt(context.Background(), &TransferOptions{DeviceIDs: []ID{"a", "b"}, Play: true})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTransferPlaybackRestricted(t *testing.T) {
+	client, server := testClientString(http.StatusForbidden, `{"error": {"status": 403, "message": "Player command failed: Restricted device", "reason": "UNKNOWN"}}`)
+	defer server.Close()
+
+	err := client.TransferPlayback(context.Background(), "speaker", false)
+	if !errors.Is(err, ErrRestrictedDevice) {
+		t.Errorf("Expected ErrRestrictedDevice, got %v", err)
+	}
+}
+
+func TestDeviceType(t *testing.T) {
+	if !DeviceType("speaker").IsSpeaker() || !DeviceTypeCastAudio.IsSpeaker() || DeviceTypeComputer.IsSpeaker() {
+		t.Error("IsSpeaker")
+	}
+	if !DeviceTypeTablet.IsMobile() || DeviceTypeTV.IsMobile() {
+		t.Error("IsMobile")
+	}
+	if !DeviceTypeSTB.IsTV() || DeviceTypeAutomobile.IsTV() {
+		t.Error("IsTV")
+	}
+}
+
+func TestPlayerStateSmartShuffle(t *testing.T) {
+	client, server := testClientString(http.StatusOK, `{
+		"is_playing": true,
+		"shuffle_state": true,
+		"smart_shuffle": true,
+		"currently_playing_type": "ad",
+		"item": null,
+		"actions": {"disallows": {"skipping_next": true}}
+	}`)
+	defer server.Close()
+
+	state, err := client.PlayerState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.SmartShuffle || state.CurrentlyPlayingType != "ad" {
+		t.Errorf("Unexpected state %+v", state)
+	}
+	if state.Actions.Allowed("skipping_next") || !state.Actions.Allowed("pausing") {
+		t.Errorf("Unexpected actions %+v", state.Actions)
+	}
+}
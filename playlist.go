@@ -5,11 +5,19 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
+	"image/jpeg"
 	"io"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/zmb3/spotify/v2/internal/lis"
 )
 
 // PlaylistTracks contains details about the tracks in a playlist.
@@ -35,19 +43,34 @@ type SimplePlaylist struct {
 	// The playlist image.  Note: this field is only  returned for modified,
 	// verified playlists. Otherwise the slice is empty.  If returned, the source
 	// URL for the image is temporary and will expire in less than a day.
-	Images   []Image `json:"images"`
-	Name     string  `json:"name"`
-	Owner    User    `json:"owner"`
-	IsPublic bool    `json:"public"`
+	Images   Images `json:"images"`
+	Name     string `json:"name"`
+	Owner    User   `json:"owner"`
+	IsPublic bool   `json:"public"`
 	// The version identifier for the current playlist. Can be supplied in other
 	// requests to target a specific playlist version.
-	SnapshotID string `json:"snapshot_id"`
+	SnapshotID SnapshotID `json:"snapshot_id"`
 	// A collection to the Web API endpoint where full details of the playlist's
 	// tracks can be retrieved, along with the total number of tracks in the playlist.
 	Tracks PlaylistTracks `json:"tracks"`
 	URI    URI            `json:"uri"`
 }
 
+// SnapshotID identifies a version of a playlist.  It changes whenever the
+// playlist is modified, and is returned by the methods that modify playlists.
+//
+// Passing a snapshot ID to a method that accepts one, such as
+// [Client.RemoveTracksFromPlaylistOpt] or [Client.ReorderPlaylistTracks],
+// makes Spotify apply the positions given to that version of the playlist,
+// even if it was modified since.  Comparing snapshot IDs is the cheapest way
+// to find out whether a playlist changed.
+type SnapshotID string
+
+// String returns the snapshot ID as a string.
+func (s SnapshotID) String() string {
+	return string(s)
+}
+
 // FullPlaylist provides extra playlist data in addition to the data provided by [SimplePlaylist].
 type FullPlaylist struct {
 	SimplePlaylist
@@ -58,21 +81,38 @@ type FullPlaylist struct {
 
 // FeaturedPlaylists gets a [list of playlists featured by Spotify].
 //
-// Supported options: [Locale], [Country], [Timestamp], [Limit], [Offset].
+// Spotify has removed this endpoint for most apps.  With the [SearchFallback]
+// option, if Spotify refuses the request, the playlists are approximated with
+// [Client.GetEditorialPlaylistsFallback] for the [Country] option, and the
+// message is empty.
+//
+// Supported options: [Locale], [Country], [Timestamp], [Limit], [Offset],
+// [SearchFallback].
 //
 // [list of playlists featured by Spotify]: https://developer.spotify.com/documentation/web-api/reference/get-featured-playlists
 func (c *Client) FeaturedPlaylists(ctx context.Context, opts ...RequestOption) (message string, playlists *SimplePlaylistPage, e error) {
-	spotifyURL := c.baseURL + "browse/featured-playlists"
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
-		spotifyURL += "?" + params
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return "", nil, err
 	}
+	ctx = o.context(ctx)
+	spotifyURL := c.apiURL(o.urlParams, "browse/featured-playlists")
 
 	var result struct {
 		Playlists SimplePlaylistPage `json:"playlists"`
 		Message   string             `json:"message"`
 	}
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
+	var apiErr Error
+	if o.searchFallback && errors.As(err, &apiErr) && (apiErr.Status == http.StatusForbidden || apiErr.Status == http.StatusNotFound) {
+		var fallbackOpts []RequestOption
+		if limit, err := strconv.Atoi(o.urlParams.Get("limit")); err == nil {
+			fallbackOpts = append(fallbackOpts, Limit(limit))
+		}
+		playlists, err := c.GetEditorialPlaylistsFallback(ctx, o.urlParams.Get("country"), fallbackOpts...)
+		return "", playlists, err
+	}
 	if err != nil {
 		return "", nil, err
 	}
@@ -80,6 +120,69 @@ func (c *Client) FeaturedPlaylists(ctx context.Context, opts ...RequestOption) (
 	return result.Message, &result.Playlists, nil
 }
 
+// editorialQueries are the searches that GetEditorialPlaylistsFallback
+// combines to approximate the featured playlists.
+var editorialQueries = []string{"top hits", "new music", "chill", "mood", "workout", "party"}
+
+// GetEditorialPlaylistsFallback approximates the playlists that the removed
+// [featured playlists] endpoint returned, for apps that can no longer use it.
+// It searches for public playlists on popular themes, such as top hits, new
+// music and chill, in country, and takes the highest ranked playlists of each
+// search in turn.  The result has its SearchFallback field set.
+//
+// The playlists are chosen by search ranking rather than by Spotify's editors,
+// and Spotify-owned playlists are no longer returned by searches, so this is
+// only a rough replacement, and makes a request per theme.  If country is
+// empty, playlists from all markets are searched.
+//
+// Supported options: [Limit], which defaults to 20.
+//
+// [featured playlists]: https://developer.spotify.com/documentation/web-api/reference/get-featured-playlists
+func (c *Client) GetEditorialPlaylistsFallback(ctx context.Context, country string, opts ...RequestOption) (*SimplePlaylistPage, error) {
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	limit := 20
+	if raw := o.urlParams.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 50 {
+			return nil, fmt.Errorf("spotify: limit must be between 1 and 50, got %q", raw)
+		}
+		limit = n
+	}
+	searchOpts := []RequestOption{Limit(limit)}
+	if country != "" {
+		searchOpts = append(searchOpts, Market(country))
+	}
+
+	var (
+		sources [][]SimplePlaylist
+		ids     [][]ID
+	)
+	for _, query := range editorialQueries {
+		result, err := c.Search(ctx, query, SearchTypePlaylist, searchOpts...)
+		if err != nil {
+			return nil, err
+		}
+		if result.Playlists == nil {
+			continue
+		}
+		playlists := result.Playlists.Playlists
+		sources = append(sources, playlists)
+		ids = append(ids, make([]ID, len(playlists)))
+		for i, p := range playlists {
+			ids[len(ids)-1][i] = p.ID
+		}
+	}
+
+	page := &SimplePlaylistPage{SearchFallback: true}
+	for _, p := range interleave(ids, nil, limit) {
+		page.Playlists = append(page.Playlists, sources[p.source][p.index])
+	}
+	page.Limit = Numeric(limit)
+	page.Total = Numeric(len(page.Playlists))
+	return page, nil
+}
+
 // FollowPlaylist [adds the current user as a follower] of the specified
 // playlist.  Any playlist can be followed, regardless of its private/public
 // status, as long as you know the playlist ID.
@@ -116,8 +219,7 @@ func (c *Client) UnfollowPlaylist(ctx context.Context, playlist ID) error {
 }
 
 func buildFollowURI(url string, playlist ID) string {
-	return fmt.Sprintf("%splaylists/%s/followers",
-		url, string(playlist))
+	return url + "playlists/" + string(playlist) + "/followers"
 }
 
 // GetPlaylistsForUser [gets a list of the playlists] owned or followed by a
@@ -134,14 +236,16 @@ func buildFollowURI(url string, playlist ID) string {
 //
 // [gets a list of the playlists]: https://developer.spotify.com/documentation/web-api/reference/get-list-users-playlists
 func (c *Client) GetPlaylistsForUser(ctx context.Context, userID string, opts ...RequestOption) (*SimplePlaylistPage, error) {
-	spotifyURL := c.baseURL + "users/" + userID + "/playlists"
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
-		spotifyURL += "?" + params
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
 	}
+	ctx = o.context(ctx)
+	spotifyURL := c.apiURL(o.urlParams, "users/", userID, "/playlists")
 
 	var result SimplePlaylistPage
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -149,23 +253,36 @@ func (c *Client) GetPlaylistsForUser(ctx context.Context, userID string, opts ..
 	return &result, err
 }
 
+// playlistURL returns the URL of the playlist identified by playlistID, which
+// may be a URI or share URL, followed by path.
+func (c *Client) playlistURL(query url.Values, playlistID ID, path ...string) (string, error) {
+	id, err := resolveID(playlistID, "playlist")
+	if err != nil {
+		return "", err
+	}
+	return c.apiURL(query, append([]string{"playlists/", string(id)}, path...)...), nil
+}
+
 // GetPlaylist [fetches a playlist] from spotify.
 //
 // Supported options: [Fields].
 //
 // [fetches a playlist]: https://developer.spotify.com/documentation/web-api/reference/get-playlist
 func (c *Client) GetPlaylist(ctx context.Context, playlistID ID, opts ...RequestOption) (*FullPlaylist, error) {
-	spotifyURL := fmt.Sprintf("%splaylists/%s", c.baseURL, playlistID)
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
-		spotifyURL += "?" + params
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	spotifyURL, err := c.playlistURL(o.urlParams, playlistID)
+	if err != nil {
+		return nil, err
 	}
 
 	var playlist FullPlaylist
 
-	err := c.get(ctx, spotifyURL, &playlist)
+	err = c.get(ctx, spotifyURL, &playlist)
 	if err != nil {
 		return nil, err
 	}
+	c.resolveRelinks(&playlist)
 
 	return &playlist, err
 }
@@ -182,17 +299,23 @@ func (c *Client) GetPlaylistTracks(
 	playlistID ID,
 	opts ...RequestOption,
 ) (*PlaylistTrackPage, error) {
-	spotifyURL := fmt.Sprintf("%splaylists/%s/tracks", c.baseURL, playlistID)
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
-		spotifyURL += "?" + params
+	o, err := c.processPagingOptions(100, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	spotifyURL, err := c.playlistURL(o.urlParams, playlistID, "/tracks")
+	if err != nil {
+		return nil, err
 	}
 
 	var result PlaylistTrackPage
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
+	c.resolveRelinks(&result)
 
 	return &result, nil
 }
@@ -213,12 +336,71 @@ type PlaylistItem struct {
 	Track PlaylistItemTrack `json:"track"`
 }
 
-// PlaylistItemTrack is a union type for both tracks and episodes. If both
-// values are null, it's likely that the piece of content is not available in
-// the configured market.
+// URI identifies the track, episode or local file in the playlist item.  It
+// returns the empty string if the item is not available.
+func (item PlaylistItem) URI() URI {
+	switch {
+	case item.Track.Track != nil:
+		return item.Track.Track.URI
+	case item.Track.Episode != nil:
+		return item.Track.Episode.URI
+	case item.Track.Local != nil:
+		return item.Track.Local.URI
+	default:
+		return ""
+	}
+}
+
+// PlaylistItemTrack is a union type for tracks, episodes and local files. If
+// all values are null, it's likely that the piece of content is not available
+// in the configured market.
 type PlaylistItemTrack struct {
 	Track   *FullTrack
 	Episode *EpisodePage
+	// Local is set instead of Track for local files that were added to the
+	// playlist by the Spotify desktop app.
+	Local *LocalTrack
+}
+
+// LocalTrack is a local file in a playlist.  Local files aren't part of the
+// Spotify catalog, so they have no ID, and only the metadata read from the
+// file is available.
+type LocalTrack struct {
+	// The name of the track.
+	Name string `json:"name"`
+	// The artists of the track.  Only their names are set.
+	Artists []SimpleArtist `json:"artists"`
+	// The album of the track.  Only its name is set.
+	Album SimpleAlbum `json:"album"`
+	// The length of the track, in milliseconds.
+	Duration Numeric `json:"duration_ms"`
+	// The local file URI of the track, for example
+	// "spotify:local:Artist:Album:Title:180".
+	URI URI `json:"uri"`
+}
+
+// TimeDuration returns the track's duration as a [time.Duration] value.
+func (t *LocalTrack) TimeDuration() time.Duration {
+	return time.Duration(t.Duration) * time.Millisecond
+}
+
+// MarshalJSON encodes the track or episode, so that the result can be decoded
+// with UnmarshalJSON.
+func (t PlaylistItemTrack) MarshalJSON() ([]byte, error) {
+	switch {
+	case t.Track != nil:
+		return json.Marshal(t.Track)
+	case t.Episode != nil:
+		return json.Marshal(t.Episode)
+	case t.Local != nil:
+		return json.Marshal(struct {
+			*LocalTrack
+			Type    string `json:"type"`
+			IsLocal bool   `json:"is_local"`
+		}{t.Local, "track", true})
+	default:
+		return []byte("null"), nil
+	}
 }
 
 // UnmarshalJSON customises the unmarshalling based on the type flags set.
@@ -231,7 +413,8 @@ func (t *PlaylistItemTrack) UnmarshalJSON(b []byte) error {
 	}
 
 	itemType := struct {
-		Type string `json:"type"`
+		Type    string `json:"type"`
+		IsLocal bool   `json:"is_local"`
 	}{}
 
 	err := json.Unmarshal(b, &itemType)
@@ -243,6 +426,9 @@ func (t *PlaylistItemTrack) UnmarshalJSON(b []byte) error {
 	case "episode":
 		return json.Unmarshal(b, &t.Episode)
 	case "track":
+		if itemType.IsLocal {
+			return json.Unmarshal(b, &t.Local)
+		}
 		return json.Unmarshal(b, &t.Track)
 	default:
 		return fmt.Errorf("unrecognized item type: %s", itemType.Type)
@@ -263,25 +449,152 @@ type PlaylistItemPage struct {
 // [gets full details of the items in a playlist]: https://developer.spotify.com/documentation/web-api/reference/get-playlists-tracks
 // [Spotify ID]: https://developer.spotify.com/documentation/web-api/#spotify-uris-and-ids
 func (c *Client) GetPlaylistItems(ctx context.Context, playlistID ID, opts ...RequestOption) (*PlaylistItemPage, error) {
-	spotifyURL := fmt.Sprintf("%splaylists/%s/tracks", c.baseURL, playlistID)
-
 	// Add default as the first option so it gets override by url.Values#Set
 	opts = append([]RequestOption{AdditionalTypes(EpisodeAdditionalType, TrackAdditionalType)}, opts...)
 
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
-		spotifyURL += "?" + params
+	o, err := c.processPagingOptions(100, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	spotifyURL, err := c.playlistURL(o.urlParams, playlistID, "/tracks")
+	if err != nil {
+		return nil, err
 	}
 
 	var result PlaylistItemPage
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
+	c.resolveRelinks(&result)
 
 	return &result, nil
 }
 
+// GetAllPlaylistItems gets every item in a playlist, following the pages of
+// [Client.GetPlaylistItems].
+//
+// If the client was configured with [WithPlaylistCache], the items are cached
+// under the playlist's snapshot ID.  Subsequent calls then only request the
+// playlist's current snapshot ID, and return the cached items if it hasn't
+// changed.
+//
+// Supported options: [Market], [Fields].
+func (c *Client) GetAllPlaylistItems(ctx context.Context, playlistID ID, opts ...RequestOption) ([]PlaylistItem, error) {
+	params := processOptions(opts...).urlParams
+	cacheable := c.playlistCache != nil && params.Get("market") != MarketFromToken
+	var key string
+	if cacheable {
+		head, err := c.GetPlaylist(ctx, playlistID, Fields("snapshot_id"))
+		if err != nil {
+			return nil, err
+		}
+		key = fmt.Sprintf("%s playlist-items %s %s?%s", c.acceptLanguage, playlistID, head.SnapshotID, params.Encode())
+		if data, ok, err := c.playlistCache.Get(key); err == nil && ok {
+			var items []PlaylistItem
+			if err := json.Unmarshal(data, &items); err == nil {
+				return items, nil
+			}
+		}
+	}
+
+	page, err := c.GetPlaylistItems(ctx, playlistID, append([]RequestOption{Limit(100)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	items := page.Items
+	for {
+		err = c.NextPage(ctx, page)
+		if errors.Is(err, ErrNoMorePages) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+	}
+
+	if cacheable {
+		if data, err := json.Marshal(items); err == nil {
+			_ = c.playlistCache.Set(key, data, c.playlistCacheTTL)
+		}
+	}
+	return items, nil
+}
+
+// PlaylistContainsTracks reports whether each of the tracks identified by ids
+// is in a playlist.  The result is in the same order as ids.
+//
+// The Spotify API has no endpoint to check this directly, so the playlist's
+// items are paged through, requesting only the fields that are needed to
+// identify each track.  Tracks that were [relinked] match both their original
+// and their relinked ID.
+//
+// [relinked]: https://developer.spotify.com/documentation/general/guides/track-relinking-guide/
+func (c *Client) PlaylistContainsTracks(ctx context.Context, playlistID ID, ids ...ID) ([]bool, error) {
+	ids, err := resolveIDs(ids, "track")
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[ID]bool, len(ids))
+	for _, id := range ids {
+		found[id] = false
+	}
+	remaining := len(found)
+	mark := func(id ID) {
+		if seen, ok := found[id]; ok && !seen {
+			found[id] = true
+			remaining--
+		}
+	}
+
+	v := url.Values{}
+	v.Set("fields", "items(track(id,linked_from(id))),next")
+	v.Set("limit", "100")
+	spotifyURL, err := c.playlistURL(v, playlistID, "/tracks")
+	if err != nil {
+		return nil, err
+	}
+	for spotifyURL != "" {
+		var page struct {
+			Items []struct {
+				Track *struct {
+					ID         ID `json:"id"`
+					LinkedFrom *struct {
+						ID ID `json:"id"`
+					} `json:"linked_from"`
+				} `json:"track"`
+			} `json:"items"`
+			Next string `json:"next"`
+		}
+		if err := c.get(ctx, spotifyURL, &page); err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			if item.Track == nil {
+				continue
+			}
+			mark(item.Track.ID)
+			if item.Track.LinkedFrom != nil {
+				mark(item.Track.LinkedFrom.ID)
+			}
+		}
+		// there's no need to look any further once every track was found
+		if remaining == 0 {
+			break
+		}
+		spotifyURL = page.Next
+	}
+
+	result := make([]bool, len(ids))
+	for i, id := range ids {
+		result[i] = found[id]
+	}
+	return result, nil
+}
+
 // CreatePlaylistForUser [creates a playlist] for a Spotify user.
 // The playlist will be empty until you add tracks to it.
 // The playlistName does not need to be unique - a user can have
@@ -294,7 +607,7 @@ func (c *Client) GetPlaylistItems(ctx context.Context, playlistID ID, opts ...Re
 //
 // [creates a playlist]: https://developer.spotify.com/documentation/web-api/reference/create-playlist
 func (c *Client) CreatePlaylistForUser(ctx context.Context, userID, playlistName, description string, public bool, collaborative bool) (*FullPlaylist, error) {
-	spotifyURL := fmt.Sprintf("%susers/%s/playlists", c.baseURL, userID)
+	spotifyURL := c.apiURL(nil, "users/", userID, "/playlists")
 	body := struct {
 		Name          string `json:"name"`
 		Public        bool   `json:"public"`
@@ -325,14 +638,70 @@ func (c *Client) CreatePlaylistForUser(ctx context.Context, userID, playlistName
 	return &p, nil
 }
 
+// CreatePlaylist creates a playlist for the current user, like
+// [Client.CreatePlaylistForUser], looking up the current user's ID first.
+//
+// Creating a public playlist requires [ScopePlaylistModifyPublic]; creating a
+// private playlist requires [ScopePlaylistModifyPrivate].
+func (c *Client) CreatePlaylist(ctx context.Context, name, description string, public, collaborative bool) (*FullPlaylist, error) {
+	user, err := c.CurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.CreatePlaylistForUser(ctx, user.ID, name, description, public, collaborative)
+}
+
+// PlaylistDetails describes the changes made by [Client.ChangePlaylistDetails].
+// Fields that are nil are left unchanged.
+type PlaylistDetails struct {
+	Name *string `json:"name,omitempty"`
+	// Public makes the playlist public or private.
+	Public *bool `json:"public,omitempty"`
+	// Collaborative allows other users to modify the playlist.  Only
+	// private playlists can be collaborative.
+	Collaborative *bool   `json:"collaborative,omitempty"`
+	Description   *string `json:"description,omitempty"`
+}
+
+// ChangePlaylistDetails [changes the details of a playlist], such as its
+// name, description, and whether it is public or collaborative, in a single
+// Web API call.  This call requires that the user has authorized the
+// [ScopePlaylistModifyPublic] or [ScopePlaylistModifyPrivate] scopes (depending
+// on whether the playlist is public or private).  The current user must own the
+// playlist in order to modify it.
+//
+// [changes the details of a playlist]: https://developer.spotify.com/documentation/web-api/reference/change-playlist-details
+func (c *Client) ChangePlaylistDetails(ctx context.Context, playlistID ID, details PlaylistDetails) error {
+	if details.Collaborative != nil && *details.Collaborative &&
+		details.Public != nil && *details.Public {
+		return errors.New("spotify: a collaborative playlist can't be public")
+	}
+	bodyJSON, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	spotifyURL, err := c.playlistURL(nil, playlistID)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", spotifyURL, bytes.NewReader(bodyJSON))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.execute(req, nil, http.StatusCreated)
+}
+
 // ChangePlaylistName [changes the name of a playlist].  This call requires that the
 // user has authorized the [ScopePlaylistModifyPublic] or [ScopePlaylistModifyPrivate]
 // scopes (depending on whether the playlist is public or private).
 // The current user must own the playlist in order to modify it.
 //
+// Deprecated: use [Client.ChangePlaylistDetails].
+//
 // [changes the name of a playlist]: https://developer.spotify.com/documentation/web-api/reference/change-playlist-details
 func (c *Client) ChangePlaylistName(ctx context.Context, playlistID ID, newName string) error {
-	return c.modifyPlaylist(ctx, playlistID, newName, "", nil)
+	return c.ChangePlaylistDetails(ctx, playlistID, PlaylistDetails{Name: optionalString(newName)})
 }
 
 // ChangePlaylistAccess [modifies the public/private status of a playlist].  This call
@@ -340,9 +709,11 @@ func (c *Client) ChangePlaylistName(ctx context.Context, playlistID ID, newName
 // [ScopePlaylistModifyPrivate] scopes (depending on whether the playlist is
 // currently public or private).  The current user must own the playlist to modify it.
 //
+// Deprecated: use [Client.ChangePlaylistDetails].
+//
 // [modifies the public/private status of a playlist]: https://developer.spotify.com/documentation/web-api/reference/change-playlist-details
 func (c *Client) ChangePlaylistAccess(ctx context.Context, playlistID ID, public bool) error {
-	return c.modifyPlaylist(ctx, playlistID, "", "", &public)
+	return c.ChangePlaylistDetails(ctx, playlistID, PlaylistDetails{Public: &public})
 }
 
 // ChangePlaylistDescription [modifies the description of a playlist].  This call
@@ -350,48 +721,46 @@ func (c *Client) ChangePlaylistAccess(ctx context.Context, playlistID ID, public
 // [ScopePlaylistModifyPrivate] scopes (depending on whether the playlist is
 // currently public or private).  The current user must own the playlist to modify it.
 //
+// Deprecated: use [Client.ChangePlaylistDetails].
+//
 // [modifies the description of a playlist]: https://developer.spotify.com/documentation/web-api/reference/change-playlist-details
 func (c *Client) ChangePlaylistDescription(ctx context.Context, playlistID ID, newDescription string) error {
-	return c.modifyPlaylist(ctx, playlistID, "", newDescription, nil)
+	return c.ChangePlaylistDetails(ctx, playlistID, PlaylistDetails{Description: optionalString(newDescription)})
 }
 
 // ChangePlaylistNameAndAccess combines [ChangePlaylistName] and [ChangePlaylistAccess] into
 // a single Web API call.  It requires that the user has authorized the [ScopePlaylistModifyPublic]
 // or [ScopePlaylistModifyPrivate] scopes (depending on whether the playlist is currently
 // public or private).  The current user must own the playlist to modify it.
+//
+// Deprecated: use [Client.ChangePlaylistDetails].
 func (c *Client) ChangePlaylistNameAndAccess(ctx context.Context, playlistID ID, newName string, public bool) error {
-	return c.modifyPlaylist(ctx, playlistID, newName, "", &public)
+	return c.ChangePlaylistDetails(ctx, playlistID, PlaylistDetails{
+		Name:   optionalString(newName),
+		Public: &public,
+	})
 }
 
 // ChangePlaylistNameAccessAndDescription combines [ChangePlaylistName], [ChangePlaylistAccess], and
 // [ChangePlaylistDescription] into a single Web API call.  It requires that the user has authorized
 // the [ScopePlaylistModifyPublic] or [ScopePlaylistModifyPrivate] scopes (depending on whether the
 // playlist is currently public or private).  The current user must own the playlist in order to modify it.
+//
+// Deprecated: use [Client.ChangePlaylistDetails].
 func (c *Client) ChangePlaylistNameAccessAndDescription(ctx context.Context, playlistID ID, newName, newDescription string, public bool) error {
-	return c.modifyPlaylist(ctx, playlistID, newName, newDescription, &public)
+	return c.ChangePlaylistDetails(ctx, playlistID, PlaylistDetails{
+		Name:        optionalString(newName),
+		Public:      &public,
+		Description: optionalString(newDescription),
+	})
 }
 
-func (c *Client) modifyPlaylist(ctx context.Context, playlistID ID, newName, newDescription string, public *bool) error {
-	body := struct {
-		Name        string `json:"name,omitempty"`
-		Public      *bool  `json:"public,omitempty"`
-		Description string `json:"description,omitempty"`
-	}{
-		newName,
-		public,
-		newDescription,
-	}
-	bodyJSON, err := json.Marshal(body)
-	if err != nil {
-		return err
-	}
-	spotifyURL := fmt.Sprintf("%splaylists/%s", c.baseURL, string(playlistID))
-	req, err := http.NewRequestWithContext(ctx, "PUT", spotifyURL, bytes.NewReader(bodyJSON))
-	if err != nil {
-		return err
+// optionalString returns a pointer to s, or nil if s is empty.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
 	}
-	req.Header.Set("Content-Type", "application/json")
-	return c.execute(req, nil, http.StatusCreated)
+	return &s
 }
 
 // AddTracksToPlaylist [adds one or more tracks to a user's playlist].
@@ -401,16 +770,22 @@ func (c *Client) modifyPlaylist(ctx context.Context, playlistID ID, newName, new
 // future requests.
 //
 // [adds one or more tracks to a user's playlist]: https://developer.spotify.com/documentation/web-api/reference/add-tracks-to-playlist
-func (c *Client) AddTracksToPlaylist(ctx context.Context, playlistID ID, trackIDs ...ID) (snapshotID string, err error) {
-	uris := make([]string, len(trackIDs))
-	for i, id := range trackIDs {
-		uris[i] = fmt.Sprintf("spotify:track:%s", id)
+func (c *Client) AddTracksToPlaylist(ctx context.Context, playlistID ID, trackIDs ...ID) (snapshotID SnapshotID, err error) {
+	uris, err := resolveTrackURIs(trackIDs)
+	if err != nil {
+		return "", err
 	}
+	return c.addItemsToPlaylist(ctx, playlistID, uris)
+}
+
+func (c *Client) addItemsToPlaylist(ctx context.Context, playlistID ID, uris []string) (snapshotID SnapshotID, err error) {
 	m := make(map[string]interface{})
 	m["uris"] = uris
 
-	spotifyURL := fmt.Sprintf("%splaylists/%s/tracks",
-		c.baseURL, string(playlistID))
+	spotifyURL, err := c.playlistURL(nil, playlistID, "/tracks")
+	if err != nil {
+		return "", err
+	}
 	body, err := json.Marshal(m)
 	if err != nil {
 		return "", err
@@ -422,7 +797,7 @@ func (c *Client) AddTracksToPlaylist(ctx context.Context, playlistID ID, trackID
 	req.Header.Set("Content-Type", "application/json")
 
 	result := struct {
-		SnapshotID string `json:"snapshot_id"`
+		SnapshotID SnapshotID `json:"snapshot_id"`
 	}{}
 
 	err = c.execute(req, &result, http.StatusCreated)
@@ -433,6 +808,61 @@ func (c *Client) AddTracksToPlaylist(ctx context.Context, playlistID ID, trackID
 	return result.SnapshotID, nil
 }
 
+// MergePlaylists appends the items of each of the source playlists to the
+// target playlist, in order.  If dedupe is true, items that are already in the
+// target playlist, or that appear earlier in the sources, are skipped.  Local
+// files and items that aren't available can't be added, and are always
+// skipped.
+//
+// It returns the number of items added from each source, in the same order as
+// sourceIDs.  If an error occurs, the counts reflect the items that were added
+// before it.
+//
+// This call requires [ScopePlaylistModifyPublic] or [ScopePlaylistModifyPrivate],
+// as well as [ScopePlaylistReadPrivate] to read private source playlists.
+func (c *Client) MergePlaylists(ctx context.Context, targetID ID, sourceIDs []ID, dedupe bool) ([]int, error) {
+	seen := make(map[URI]bool)
+	if dedupe {
+		items, err := c.GetAllPlaylistItems(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			seen[item.URI()] = true
+		}
+	}
+
+	counts := make([]int, len(sourceIDs))
+	for i, sourceID := range sourceIDs {
+		items, err := c.GetAllPlaylistItems(ctx, sourceID)
+		if err != nil {
+			return counts, err
+		}
+		var uris []string
+		for _, item := range items {
+			uri := item.URI()
+			if uri == "" || item.IsLocal || (dedupe && seen[uri]) {
+				continue
+			}
+			seen[uri] = true
+			uris = append(uris, string(uri))
+		}
+		// at most 100 items can be added per request
+		for len(uris) > 0 {
+			n := len(uris)
+			if n > 100 {
+				n = 100
+			}
+			if _, err := c.addItemsToPlaylist(ctx, targetID, uris[:n]); err != nil {
+				return counts, err
+			}
+			counts[i] += n
+			uris = uris[n:]
+		}
+	}
+	return counts, nil
+}
+
 // RemoveTracksFromPlaylist [removes one or more tracks from a user's playlist].
 // This call requires that the user has authorized the [ScopePlaylistModifyPublic]
 // or [ScopePlaylistModifyPrivate] scopes.
@@ -442,13 +872,17 @@ func (c *Client) AddTracksToPlaylist(ctx context.Context, playlistID ID, trackID
 // identify the playlist version in future requests.
 //
 // [removes one or more tracks from a user's playlist]: https://developer.spotify.com/documentation/web-api/reference/remove-tracks-playlist
-func (c *Client) RemoveTracksFromPlaylist(ctx context.Context, playlistID ID, trackIDs ...ID) (newSnapshotID string, err error) {
+func (c *Client) RemoveTracksFromPlaylist(ctx context.Context, playlistID ID, trackIDs ...ID) (newSnapshotID SnapshotID, err error) {
+	uris, err := resolveTrackURIs(trackIDs)
+	if err != nil {
+		return "", err
+	}
 	tracks := make([]struct {
 		URI string `json:"uri"`
 	}, len(trackIDs))
 
-	for i, u := range trackIDs {
-		tracks[i].URI = fmt.Sprintf("spotify:track:%s", u)
+	for i, uri := range uris {
+		tracks[i].URI = uri
 	}
 	return c.removeTracksFromPlaylist(ctx, playlistID, tracks, "")
 }
@@ -462,10 +896,15 @@ type TrackToRemove struct {
 }
 
 // NewTrackToRemove returns a [TrackToRemove] with the specified
-// track ID and playlist locations.
+// track ID and playlist locations.  If trackID is the URI of another kind of
+// item, such as an episode, it is used as is.
 func NewTrackToRemove(trackID string, positions []int) TrackToRemove {
+	uri := trackID
+	if uris, err := resolveTrackURIs([]ID{ID(trackID)}); err == nil {
+		uri = uris[0]
+	}
 	return TrackToRemove{
-		URI:       fmt.Sprintf("spotify:track:%s", trackID),
+		URI:       uri,
 		Positions: positions,
 	}
 }
@@ -486,8 +925,8 @@ func (c *Client) RemoveTracksFromPlaylistOpt(
 	ctx context.Context,
 	playlistID ID,
 	tracks []TrackToRemove,
-	snapshotID string,
-) (newSnapshotID string, err error) {
+	snapshotID SnapshotID,
+) (newSnapshotID SnapshotID, err error) {
 	return c.removeTracksFromPlaylist(ctx, playlistID, tracks, snapshotID)
 }
 
@@ -495,16 +934,18 @@ func (c *Client) removeTracksFromPlaylist(
 	ctx context.Context,
 	playlistID ID,
 	tracks interface{},
-	snapshotID string,
-) (newSnapshotID string, err error) {
+	snapshotID SnapshotID,
+) (newSnapshotID SnapshotID, err error) {
 	m := make(map[string]interface{})
 	m["tracks"] = tracks
 	if snapshotID != "" {
 		m["snapshot_id"] = snapshotID
 	}
 
-	spotifyURL := fmt.Sprintf("%splaylists/%s/tracks",
-		c.baseURL, string(playlistID))
+	spotifyURL, err := c.playlistURL(nil, playlistID, "/tracks")
+	if err != nil {
+		return "", err
+	}
 	body, err := json.Marshal(m)
 	if err != nil {
 		return "", err
@@ -516,7 +957,7 @@ func (c *Client) removeTracksFromPlaylist(
 	req.Header.Set("Content-Type", "application/json")
 
 	result := struct {
-		SnapshotID string `json:"snapshot_id"`
+		SnapshotID SnapshotID `json:"snapshot_id"`
 	}{}
 
 	err = c.execute(req, &result)
@@ -540,12 +981,14 @@ func (c *Client) removeTracksFromPlaylist(
 //
 // [replaces all of the tracks in a playlist]: https://developer.spotify.com/documentation/web-api/reference/reorder-or-replace-playlists-tracks
 func (c *Client) ReplacePlaylistTracks(ctx context.Context, playlistID ID, trackIDs ...ID) error {
-	trackURIs := make([]string, len(trackIDs))
-	for i, u := range trackIDs {
-		trackURIs[i] = fmt.Sprintf("spotify:track:%s", u)
+	trackURIs, err := resolveTrackURIs(trackIDs)
+	if err != nil {
+		return err
+	}
+	spotifyURL, err := c.playlistURL(url.Values{"uris": {strings.Join(trackURIs, ",")}}, playlistID, "/tracks")
+	if err != nil {
+		return err
 	}
-	spotifyURL := fmt.Sprintf("%splaylists/%s/tracks?uris=%s",
-		c.baseURL, playlistID, strings.Join(trackURIs, ","))
 	req, err := http.NewRequestWithContext(ctx, "PUT", spotifyURL, nil)
 	if err != nil {
 		return err
@@ -565,7 +1008,7 @@ func (c *Client) ReplacePlaylistTracks(ctx context.Context, playlistID ID, track
 // added via AddTracksToPlaylist.
 //
 // [replaces all the items in a playlist]: https://developer.spotify.com/documentation/web-api/reference/reorder-or-replace-playlists-tracks
-func (c *Client) ReplacePlaylistItems(ctx context.Context, playlistID ID, items ...URI) (string, error) {
+func (c *Client) ReplacePlaylistItems(ctx context.Context, playlistID ID, items ...URI) (SnapshotID, error) {
 	m := make(map[string]interface{})
 	m["uris"] = items
 
@@ -574,7 +1017,10 @@ func (c *Client) ReplacePlaylistItems(ctx context.Context, playlistID ID, items
 		return "", err
 	}
 
-	spotifyURL := fmt.Sprintf("%splaylists/%s/tracks", c.baseURL, playlistID)
+	spotifyURL, err := c.playlistURL(nil, playlistID, "/tracks")
+	if err != nil {
+		return "", err
+	}
 	req, err := http.NewRequestWithContext(ctx, "PUT", spotifyURL, bytes.NewReader(body))
 	if err != nil {
 		return "", err
@@ -582,7 +1028,7 @@ func (c *Client) ReplacePlaylistItems(ctx context.Context, playlistID ID, items
 	req.Header.Set("Content-Type", "application/json")
 
 	result := struct {
-		SnapshotID string `json:"snapshot_id"`
+		SnapshotID SnapshotID `json:"snapshot_id"`
 	}{}
 
 	err = c.execute(req, &result, http.StatusCreated)
@@ -602,12 +1048,14 @@ func (c *Client) ReplacePlaylistItems(ctx context.Context, playlistID ID, items
 //
 // [checks if one or more (up to 5) users are following]: https://developer.spotify.com/documentation/web-api/reference/check-if-user-follows-playlist
 func (c *Client) UserFollowsPlaylist(ctx context.Context, playlistID ID, userIDs ...string) ([]bool, error) {
-	spotifyURL := fmt.Sprintf("%splaylists/%s/followers/contains?ids=%s",
-		c.baseURL, playlistID, strings.Join(userIDs, ","))
+	spotifyURL, err := c.playlistURL(url.Values{"ids": {strings.Join(userIDs, ",")}}, playlistID, "/followers/contains")
+	if err != nil {
+		return nil, err
+	}
 
 	follows := make([]bool, len(userIDs))
 
-	err := c.get(ctx, spotifyURL, &follows)
+	err = c.get(ctx, spotifyURL, &follows)
 	if err != nil {
 		return nil, err
 	}
@@ -639,7 +1087,7 @@ type PlaylistReorderOptions struct {
 	InsertBefore Numeric `json:"insert_before"`
 	// The playlist's snapshot ID against which you wish to make the changes.
 	// This field is optional.
-	SnapshotID string `json:"snapshot_id,omitempty"`
+	SnapshotID SnapshotID `json:"snapshot_id,omitempty"`
 }
 
 // ReorderPlaylistTracks reorders a track or group of tracks in a playlist.  It
@@ -652,8 +1100,11 @@ type PlaylistReorderOptions struct {
 // Reordering tracks in the current user's public playlist requires [ScopePlaylistModifyPublic].
 // Reordering tracks in the user's private playlists (including collaborative playlists) requires
 // [ScopePlaylistModifyPrivate].
-func (c *Client) ReorderPlaylistTracks(ctx context.Context, playlistID ID, opt PlaylistReorderOptions) (snapshotID string, err error) {
-	spotifyURL := fmt.Sprintf("%splaylists/%s/tracks", c.baseURL, playlistID)
+func (c *Client) ReorderPlaylistTracks(ctx context.Context, playlistID ID, opt PlaylistReorderOptions) (snapshotID SnapshotID, err error) {
+	spotifyURL, err := c.playlistURL(nil, playlistID, "/tracks")
+	if err != nil {
+		return "", err
+	}
 	j, err := json.Marshal(opt)
 	if err != nil {
 		return "", err
@@ -665,7 +1116,7 @@ func (c *Client) ReorderPlaylistTracks(ctx context.Context, playlistID ID, opt P
 	req.Header.Set("Content-Type", "application/json")
 
 	result := struct {
-		SnapshotID string `json:"snapshot_id"`
+		SnapshotID SnapshotID `json:"snapshot_id"`
 	}{}
 	err = c.execute(req, &result)
 	if err != nil {
@@ -675,12 +1126,108 @@ func (c *Client) ReorderPlaylistTracks(ctx context.Context, playlistID ID, opt P
 	return result.SnapshotID, nil
 }
 
+// SortPlaylist sorts the items of a playlist according to less, which reports
+// whether item a should come before item b.  The sort is stable.  It returns
+// the snapshot ID of the sorted playlist.
+//
+// Items are moved with [Client.ReorderPlaylistTracks] rather than replaced, so
+// that the dates on which they were added are preserved.  The longest
+// subsequence of items that is already sorted stays where it is, and only the
+// other items are moved.  Consecutive items that are already in the right
+// order are moved together, so sorting a playlist that is nearly sorted only
+// takes a few requests.
+//
+// Sorting the current user's public playlist requires [ScopePlaylistModifyPublic];
+// sorting private playlists requires [ScopePlaylistModifyPrivate].
+func (c *Client) SortPlaylist(ctx context.Context, playlistID ID, less func(a, b PlaylistItem) bool) (snapshotID SnapshotID, err error) {
+	head, err := c.GetPlaylist(ctx, playlistID, Fields("snapshot_id"))
+	if err != nil {
+		return "", err
+	}
+	snapshotID = head.SnapshotID
+
+	items, err := c.GetAllPlaylistItems(ctx, playlistID)
+	if err != nil {
+		return "", err
+	}
+
+	// items are identified by their original position, as a playlist may
+	// contain the same track more than once
+	target := make([]int, len(items))
+	current := make([]int, len(items))
+	for i := range items {
+		target[i] = i
+		current[i] = i
+	}
+	sort.SliceStable(target, func(i, j int) bool {
+		return less(items[target[i]], items[target[j]])
+	})
+
+	// the items whose original positions form the longest increasing
+	// subsequence of target are already in order; every other item is
+	// moved, in order, to just after the item that precedes it in target
+	stay := lis.Longest(target)
+	index := func(item int) int {
+		for i, c := range current {
+			if c == item {
+				return i
+			}
+		}
+		return -1
+	}
+	for i := 0; i < len(target); {
+		if stay[i] {
+			i++
+			continue
+		}
+		j := index(target[i])
+		before := 0
+		if i > 0 {
+			before = index(target[i-1]) + 1
+		}
+		// move as many items as are already in the right order
+		n := 1
+		for i+n < len(target) && !stay[i+n] && j+n < len(current) && current[j+n] == target[i+n] {
+			n++
+		}
+		if j != before {
+			snapshotID, err = c.ReorderPlaylistTracks(ctx, playlistID, PlaylistReorderOptions{
+				RangeStart:   Numeric(j),
+				RangeLength:  Numeric(n),
+				InsertBefore: Numeric(before),
+				SnapshotID:   snapshotID,
+			})
+			if err != nil {
+				return "", err
+			}
+			current = moveRange(current, j, n, before)
+		}
+		i += n
+	}
+
+	return snapshotID, nil
+}
+
+// moveRange returns items with the length items starting at start moved to
+// before the item at index before, as [Client.ReorderPlaylistTracks] does.
+func moveRange(items []int, start, length, before int) []int {
+	moved := append([]int(nil), items[start:start+length]...)
+	rest := append(append([]int(nil), items[:start]...), items[start+length:]...)
+	if before > start {
+		before -= length
+	}
+	return append(rest[:before], append(moved, rest[before:]...)...)
+}
+
+// MaxPlaylistImageSize is the largest base64-encoded image payload, in bytes,
+// that Spotify accepts when setting a playlist image.
+const MaxPlaylistImageSize = 256 * 1024
+
 // SetPlaylistImage replaces the image used to represent a playlist.
 // This action can only be performed by the owner of the playlist,
 // and requires [ScopeImageUpload] as well as [ScopeModifyPlaylistPublic] or
 // [ScopeModifyPlaylistPrivate].
 func (c *Client) SetPlaylistImage(ctx context.Context, playlistID ID, img io.Reader) error {
-	spotifyURL := fmt.Sprintf("%splaylists/%s/images", c.baseURL, playlistID)
 	// data flow:
 	// img (reader) -> copy into base64 encoder (writer) -> pipe (write end)
 	// pipe (read end) -> request body
@@ -692,7 +1239,36 @@ func (c *Client) SetPlaylistImage(ctx context.Context, playlistID ID, img io.Rea
 		_ = w.CloseWithError(err)
 	}()
 
-	req, err := http.NewRequestWithContext(ctx, "PUT", spotifyURL, r)
+	return c.uploadPlaylistImage(ctx, playlistID, r)
+}
+
+// SetPlaylistImageFromImage is like [Client.SetPlaylistImage], but encodes img
+// as a JPEG of the given quality (1 to 100, see [image/jpeg.Options]) before
+// uploading it.  If the encoded payload exceeds [MaxPlaylistImageSize], an
+// error is returned without contacting Spotify; try a lower quality or a
+// smaller image.
+func (c *Client) SetPlaylistImageFromImage(ctx context.Context, playlistID ID, img image.Image, quality int) error {
+	var raw bytes.Buffer
+	err := jpeg.Encode(&raw, img, &jpeg.Options{Quality: quality})
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(raw.Bytes())
+	if len(encoded) > MaxPlaylistImageSize {
+		return fmt.Errorf("spotify: playlist image is %d bytes when base64 encoded, exceeding the limit of %d bytes",
+			len(encoded), MaxPlaylistImageSize)
+	}
+
+	return c.uploadPlaylistImage(ctx, playlistID, strings.NewReader(encoded))
+}
+
+func (c *Client) uploadPlaylistImage(ctx context.Context, playlistID ID, body io.Reader) error {
+	spotifyURL, err := c.playlistURL(nil, playlistID, "/images")
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", spotifyURL, body)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,106 @@
+package spotify
+
+import "context"
+
+// PlaylistDiff is the result of [Client.DiffPlaylists].
+type PlaylistDiff struct {
+	// OnlyInA holds the items of the first playlist that aren't in the
+	// second, in playlist order.
+	OnlyInA []PlaylistItem
+	// OnlyInB holds the items of the second playlist that aren't in the
+	// first, in playlist order.
+	OnlyInB []PlaylistItem
+	// Common holds the items of the first playlist that are also in the
+	// second, in playlist order.
+	Common []PlaylistItem
+}
+
+// DiffPlaylists compares the items of two playlists, for example to check a
+// migration or to clean up duplicate playlists.  Items that aren't available
+// anymore are ignored.
+//
+// Tracks are compared in a [relinking]-aware way: a track matches another if
+// either its own URI or the URI of the track it was relinked from is the
+// same, so the same song is recognised even if Spotify substituted a
+// market-specific version in one of the playlists.  Pass the [Market] option
+// to have relinking applied.
+//
+// A playlist may contain the same item more than once.  Each occurrence is
+// matched at most once, so if a track is in A twice and in B once, one
+// occurrence is common and the other is only in A.
+//
+// Supported options: [Market].
+//
+// [relinking]: https://developer.spotify.com/documentation/general/guides/track-relinking-guide/
+func (c *Client) DiffPlaylists(ctx context.Context, aID, bID ID, opts ...RequestOption) (*PlaylistDiff, error) {
+	a, err := c.GetAllPlaylistItems(ctx, aID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.GetAllPlaylistItems(ctx, bID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return diffPlaylistItems(a, b), nil
+}
+
+// diffPlaylistItems matches each occurrence of an item in a with an
+// unmatched occurrence of the same item in b.
+func diffPlaylistItems(a, b []PlaylistItem) *PlaylistDiff {
+	// positions maps every key of the items of b to their indices
+	positions := make(map[URI][]int, len(b))
+	for i, item := range b {
+		for _, key := range playlistItemKeys(item) {
+			positions[key] = append(positions[key], i)
+		}
+	}
+
+	diff := &PlaylistDiff{}
+	matched := make([]bool, len(b))
+	for _, item := range a {
+		keys := playlistItemKeys(item)
+		if len(keys) == 0 {
+			continue
+		}
+		if matchPlaylistItem(keys, positions, matched) {
+			diff.Common = append(diff.Common, item)
+		} else {
+			diff.OnlyInA = append(diff.OnlyInA, item)
+		}
+	}
+	for i, item := range b {
+		if !matched[i] && len(playlistItemKeys(item)) > 0 {
+			diff.OnlyInB = append(diff.OnlyInB, item)
+		}
+	}
+	return diff
+}
+
+// matchPlaylistItem marks the first unmatched item with one of keys as
+// matched, and reports whether there was one.
+func matchPlaylistItem(keys []URI, positions map[URI][]int, matched []bool) bool {
+	for _, key := range keys {
+		for _, i := range positions[key] {
+			if !matched[i] {
+				matched[i] = true
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// playlistItemKeys returns the URIs that identify the item: for a relinked
+// track, both its own URI and the URI of the track it was relinked from.
+func playlistItemKeys(item PlaylistItem) []URI {
+	uri := item.URI()
+	if uri == "" {
+		return nil
+	}
+	if t := item.Track.Track; t != nil {
+		if canonical := t.CanonicalURI(); canonical != uri {
+			return []URI{canonical, uri}
+		}
+	}
+	return []URI{uri}
+}
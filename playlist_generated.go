@@ -0,0 +1,76 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// GeneratedPlaylistName is the name of a playlist that Spotify generates for
+// each user, for use with [Client.FindUserGeneratedPlaylists].
+type GeneratedPlaylistName string
+
+const (
+	DiscoverWeekly GeneratedPlaylistName = "Discover Weekly"
+	ReleaseRadar   GeneratedPlaylistName = "Release Radar"
+	DailyMix       GeneratedPlaylistName = "Daily Mix"
+	OnRepeat       GeneratedPlaylistName = "On Repeat"
+	RepeatRewind   GeneratedPlaylistName = "Repeat Rewind"
+)
+
+// spotifyUserID is the ID of the user that owns Spotify's playlists.
+const spotifyUserID = "spotify"
+
+// FindUserGeneratedPlaylists looks for the playlists that Spotify generates
+// for the current user, such as [DiscoverWeekly] and [ReleaseRadar], in the
+// user's playlists, since the API no longer gives direct access to them.  It
+// returns the playlists found for each of names, which default to all of the
+// [GeneratedPlaylistName] constants.  The user must follow or have saved a generated playlist for
+// it to be found.
+//
+// A playlist matches a name if its name is the same, ignoring case, or starts
+// with the name followed by a space, so that [DailyMix] finds "Daily Mix 1"
+// to "Daily Mix 6".  Playlists owned by Spotify are listed first, as users'
+// own playlists, such as copies of a Discover Weekly, can have the same name.
+// Names without any match are left out of the result.
+//
+// Every page of the user's playlists is fetched, so this makes a request per
+// 50 playlists.  It requires the [ScopePlaylistReadPrivate] scope to find
+// private playlists.
+func (c *Client) FindUserGeneratedPlaylists(ctx context.Context, names ...GeneratedPlaylistName) (map[GeneratedPlaylistName][]SimplePlaylist, error) {
+	if len(names) == 0 {
+		names = []GeneratedPlaylistName{DiscoverWeekly, ReleaseRadar, DailyMix, OnRepeat, RepeatRewind}
+	}
+
+	found := make(map[GeneratedPlaylistName][]SimplePlaylist)
+	page, err := c.CurrentUsersPlaylists(ctx, Limit(50))
+	for err == nil {
+		for _, p := range page.Playlists {
+			for _, name := range names {
+				if matchesGeneratedName(p.Name, name) {
+					found[name] = append(found[name], p)
+				}
+			}
+		}
+		err = c.NextPage(ctx, page)
+	}
+	if !errors.Is(err, ErrNoMorePages) {
+		return nil, err
+	}
+
+	for _, playlists := range found {
+		sort.SliceStable(playlists, func(i, j int) bool {
+			return playlists[i].Owner.ID == spotifyUserID && playlists[j].Owner.ID != spotifyUserID
+		})
+	}
+	return found, nil
+}
+
+// matchesGeneratedName reports whether a playlist called playlistName is an
+// instance of the generated playlist called name.
+func matchesGeneratedName(playlistName string, generated GeneratedPlaylistName) bool {
+	playlistName = strings.ToLower(strings.TrimSpace(playlistName))
+	name := strings.ToLower(string(generated))
+	return playlistName == name || strings.HasPrefix(playlistName, name+" ")
+}
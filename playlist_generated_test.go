@@ -0,0 +1,50 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFindUserGeneratedPlaylists(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") == "" {
+			fmt.Fprintf(w, `{"total": 5, "next": "%s/me/playlists?offset=3", "items": [
+				{"id": "copy", "name": "discover weekly", "owner": {"id": "someone"}},
+				null,
+				{"id": "mix1", "name": "Daily Mix 1", "owner": {"id": "spotify"}}
+			]}`, server.URL)
+			return
+		}
+		fmt.Fprint(w, `{"total": 5, "items": [
+			{"id": "dw", "name": "Discover Weekly", "owner": {"id": "spotify"}},
+			{"id": "mixtape", "name": "Daily Mixtape", "owner": {"id": "someone"}}
+		]}`)
+	}))
+	defer server.Close()
+
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+	found, err := client.FindUserGeneratedPlaylists(context.Background(), DiscoverWeekly, DailyMix, ReleaseRadar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := func(name GeneratedPlaylistName) string {
+		var ids []ID
+		for _, p := range found[name] {
+			ids = append(ids, p.ID)
+		}
+		return fmt.Sprint(ids)
+	}
+	if got := ids(DiscoverWeekly); got != "[dw copy]" {
+		t.Errorf("Expected Spotify's Discover Weekly first, got %s", got)
+	}
+	if got := ids(DailyMix); got != "[mix1]" {
+		t.Errorf("Expected one daily mix, got %s", got)
+	}
+	if _, ok := found[ReleaseRadar]; ok {
+		t.Error("Expected no Release Radar")
+	}
+}
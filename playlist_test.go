@@ -3,10 +3,17 @@ package spotify
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"image"
+	"image/jpeg"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -570,7 +577,7 @@ func TestClient_ReplacePlaylistItems(t *testing.T) {
 	}
 	type want struct {
 		requestBody string
-		snapshot    string
+		snapshot    SnapshotID
 		err         string
 	}
 	tests := []struct {
@@ -605,7 +612,7 @@ func TestClient_ReplacePlaylistItems(t *testing.T) {
 				items:      []URI{"spotify:track:track1", "spotify:track:track2"},
 			},
 			want: want{
-				err: "Forbidden",
+				err: "Forbidden (PUT /playlists/playlistID/tracks)",
 			},
 		},
 	}
@@ -713,7 +720,7 @@ func TestReorderPlaylistRequest(t *testing.T) {
 		RangeStart:   3,
 		InsertBefore: 8,
 	})
-	if want := "Not Found"; err == nil || err.Error() != want {
+	if want := "Not Found (PUT /playlists/playlist/tracks)"; err == nil || err.Error() != want {
 		t.Errorf("Expected error: want %v, got %v", want, err)
 	}
 }
@@ -742,3 +749,465 @@ func TestSetPlaylistImage(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestSetPlaylistImageFromImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+
+	client, server := testClientString(http.StatusAccepted, "", func(req *http.Request) {
+		if ct := req.Header.Get("Content-Type"); ct != "image/jpeg" {
+			t.Errorf("wrong content type, got %s, want image/jpeg", ct)
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := base64.StdEncoding.DecodeString(string(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+			t.Errorf("invalid JPEG: %v", err)
+		}
+	})
+	defer server.Close()
+
+	err := client.SetPlaylistImageFromImage(context.Background(), "playlist", img, 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetPlaylistImageFromImageTooLarge(t *testing.T) {
+	// random noise doesn't compress well, so this is well over the limit
+	img := image.NewGray(image.Rect(0, 0, 1024, 1024))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7919 % 251)
+	}
+
+	client, server := testClientString(http.StatusAccepted, "", func(req *http.Request) {
+		t.Error("request should not have been sent")
+	})
+	defer server.Close()
+
+	err := client.SetPlaylistImageFromImage(context.Background(), "playlist", img, 100)
+	if err == nil || !strings.Contains(err.Error(), "exceeding the limit") {
+		t.Errorf("expected size error, got %v", err)
+	}
+}
+
+func TestGetAllPlaylistItemsCache(t *testing.T) {
+	itemRequests := 0
+	data, err := os.ReadFile("test_data/playlist_items_episodes_and_tracks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/playlists/playlist" {
+			if r.URL.Query().Get("fields") != "snapshot_id" {
+				t.Error("Expected only the snapshot ID to be requested")
+			}
+			_, _ = io.WriteString(w, `{"snapshot_id": "abc"}`)
+			return
+		}
+		itemRequests++
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithPlaylistCache(cache, time.Hour))
+
+	first, err := client.GetAllPlaylistItems(context.Background(), "playlist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.GetAllPlaylistItems(context.Background(), "playlist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if itemRequests != 1 {
+		t.Errorf("Expected items to be requested once, got %d", itemRequests)
+	}
+	if len(first) == 0 || !reflect.DeepEqual(first, second) {
+		t.Error("Expected the cached items to match the original ones")
+	}
+
+	// the market of the token depends on the user
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetAllPlaylistItems(context.Background(), "playlist", TokenMarket()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if itemRequests != 3 {
+		t.Errorf("Expected items requested with TokenMarket not to be cached, got %d requests", itemRequests)
+	}
+
+	// playlists aren't stored in the shared cache
+	itemRequests = 0
+	client = New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithCache(cache, time.Hour))
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetAllPlaylistItems(context.Background(), "playlist"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if itemRequests != 2 {
+		t.Errorf("Expected WithCache not to cache playlist items, got %d requests", itemRequests)
+	}
+}
+
+func TestPlaylistContainsTracks(t *testing.T) {
+	pages := []string{
+		`{"items": [{"track": {"id": "a"}}, {"track": null}, {"track": {"id": "b", "linked_from": {"id": "c"}}}], "next": "SERVER/next"}`,
+		`{"items": [{"track": {"id": "d"}}], "next": null}`,
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests == 0 && r.URL.Query().Get("fields") == "" {
+			t.Error("Expected a fields filter")
+		}
+		_, _ = io.WriteString(w, strings.Replace(pages[requests], "SERVER", "http://"+r.Host, 1))
+		requests++
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	contains, err := client.PlaylistContainsTracks(context.Background(), "playlist", "c", "x", "d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []bool{true, false, true}; !reflect.DeepEqual(contains, want) {
+		t.Errorf("Expected %v, got %v", want, contains)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+}
+
+func TestSortPlaylist(t *testing.T) {
+	tests := []struct {
+		tracks   string
+		reorders int
+	}{
+		{tracks: "abcde", reorders: 0},
+		{tracks: "dabce", reorders: 1},
+		{tracks: "deabc", reorders: 1},
+		// a greedy sort would move a, then b and then c
+		{tracks: "dacb", reorders: 2},
+		{tracks: "edcba", reorders: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tracks, func(t *testing.T) {
+			tracks := strings.Split(tt.tracks, "")
+			reorders := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/playlists/p" && r.Method == http.MethodGet:
+					_, _ = io.WriteString(w, `{"snapshot_id": "s0"}`)
+				case r.Method == http.MethodGet:
+					var items []string
+					for _, id := range tracks {
+						items = append(items, fmt.Sprintf(`{"track": {"id": %q, "type": "track"}}`, id))
+					}
+					fmt.Fprintf(w, `{"items": [%s], "next": null}`, strings.Join(items, ","))
+				case r.Method == http.MethodPut:
+					var opt PlaylistReorderOptions
+					if err := json.NewDecoder(r.Body).Decode(&opt); err != nil {
+						t.Error(err)
+						return
+					}
+					if want := SnapshotID(fmt.Sprintf("s%d", reorders)); opt.SnapshotID != want {
+						t.Errorf("Expected snapshot %s, got %s", want, opt.SnapshotID)
+					}
+					reorders++
+					start, length, before := int(opt.RangeStart), int(opt.RangeLength), int(opt.InsertBefore)
+					moved := append([]string(nil), tracks[start:start+length]...)
+					rest := append(append([]string(nil), tracks[:start]...), tracks[start+length:]...)
+					if before > start {
+						before -= length
+					}
+					tracks = append(rest[:before], append(moved, rest[before:]...)...)
+					fmt.Fprintf(w, `{"snapshot_id": "s%d"}`, reorders)
+				}
+			}))
+			defer server.Close()
+			client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+			snapshot, err := client.SortPlaylist(context.Background(), "p", func(a, b PlaylistItem) bool {
+				return a.Track.Track.ID < b.Track.Track.ID
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(tracks, ""); got != "abcde"[:len(tracks)] {
+				t.Errorf("Expected sorted playlist, got %s", got)
+			}
+			if want := SnapshotID(fmt.Sprintf("s%d", tt.reorders)); reorders != tt.reorders || snapshot != want {
+				t.Errorf("Expected %d reorders, got %d (snapshot %s)", tt.reorders, reorders, snapshot)
+			}
+		})
+	}
+}
+
+func TestMergePlaylists(t *testing.T) {
+	playlists := map[string][]string{
+		"target": {"a"},
+		"one":    {"a", "b", "b"},
+		"two":    {"c", "b"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.Split(r.URL.Path, "/")[2]
+		if r.Method == http.MethodPost {
+			var body struct {
+				URIs []string `json:"uris"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			for _, uri := range body.URIs {
+				playlists[id] = append(playlists[id], strings.TrimPrefix(uri, "spotify:track:"))
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"snapshot_id": "s"}`)
+			return
+		}
+		var items []string
+		for _, track := range playlists[id] {
+			items = append(items, fmt.Sprintf(`{"track": {"id": %q, "uri": "spotify:track:%s", "type": "track"}}`, track, track))
+		}
+		fmt.Fprintf(w, `{"items": [%s], "next": null}`, strings.Join(items, ","))
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	counts, err := client.MergePlaylists(context.Background(), "target", []ID{"one", "two"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(counts, []int{1, 1}) {
+		t.Errorf("Unexpected counts %v", counts)
+	}
+	if got := strings.Join(playlists["target"], ""); got != "abc" {
+		t.Errorf("Expected merged playlist abc, got %s", got)
+	}
+}
+
+func TestChangePlaylistDetails(t *testing.T) {
+	client, server := testClientString(http.StatusOK, "", func(r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"public":false,"collaborative":true,"description":""}`; string(body) != want {
+			t.Errorf("Expected body %s, got %s", want, body)
+		}
+	})
+	defer server.Close()
+
+	public, collaborative, description := false, true, ""
+	err := client.ChangePlaylistDetails(context.Background(), "playlist-id", PlaylistDetails{
+		Public:        &public,
+		Collaborative: &collaborative,
+		Description:   &description,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	public = true
+	err = client.ChangePlaylistDetails(context.Background(), "playlist-id", PlaylistDetails{
+		Public:        &public,
+		Collaborative: &collaborative,
+	})
+	if err == nil {
+		t.Error("Expected an error for a public collaborative playlist")
+	}
+}
+
+func TestCreatePlaylistForCurrentUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/me":
+			_, _ = io.WriteString(w, `{"id": "current-user"}`)
+		case "/users/current-user/playlists":
+			if r.Method != http.MethodPost {
+				t.Errorf("Expected POST, got %s", r.Method)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"id": "new-playlist", "name": "A New Playlist"}`)
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	p, err := client.CreatePlaylist(context.Background(), "A New Playlist", "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != "new-playlist" {
+		t.Errorf("Unexpected playlist %s", p.ID)
+	}
+}
+
+func TestGetPlaylistItemsLocalFile(t *testing.T) {
+	const body = `{
+		"href": "https://api.spotify.com/v1/playlists/playlist/tracks",
+		"items": [{
+			"added_at": "2023-01-02T15:04:05Z",
+			"is_local": true,
+			"track": {
+				"album": {"album_type": null, "artists": [], "id": null, "images": [], "name": "Demo Album", "release_date": null, "type": "album", "uri": null},
+				"artists": [{"href": null, "id": null, "name": "Some Band", "type": "artist", "uri": null}],
+				"available_markets": [],
+				"duration_ms": 183000,
+				"external_ids": {},
+				"external_urls": {},
+				"href": null,
+				"id": null,
+				"is_local": true,
+				"name": "Garage Take",
+				"popularity": 0,
+				"preview_url": null,
+				"type": "track",
+				"uri": "spotify:local:Some+Band:Demo+Album:Garage+Take:183"
+			}
+		}],
+		"limit": 100,
+		"offset": 0,
+		"total": 1
+	}`
+	client, server := testClientString(http.StatusOK, body)
+	defer server.Close()
+
+	items, err := client.GetPlaylistItems(context.Background(), "playlist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	track := items.Items[0].Track
+	if track.Track != nil {
+		t.Error("Expected local file not to be decoded as a catalog track")
+	}
+	if track.Local == nil {
+		t.Fatal("Expected local file")
+	}
+	if track.Local.Name != "Garage Take" || track.Local.Album.Name != "Demo Album" || track.Local.Artists[0].Name != "Some Band" {
+		t.Errorf("Unexpected local file metadata: %+v", track.Local)
+	}
+	if d := track.Local.TimeDuration(); d != 183*time.Second {
+		t.Errorf("Expected duration of 3m3s, got %s", d)
+	}
+
+	// local files must survive being cached
+	data, err := json.Marshal(track)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded PlaylistItemTrack
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Local == nil || decoded.Local.URI != track.Local.URI {
+		t.Errorf("Local file didn't round trip: %s", data)
+	}
+}
+
+func TestDiffPlaylists(t *testing.T) {
+	items := map[string]string{
+		"/playlists/a/tracks": `{"items": [
+			{"track": {"id": "1", "uri": "spotify:track:1", "type": "track"}},
+			{"track": {"id": "2", "uri": "spotify:track:2", "type": "track"}},
+			{"track": {"id": "2", "uri": "spotify:track:2", "type": "track"}},
+			{"track": {"id": "3x", "uri": "spotify:track:3x", "type": "track", "linked_from": {"id": "3", "uri": "spotify:track:3"}}},
+			{"track": null}
+		], "next": null}`,
+		"/playlists/b/tracks": `{"items": [
+			{"track": {"id": "3", "uri": "spotify:track:3", "type": "track"}},
+			{"track": {"id": "2", "uri": "spotify:track:2", "type": "track"}},
+			{"track": {"id": "4", "uri": "spotify:track:4", "type": "track"}}
+		], "next": null}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, items[r.URL.Path])
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	diff, err := client.DiffPlaylists(context.Background(), "a", "b", Market("SE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := func(items []PlaylistItem) string {
+		var s []string
+		for _, item := range items {
+			s = append(s, string(item.Track.Track.ID))
+		}
+		return strings.Join(s, ",")
+	}
+	if got := ids(diff.OnlyInA); got != "1,2" {
+		t.Errorf("Expected 1,2 only in A, got %s", got)
+	}
+	if got := ids(diff.OnlyInB); got != "4" {
+		t.Errorf("Expected 4 only in B, got %s", got)
+	}
+	if got := ids(diff.Common); got != "2,3x" {
+		t.Errorf("Expected 2,3x in common, got %s", got)
+	}
+}
+
+func TestFeaturedPlaylistsSearchFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/browse/featured-playlists" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error": {"status": 403, "message": "Forbidden"}}`)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("market") != "SE" || q.Get("limit") != "3" {
+			t.Errorf("Unexpected search %s", r.URL.RawQuery)
+		}
+		// every search returns a shared playlist first, then its own
+		query := strings.ReplaceAll(q.Get("q"), " ", "-")
+		fmt.Fprintf(w, `{"playlists": {"items": [{"id": "shared"}, {"id": %q}, null]}}`, query)
+	}))
+	defer server.Close()
+
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+	if _, _, err := client.FeaturedPlaylists(context.Background(), Country("SE")); err == nil {
+		t.Error("Expected an error without the fallback")
+	}
+
+	msg, page, err := client.FeaturedPlaylists(context.Background(), Country("SE"), Limit(3), SearchFallback())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []ID
+	for _, p := range page.Playlists {
+		ids = append(ids, p.ID)
+	}
+	if msg != "" || !page.SearchFallback || fmt.Sprint(ids) != "[shared new-music chill]" {
+		t.Errorf("Unexpected fallback %q %v", msg, ids)
+	}
+}
+
+func TestPlaylistItemURI(t *testing.T) {
+	items := []struct {
+		item PlaylistItem
+		want URI
+	}{
+		{PlaylistItem{Track: PlaylistItemTrack{Track: &FullTrack{SimpleTrack: SimpleTrack{URI: "spotify:track:1"}}}}, "spotify:track:1"},
+		{PlaylistItem{Track: PlaylistItemTrack{Episode: &EpisodePage{URI: "spotify:episode:1"}}}, "spotify:episode:1"},
+		{PlaylistItem{IsLocal: true, Track: PlaylistItemTrack{Local: &LocalTrack{URI: "spotify:local:a:b:c:1"}}}, "spotify:local:a:b:c:1"},
+		{PlaylistItem{}, ""},
+	}
+	for _, tt := range items {
+		if got := tt.item.URI(); got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package spotify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity above which buffers aren't returned to the
+// pool, so that one very large response doesn't stay in memory for good.
+const maxPooledBuffer = 1 << 20
+
+// bufferPool holds the buffers that response bodies are read into.
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// decodeJSON decodes the JSON in r into result.  The body is read into a
+// pooled buffer and unmarshaled in one go, which allocates much less than a
+// [json.Decoder] per response.  [json.Unmarshal] copies everything it keeps,
+// including [json.RawMessage] values, so the buffer can be reused safely.
+//
+// Like a json.Decoder, decodeJSON ignores any data after the first JSON
+// value.  json.Unmarshal rejects it without modifying result, so such bodies
+// are decoded again with a json.Decoder.
+func decodeJSON(r io.Reader, result interface{}) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if _, err := buf.ReadFrom(r); err != nil {
+		return err
+	}
+	err := json.Unmarshal(buf.Bytes(), result)
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return json.NewDecoder(bytes.NewReader(buf.Bytes())).Decode(result)
+	}
+	return err
+}
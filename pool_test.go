@@ -0,0 +1,92 @@
+package spotify
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSONReusesBuffers(t *testing.T) {
+	var first, second json.RawMessage
+	if err := decodeJSON(strings.NewReader(`{"id":"first"}`), &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := decodeJSON(strings.NewReader(`{"id":"later"}`), &second); err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != `{"id":"first"}` {
+		t.Errorf("First result was overwritten: %s", first)
+	}
+}
+
+func TestDecodeJSONIgnoresTrailingData(t *testing.T) {
+	var album FullAlbum
+	f, err := os.Open("test_data/find_album.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	// the fixture holds the album twice
+	if err := decodeJSON(f, &album); err != nil {
+		t.Fatal(err)
+	}
+	if album.ID != "0sNOF9WDwhWunNAHPD3Baj" {
+		t.Errorf("Expected the first album, got %q", album.ID)
+	}
+
+	var v struct{ ID string }
+	if err := decodeJSON(strings.NewReader(`{"ID": "a"}{`), &v); err != nil || v.ID != "a" {
+		t.Errorf("Expected the first value, got %+v, %v", v, err)
+	}
+	if err := decodeJSON(strings.NewReader(`{"ID": `), &v); err == nil {
+		t.Error("Expected an error for truncated JSON")
+	}
+}
+
+func BenchmarkDecodeJSON(b *testing.B) {
+	var data json.RawMessage
+	f, err := os.Open("test_data/find_album.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	// only benchmark the first album in the fixture
+	if err := json.NewDecoder(f).Decode(&data); err != nil {
+		b.Fatal(err)
+	}
+	b.Run("Decoder", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var album FullAlbum
+			if err := json.NewDecoder(bytes.NewReader(data)).Decode(&album); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var album FullAlbum
+			if err := decodeJSON(bytes.NewReader(data), &album); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkDecodeError(b *testing.B) {
+	body := `{"error": {"status": 404, "message": "Non existing id"}}`
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		resp := &http.Response{
+			StatusCode: http.StatusNotFound,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+		_ = decodeError(resp)
+	}
+}
@@ -0,0 +1,100 @@
+package spotify
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// prefetchTTL is how long a prefetched page is kept if it isn't requested.
+const prefetchTTL = time.Minute
+
+// WithPagePrefetch configures the client to fetch the next page of a paged
+// response in the background as soon as a page is received.  The next call to
+// [Client.NextPage] for that page is then served from memory, or waits for the
+// request that is already in flight, instead of starting a new one.
+//
+// Prefetching trades an extra request per listing, when the last page fetched
+// is never followed, for lower latency when walking large listings.  If a
+// prefetch fails, NextPage requests the page again.  Pages that aren't
+// requested within a minute are discarded.
+func WithPagePrefetch() ClientOption {
+	return func(client *Client) {
+		client.prefetcher = &prefetcher{pages: make(map[string]*prefetchedPage)}
+	}
+}
+
+// prefetcher holds the pages fetched in the background.
+type prefetcher struct {
+	mu    sync.Mutex
+	pages map[string]*prefetchedPage
+}
+
+type prefetchedPage struct {
+	done    chan struct{}
+	data    json.RawMessage
+	err     error
+	started time.Time
+}
+
+// prefetchNext starts fetching the page after result in the background, if
+// result is a page and prefetching is enabled.
+func (c *Client) prefetchNext(ctx context.Context, result interface{}) {
+	if c.prefetcher == nil {
+		return
+	}
+	p, ok := result.(pageable)
+	if !ok || p.page() == nil {
+		return
+	}
+	next := p.page().Next
+	if next == "" {
+		return
+	}
+
+	pf := c.prefetcher
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	for url, page := range pf.pages {
+		if time.Since(page.started) > prefetchTTL {
+			delete(pf.pages, url)
+		}
+	}
+	if _, ok := pf.pages[next]; ok {
+		return
+	}
+	page := &prefetchedPage{done: make(chan struct{}), started: time.Now()}
+	pf.pages[next] = page
+	// the next page is fetched the way NextPage would fetch it, without the
+	// headers added to this request with WithHeader
+	ctx = withoutHeaders(ctx)
+	go func() {
+		defer close(page.done)
+		page.err = c.get(ctx, next, &page.data)
+	}()
+}
+
+// takePrefetched returns the prefetched response for url, waiting for it if it
+// is still in flight.  The boolean result is false if the page wasn't
+// prefetched, or if prefetching it failed.
+func (c *Client) takePrefetched(ctx context.Context, url string) (json.RawMessage, bool) {
+	if c.prefetcher == nil {
+		return nil, false
+	}
+	pf := c.prefetcher
+	pf.mu.Lock()
+	page, ok := pf.pages[url]
+	delete(pf.pages, url)
+	pf.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	select {
+	case <-page.done:
+	case <-ctx.Done():
+		return nil, false
+	}
+	return page.data, page.err == nil
+}
@@ -0,0 +1,167 @@
+package spotify
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultQuotaWindow is the length of the rolling window over which Spotify
+// counts the requests made by an app.
+const DefaultQuotaWindow = 30 * time.Second
+
+// Quota tracks the requests made by several clients of the same app, such as
+// the clients of different users, against a budget of requests per rolling
+// window, so that they stay under the app's rate limit together.  Clients are
+// configured to use it with [WithQuota].
+//
+// When the budget is used up, requests wait for a slot.  Slots are granted to
+// the waiting users in turn, so that a user making many requests, for example
+// while syncing a large library, doesn't starve the others.
+//
+// Spotify doesn't publish its rate limits, so the budget has to be tuned for
+// each app.  A Quota is safe for concurrent use, and should be created with
+// [NewQuota].
+type Quota struct {
+	limit  int
+	window time.Duration
+
+	mu   sync.Mutex
+	sent []time.Time
+	// waiting holds the requests waiting for a slot, by user, and order the
+	// users with waiting requests, in the order they are served.
+	waiting map[string][]chan struct{}
+	order   []string
+	timer   *time.Timer
+}
+
+// NewQuota creates a quota of limit requests per window.  If window is 0,
+// [DefaultQuotaWindow] is used.  A limit below 1 is treated as 1, as no
+// request could ever be made otherwise.
+func NewQuota(limit int, window time.Duration) *Quota {
+	if window == 0 {
+		window = DefaultQuotaWindow
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	return &Quota{
+		limit:   limit,
+		window:  window,
+		waiting: make(map[string][]chan struct{}),
+	}
+}
+
+// WithQuota configures the client to count its requests against q, on behalf
+// of user, which is usually the ID of the user whose token the client uses.
+// Clients with the same user share their turn when requests are waiting.
+func WithQuota(q *Quota, user string) ClientOption {
+	return func(client *Client) {
+		client.quota = q
+		client.quotaUser = user
+	}
+}
+
+// Used returns the number of requests made in the current window.
+func (q *Quota) Used() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.prune(time.Now())
+	return len(q.sent)
+}
+
+// acquire blocks until a request can be made on behalf of user, or ctx is
+// done.
+func (q *Quota) acquire(ctx context.Context, user string) error {
+	q.mu.Lock()
+	now := time.Now()
+	q.prune(now)
+	if len(q.order) == 0 && len(q.sent) < q.limit {
+		q.sent = append(q.sent, now)
+		q.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	if len(q.waiting[user]) == 0 {
+		q.order = append(q.order, user)
+	}
+	q.waiting[user] = append(q.waiting[user], ready)
+	q.schedule(now)
+	q.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, ch := range q.waiting[user] {
+		if ch == ready {
+			q.remove(user, i)
+			return ctx.Err()
+		}
+	}
+	// the slot was granted while ctx was being cancelled
+	return nil
+}
+
+// prune forgets the requests that are outside the window ending at now.
+func (q *Quota) prune(now time.Time) {
+	n := 0
+	for n < len(q.sent) && now.Sub(q.sent[n]) >= q.window {
+		n++
+	}
+	q.sent = q.sent[n:]
+}
+
+// dispatch grants the free slots to the waiting requests, taking turns
+// between users.
+func (q *Quota) dispatch() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.timer = nil
+	now := time.Now()
+	q.prune(now)
+	for len(q.order) > 0 && len(q.sent) < q.limit {
+		user := q.order[0]
+		close(q.waiting[user][0])
+		q.sent = append(q.sent, now)
+		q.order = q.order[1:]
+		q.remove(user, 0)
+		if len(q.waiting[user]) > 0 {
+			q.order = append(q.order, user)
+		}
+	}
+	q.schedule(now)
+}
+
+// remove removes the i-th waiting request of user.
+func (q *Quota) remove(user string, i int) {
+	waiting := append(q.waiting[user][:i:i], q.waiting[user][i+1:]...)
+	if len(waiting) > 0 {
+		q.waiting[user] = waiting
+		return
+	}
+	delete(q.waiting, user)
+	for j, u := range q.order {
+		if u == user {
+			q.order = append(q.order[:j:j], q.order[j+1:]...)
+			break
+		}
+	}
+}
+
+// schedule makes sure that dispatch runs when the oldest request leaves the
+// window, if requests are waiting.
+func (q *Quota) schedule(now time.Time) {
+	if len(q.order) == 0 || q.timer != nil {
+		return
+	}
+	var wait time.Duration
+	if len(q.sent) > 0 {
+		wait = q.sent[0].Add(q.window).Sub(now)
+	}
+	q.timer = time.AfterFunc(wait, q.dispatch)
+}
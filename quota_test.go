@@ -0,0 +1,102 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// waitingRequests returns the number of requests waiting for a slot.
+func (q *Quota) waitingRequests() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, w := range q.waiting {
+		n += len(w)
+	}
+	return n
+}
+
+func TestQuotaTakesTurns(t *testing.T) {
+	q := NewQuota(1, 20*time.Millisecond)
+	ctx := context.Background()
+	if err := q.acquire(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	granted := make(chan string, 3)
+	enqueue := func(user string) {
+		n := q.waitingRequests()
+		go func() {
+			if err := q.acquire(ctx, user); err == nil {
+				granted <- user
+			}
+		}()
+		for q.waitingRequests() == n {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	enqueue("a")
+	enqueue("a")
+	enqueue("b")
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		got = append(got, <-granted)
+	}
+	if fmt.Sprint(got) != "[a b a]" {
+		t.Errorf("Expected users to take turns, got %v", got)
+	}
+}
+
+func TestQuotaCancel(t *testing.T) {
+	q := NewQuota(1, time.Hour)
+	if err := q.acquire(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.acquire(ctx, "a"); err != context.DeadlineExceeded {
+		t.Errorf("Expected the deadline to be exceeded, got %v", err)
+	}
+	if n := q.waitingRequests(); n != 0 {
+		t.Errorf("Expected no waiting requests, got %d", n)
+	}
+}
+
+func TestQuotaInvalidLimit(t *testing.T) {
+	for _, limit := range []int{0, -1} {
+		q := NewQuota(limit, time.Hour)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err := q.acquire(ctx, "a")
+		cancel()
+		if err != nil {
+			t.Errorf("Expected a limit of %d to allow one request, got %v", limit, err)
+		}
+		if q.Used() != 1 {
+			t.Errorf("Expected 1 request to be counted, got %d", q.Used())
+		}
+	}
+}
+
+func TestWithQuota(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "track"}`)
+	}))
+	defer server.Close()
+
+	q := NewQuota(10, 0)
+	alice := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithQuota(q, "alice"))
+	bob := alice.With(WithQuota(q, "bob"))
+	for _, client := range []*Client{alice, bob} {
+		if _, err := client.GetTrack(context.Background(), "4uLU6hMCjMI75M1A2tKUQC"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if used := q.Used(); used != 2 {
+		t.Errorf("Expected 2 requests to be counted, got %d", used)
+	}
+}
@@ -2,6 +2,7 @@ package spotify
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -41,6 +42,45 @@ type RecommendationSeed struct {
 // MaxNumberOfSeeds allowed by Spotify for a recommendation request.
 const MaxNumberOfSeeds = 5
 
+// Validate checks that s can be used for a recommendation request: it must
+// contain between one and [MaxNumberOfSeeds] seeds in total, and none of them
+// may be empty or repeated.  Spotify doesn't always report these problems
+// clearly, so [Client.GetRecommendations] validates the seeds before sending
+// the request.
+func (s Seeds) Validate() error {
+	switch n := s.count(); {
+	case n == 0:
+		return errors.New("spotify: at least one seed is required")
+	case n > MaxNumberOfSeeds:
+		return fmt.Errorf("spotify: exceeded maximum of %d seeds: got %d artists, %d tracks and %d genres",
+			MaxNumberOfSeeds, len(s.Artists), len(s.Tracks), len(s.Genres))
+	}
+	if err := validateSeeds("artist", toStringSlice(s.Artists)); err != nil {
+		return err
+	}
+	if err := validateSeeds("track", toStringSlice(s.Tracks)); err != nil {
+		return err
+	}
+	return validateSeeds("genre", s.Genres)
+}
+
+func validateSeeds(kind string, seeds []string) error {
+	seen := make(map[string]bool, len(seeds))
+	for _, seed := range seeds {
+		if seed == "" {
+			return fmt.Errorf("spotify: empty %s seed", kind)
+		}
+		if strings.Contains(seed, ",") {
+			return fmt.Errorf("spotify: invalid %s seed %q", kind, seed)
+		}
+		if seen[seed] {
+			return fmt.Errorf("spotify: duplicate %s seed %q", kind, seed)
+		}
+		seen[seed] = true
+	}
+	return nil
+}
+
 // setSeedValues sets url values into v for each seed in seeds
 func setSeedValues(seeds Seeds, v url.Values) {
 	if len(seeds.Artists) != 0 {
@@ -79,13 +119,15 @@ func setTrackAttributesValues(trackAttributes *TrackAttributes, values url.Value
 //
 // [list of recommended tracks]: https://developer.spotify.com/documentation/web-api/reference/get-recommendations
 func (c *Client) GetRecommendations(ctx context.Context, seeds Seeds, trackAttributes *TrackAttributes, opts ...RequestOption) (*Recommendations, error) {
-	v := processOptions(opts...).urlParams
-
-	if seeds.count() == 0 {
-		return nil, fmt.Errorf("spotify: at least one seed is required")
+	o, err := c.processPagingOptions(100, opts...)
+	if err != nil {
+		return nil, err
 	}
-	if seeds.count() > MaxNumberOfSeeds {
-		return nil, fmt.Errorf("spotify: exceeded maximum of %d seeds", MaxNumberOfSeeds)
+	ctx = o.context(ctx)
+	v := o.urlParams
+
+	if err := seeds.Validate(); err != nil {
+		return nil, err
 	}
 
 	setSeedValues(seeds, v)
@@ -94,7 +136,7 @@ func (c *Client) GetRecommendations(ctx context.Context, seeds Seeds, trackAttri
 	spotifyURL := c.baseURL + "recommendations?" + v.Encode()
 
 	var recommendations Recommendations
-	err := c.get(ctx, spotifyURL, &recommendations)
+	err = c.get(ctx, spotifyURL, &recommendations)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,319 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RecommendationProvider generates recommendations based on seeds.  [Client]
+// implements it with the Web API's recommendations endpoint, which Spotify
+// has removed for most apps; [LibraryRecommender] implements it client-side.
+// Use [RecommendationsWithFallback] to combine the two.
+type RecommendationProvider interface {
+	GetRecommendations(ctx context.Context, seeds Seeds, trackAttributes *TrackAttributes, opts ...RequestOption) (*Recommendations, error)
+}
+
+var _ RecommendationProvider = (*Client)(nil)
+
+// defaultRecommendationLimit is the number of tracks recommended if the
+// [Limit] option isn't given, matching the Web API.
+const defaultRecommendationLimit = 20
+
+// LibraryRecommender is a [RecommendationProvider] that derives
+// recommendations from endpoints that are still available to all apps.
+// Candidates are gathered from:
+//
+//   - the top tracks of the seed artists, and of the artists of the seed
+//     tracks,
+//   - tracks found by searching for the seed genres,
+//   - the top tracks of the user's top artists, limited to those matching
+//     the seed genres if any are given, and
+//   - the latest releases of the artists that the user follows.
+//
+// The sources are interleaved, so that the recommendations are varied, and
+// the seed tracks themselves are never recommended.  Track attributes can't
+// be applied client-side, and are ignored.
+//
+// The user's top and followed artists require the [ScopeUserTopRead] and
+// [ScopeUserFollowRead] scopes.  If the client wasn't granted them, those
+// sources are skipped.
+//
+// You should always use [NewLibraryRecommender] to make them.
+type LibraryRecommender struct {
+	client *Client
+}
+
+// NewLibraryRecommender creates a recommender that uses client to gather
+// candidates.
+func NewLibraryRecommender(client *Client) *LibraryRecommender {
+	return &LibraryRecommender{client: client}
+}
+
+// recommenderArtists is the number of the user's top and followed artists
+// that candidates are gathered from.
+const recommenderArtists = 5
+
+// GetRecommendations implements [RecommendationProvider].  Unlike the Web
+// API, it can take several requests per seed.
+//
+// Supported options: [Limit], [Country].
+func (r *LibraryRecommender) GetRecommendations(ctx context.Context, seeds Seeds, trackAttributes *TrackAttributes, opts ...RequestOption) (*Recommendations, error) {
+	if err := seeds.Validate(); err != nil {
+		return nil, err
+	}
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	params := o.urlParams
+	limit := defaultRecommendationLimit
+	if raw := params.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 100 {
+			return nil, fmt.Errorf("spotify: limit must be between 1 and 100, got %q", raw)
+		}
+		limit = n
+	}
+	market := params.Get("country")
+	if market == "" {
+		market = MarketFromToken
+	}
+
+	var sources [][]SimpleTrack
+	result := &Recommendations{}
+
+	// the artists of the seed tracks are treated like seed artists
+	artists := append([]ID(nil), seeds.Artists...)
+	if len(seeds.Tracks) > 0 {
+		tracks, err := r.client.GetTracks(ctx, seeds.Tracks, Market(market))
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tracks {
+			if t != nil && len(t.Artists) > 0 {
+				artists = append(artists, t.Artists[0].ID)
+			}
+		}
+	}
+	for _, id := range artists {
+		tracks, err := r.topTracks(ctx, id, market)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, tracks)
+	}
+
+	for _, genre := range seeds.Genres {
+		res, err := r.client.Search(ctx, fmt.Sprintf("genre:%q", genre), SearchTypeTrack, Market(market), Limit(limit))
+		if err != nil {
+			return nil, err
+		}
+		if res.Tracks != nil {
+			sources = append(sources, simpleTracks(res.Tracks.Tracks))
+		}
+	}
+
+	top, err := r.topArtists(ctx, seeds.Genres)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range top {
+		tracks, err := r.topTracks(ctx, id, market)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, tracks)
+	}
+
+	followed, err := r.followedReleases(ctx, market)
+	if err != nil {
+		return nil, err
+	}
+	sources = append(sources, followed...)
+
+	exclude := make(map[ID]bool, len(seeds.Tracks))
+	for _, id := range seeds.Tracks {
+		exclude[id] = true
+	}
+	result.Tracks = interleaveTracks(sources, exclude, limit)
+
+	for _, id := range seeds.Artists {
+		result.Seeds = append(result.Seeds, RecommendationSeed{ID: id, Type: "ARTIST"})
+	}
+	for _, id := range seeds.Tracks {
+		result.Seeds = append(result.Seeds, RecommendationSeed{ID: id, Type: "TRACK"})
+	}
+	for _, genre := range seeds.Genres {
+		result.Seeds = append(result.Seeds, RecommendationSeed{ID: ID(genre), Type: "GENRE"})
+	}
+	return result, nil
+}
+
+func (r *LibraryRecommender) topTracks(ctx context.Context, artist ID, market string) ([]SimpleTrack, error) {
+	tracks, err := r.client.GetArtistsTopTracks(ctx, artist, market)
+	if err != nil {
+		return nil, err
+	}
+	return simpleTracks(tracks), nil
+}
+
+// topArtists returns the IDs of the user's top artists.  If genres isn't
+// empty, only artists associated with one of them are returned.
+func (r *LibraryRecommender) topArtists(ctx context.Context, genres []string) ([]ID, error) {
+	page, err := r.client.CurrentUsersTopArtists(ctx, Limit(50))
+	if errors.Is(err, ErrInsufficientScope) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(genres))
+	for _, g := range genres {
+		wanted[strings.ToLower(g)] = true
+	}
+	var ids []ID
+	for _, a := range page.Artists {
+		if len(ids) == recommenderArtists {
+			break
+		}
+		if len(wanted) > 0 && !hasGenre(a.Genres, wanted) {
+			continue
+		}
+		ids = append(ids, a.ID)
+	}
+	return ids, nil
+}
+
+func hasGenre(genres []string, wanted map[string]bool) bool {
+	for _, g := range genres {
+		if wanted[strings.ToLower(g)] {
+			return true
+		}
+	}
+	return false
+}
+
+// followedReleases returns the tracks of the latest release of each of the
+// artists that the user follows.
+func (r *LibraryRecommender) followedReleases(ctx context.Context, market string) ([][]SimpleTrack, error) {
+	page, err := r.client.CurrentUsersFollowedArtists(ctx, Limit(recommenderArtists))
+	if errors.Is(err, ErrInsufficientScope) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var releases [][]SimpleTrack
+	for _, a := range page.Artists {
+		albums, err := r.client.GetArtistAlbums(ctx, a.ID, IncludeGroups(AlbumTypeAlbum|AlbumTypeSingle), Market(market), Limit(1))
+		if err != nil {
+			return nil, err
+		}
+		if len(albums.Albums) == 0 {
+			continue
+		}
+		tracks, err := r.client.GetAlbumTracks(ctx, albums.Albums[0].ID, Market(market))
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, tracks.Tracks)
+	}
+	return releases, nil
+}
+
+func simpleTracks(tracks []FullTrack) []SimpleTrack {
+	simple := make([]SimpleTrack, len(tracks))
+	for i := range tracks {
+		simple[i] = tracks[i].SimpleTrack
+	}
+	return simple
+}
+
+// interleaveTracks takes one track from each source in turn until limit tracks
+// are collected or the sources are exhausted.  Tracks in exclude, and tracks
+// that were already taken, are skipped.
+func interleaveTracks(sources [][]SimpleTrack, exclude map[ID]bool, limit int) []SimpleTrack {
+	ids := make([][]ID, len(sources))
+	for i, source := range sources {
+		ids[i] = make([]ID, len(source))
+		for j, t := range source {
+			ids[i][j] = t.ID
+		}
+	}
+	var tracks []SimpleTrack
+	for _, p := range interleave(ids, exclude, limit) {
+		tracks = append(tracks, sources[p.source][p.index])
+	}
+	return tracks
+}
+
+// interleaved is the position of an item taken by interleave.
+type interleaved struct {
+	source, index int
+}
+
+// interleave takes one item from each source in turn until limit items are
+// taken or the sources are exhausted.  sources holds the IDs of the items of
+// each source.  Items in exclude, and items that were already taken, are
+// skipped.  It returns the positions of the items taken, in order.
+func interleave(sources [][]ID, exclude map[ID]bool, limit int) []interleaved {
+	seen := make(map[ID]bool, len(exclude))
+	for id := range exclude {
+		seen[id] = true
+	}
+
+	next := make([]int, len(sources))
+	var taken []interleaved
+	for len(taken) < limit {
+		progressed := false
+		for i, source := range sources {
+			for next[i] < len(source) {
+				j := next[i]
+				next[i]++
+				if seen[source[j]] {
+					continue
+				}
+				seen[source[j]] = true
+				taken = append(taken, interleaved{source: i, index: j})
+				progressed = true
+				break
+			}
+			if len(taken) == limit {
+				break
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+	return taken
+}
+
+// RecommendationsWithFallback returns a [RecommendationProvider] that asks
+// primary for recommendations, and falls back to fallback if primary reports
+// that the endpoint is not available, which Spotify signals with a 403 or
+// 404 status.  Other errors are returned as is.
+//
+// For example, to keep using the Web API where it still works:
+//
+//	provider := spotify.RecommendationsWithFallback(client, spotify.NewLibraryRecommender(client))
+func RecommendationsWithFallback(primary, fallback RecommendationProvider) RecommendationProvider {
+	return fallbackRecommender{primary: primary, fallback: fallback}
+}
+
+type fallbackRecommender struct {
+	primary, fallback RecommendationProvider
+}
+
+func (f fallbackRecommender) GetRecommendations(ctx context.Context, seeds Seeds, trackAttributes *TrackAttributes, opts ...RequestOption) (*Recommendations, error) {
+	recs, err := f.primary.GetRecommendations(ctx, seeds, trackAttributes, opts...)
+	var e Error
+	if errors.As(err, &e) && (e.Status == http.StatusForbidden || e.Status == http.StatusNotFound) {
+		return f.fallback.GetRecommendations(ctx, seeds, trackAttributes, opts...)
+	}
+	return recs, err
+}
@@ -2,7 +2,11 @@ package spotify
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -70,3 +74,106 @@ func TestSetEmptyTrackAttributesValues(t *testing.T) {
 		t.Errorf("Expected track attributes values to be empty but got %s", actualValues)
 	}
 }
+
+func TestSeedsValidate(t *testing.T) {
+	tests := []struct {
+		seeds Seeds
+		ok    bool
+	}{
+		{Seeds{Genres: []string{"punk"}}, true},
+		{Seeds{Artists: []ID{"a", "b"}, Tracks: []ID{"c", "d"}, Genres: []string{"punk"}}, true},
+		{Seeds{}, false},
+		{Seeds{Artists: []ID{"a", "b", "c"}, Tracks: []ID{"d", "e", "f"}}, false},
+		{Seeds{Tracks: []ID{"a", "a"}}, false},
+		{Seeds{Genres: []string{""}}, false},
+		{Seeds{Genres: []string{"punk,metal"}}, false},
+	}
+	for _, test := range tests {
+		err := test.seeds.Validate()
+		if test.ok && err != nil {
+			t.Errorf("Unexpected error for %+v: %v", test.seeds, err)
+		}
+		if !test.ok && err == nil {
+			t.Errorf("Expected an error for %+v", test.seeds)
+		}
+	}
+}
+
+func TestGetRecommendationsValidatesSeeds(t *testing.T) {
+	client, server := testClientString(http.StatusOK, "{}", func(r *http.Request) {
+		t.Error("No request should be sent for invalid seeds")
+	})
+	defer server.Close()
+
+	seeds := Seeds{Tracks: []ID{"a", "b", "c", "d", "e", "f"}}
+	if _, err := client.GetRecommendations(context.Background(), seeds, nil); err == nil {
+		t.Error("Expected an error for too many seeds")
+	}
+}
+
+func TestRecommendationsWithFallback(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/recommendations":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"status": 404, "message": "Not Found"}}`)
+		case "/tracks":
+			fmt.Fprint(w, `{"tracks": [{"id": "seed", "artists": [{"id": "artist2"}]}]}`)
+		case "/artists/artist1/top-tracks":
+			fmt.Fprint(w, `{"tracks": [{"id": "a1"}, {"id": "a2"}, {"id": "a3"}]}`)
+		case "/artists/artist2/top-tracks":
+			fmt.Fprint(w, `{"tracks": [{"id": "seed"}, {"id": "a1"}, {"id": "b1"}]}`)
+		case "/me/top/artists":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error": {"status": 403, "message": "Insufficient client scope"}}`)
+		case "/me/following":
+			fmt.Fprint(w, `{"artists": {"items": [{"id": "followed"}]}}`)
+		case "/artists/followed/albums":
+			fmt.Fprint(w, `{"items": [{"id": "latest"}]}`)
+		case "/albums/latest/tracks":
+			fmt.Fprint(w, `{"items": [{"id": "f1"}]}`)
+		default:
+			t.Errorf("Unexpected request for %s", r.URL)
+		}
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	provider := RecommendationsWithFallback(client, NewLibraryRecommender(client))
+	seeds := Seeds{Artists: []ID{"artist1"}, Tracks: []ID{"seed"}}
+	recs, err := provider.GetRecommendations(context.Background(), seeds, nil, Limit(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, track := range recs.Tracks {
+		ids = append(ids, string(track.ID))
+	}
+	// sources are interleaved, duplicates and seed tracks are skipped
+	if got := strings.Join(ids, ","); got != "a1,b1,f1,a2,a3" {
+		t.Errorf("Unexpected recommendations: %s", got)
+	}
+	if len(recs.Seeds) != 2 {
+		t.Errorf("Expected 2 seeds, got %d", len(recs.Seeds))
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	sources := [][]ID{{"a", "b", "c"}, {"a", "d"}, {}, {"x", "e"}}
+	tests := []struct {
+		limit int
+		want  string
+	}{
+		{limit: 0, want: "[]"},
+		{limit: 2, want: "[{0 0} {1 1}]"},
+		{limit: 10, want: "[{0 0} {1 1} {3 1} {0 1} {0 2}]"},
+	}
+	for _, tt := range tests {
+		got := interleave(sources, map[ID]bool{"x": true}, tt.limit)
+		if fmt.Sprint(got) != tt.want {
+			t.Errorf("Expected %s with a limit of %d, got %v", tt.want, tt.limit, got)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package spotify
 
 import (
+	"context"
+	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
@@ -10,6 +13,12 @@ type RequestOption func(*requestOptions)
 
 type requestOptions struct {
 	urlParams url.Values
+	// header holds the headers added with WithHeader.
+	header http.Header
+	// searchFallback is set by SearchFallback.
+	searchFallback bool
+	// err records an invalid option, and is reported by validate.
+	err error
 }
 
 // Limit sets the number of entries that a request should return.
@@ -26,6 +35,19 @@ func Market(code string) RequestOption {
 	}
 }
 
+// TokenMarket is shorthand for Market([MarketFromToken]): it limits results to
+// content that is playable in the country of the user who granted the access
+// token.
+//
+// The token must belong to a user, and must have been granted
+// [ScopeUserReadPrivate].  Tokens obtained with the client credentials flow
+// don't belong to a user, so Spotify rejects these requests, usually with a
+// "400 Invalid market code" error that doesn't mention the token.  With such
+// tokens, pass an explicit country code to [Market] instead.
+func TokenMarket() RequestOption {
+	return Market(MarketFromToken)
+}
+
 // Country enables a specific region to be specified for region-specific suggestions e.g popular playlists
 // The Country option takes an ISO 3166-1 alpha-2 country code.  It can be
 // used to ensure that the category exists for a particular country.
@@ -71,6 +93,17 @@ func After(after string) RequestOption {
 	}
 }
 
+// Before is the cursor that marks the start of the previous request's results,
+// and is used to page backwards through cursor-based results.  For
+// [Client.PlayerRecentlyPlayed], it is a Unix timestamp in milliseconds and
+// only items played before that time are returned.  It can't be combined with
+// [After].
+func Before(before string) RequestOption {
+	return func(o *requestOptions) {
+		o.urlParams.Set("before", before)
+	}
+}
+
 // Fields is a comma-separated list of the fields to return.
 // See the JSON tags on [FullPlaylist] for valid field options.
 // For example, to get just the playlist's description and URI:
@@ -96,6 +129,24 @@ func Fields(fields string) RequestOption {
 	}
 }
 
+// DeviceID sets the device that a player command is sent to.  Without it,
+// commands go to the user's active device.
+func DeviceID(id ID) RequestOption {
+	return func(o *requestOptions) {
+		o.urlParams.Set("device_id", string(id))
+	}
+}
+
+// WithURLParam sets an arbitrary query parameter on the request.  It can be
+// used to pass parameters that Spotify supports but that this package doesn't
+// provide an option for yet.  Parameters that a method sets itself, such as
+// the IDs passed to [Client.GetTracks], take precedence.
+func WithURLParam(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.urlParams.Set(key, value)
+	}
+}
+
 type Range string
 
 const (
@@ -139,6 +190,126 @@ func AdditionalTypes(types ...AdditionalType) RequestOption {
 	}
 }
 
+// IncludeGroups restricts [Client.GetArtistAlbums] to albums of the given
+// types.  The types may be given separately or OR'd together, for example:
+//
+//	IncludeGroups(AlbumTypeAlbum | AlbumTypeSingle)
+//
+// By default, albums of all types are returned.
+func IncludeGroups(types ...AlbumType) RequestOption {
+	var at AlbumType
+	for _, t := range types {
+		at |= t
+	}
+	return func(o *requestOptions) {
+		if at == 0 || at&^allAlbumTypes != 0 {
+			o.err = fmt.Errorf("spotify: invalid album type %d for include groups", at)
+			return
+		}
+		o.urlParams.Set("include_groups", at.encode())
+	}
+}
+
+// WithHeader adds a header to the request, for example to send the extra
+// headers required by a proxy, or to try out Spotify preview features.  The
+// header is added after the ones set by the client, so it can be used to
+// override them.  Requests made with custom headers are not cached.
+//
+// WithHeader applies to the request made by the method it is passed to, and
+// not to later calls to [Client.NextPage] or [Client.PreviousPage], nor to the
+// pages prefetched for them by [WithPagePrefetch].  Methods that make several
+// requests, such as [Client.GetArtistDiscography], add it to all of them.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// SearchFallback makes [Client.GetCategoryPlaylists] and
+// [Client.FeaturedPlaylists] approximate their results by searching for
+// public playlists if Spotify doesn't allow the app to use their endpoints.
+// It has no effect on other methods.
+func SearchFallback() RequestOption {
+	return func(o *requestOptions) {
+		o.searchFallback = true
+	}
+}
+
+type headerKey struct{}
+
+// context returns a context that carries the headers added with WithHeader to
+// the requests made with it.
+func (o requestOptions) context(ctx context.Context) context.Context {
+	if len(o.header) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, headerKey{}, o.header)
+}
+
+// withoutHeaders returns a context that doesn't carry the headers added with
+// WithHeader, if ctx does.
+func withoutHeaders(ctx context.Context) context.Context {
+	if !hasHeaders(ctx) {
+		return ctx
+	}
+	return context.WithValue(ctx, headerKey{}, nil)
+}
+
+// hasHeaders reports whether ctx carries headers added with WithHeader.
+func hasHeaders(ctx context.Context) bool {
+	return ctx.Value(headerKey{}) != nil
+}
+
+// addHeaders adds the headers carried by the request's context to it.
+func addHeaders(req *http.Request) {
+	header, _ := req.Context().Value(headerKey{}).(http.Header)
+	for key, values := range header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+}
+
+// validate checks that the options were given valid values, and that the
+// limit and offset parameters, if present, are within the bounds accepted by an
+// endpoint that returns at most maxLimit items.
+func (o requestOptions) validate(maxLimit int) error {
+	if o.err != nil {
+		return o.err
+	}
+	if raw := o.urlParams.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("spotify: invalid limit %q", raw)
+		}
+		if limit < 1 || limit > maxLimit {
+			return fmt.Errorf("spotify: limit must be between 1 and %d for this endpoint, got %d", maxLimit, limit)
+		}
+	}
+	if raw := o.urlParams.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("spotify: invalid offset %q", raw)
+		}
+		if offset < 0 {
+			return fmt.Errorf("spotify: offset must not be negative, got %d", offset)
+		}
+	}
+	return nil
+}
+
+// processPagingOptions is like processOptions, but also validates the [Limit]
+// and [Offset] options against the bounds of an endpoint that returns at most
+// maxLimit items, unless validation was disabled with [WithOptionValidation].
+func (c *Client) processPagingOptions(maxLimit int, options ...RequestOption) (requestOptions, error) {
+	o := processOptions(options...)
+	if c.skipOptionValidation {
+		return o, nil
+	}
+	return o, o.validate(maxLimit)
+}
+
 func processOptions(options ...RequestOption) requestOptions {
 	o := requestOptions{
 		urlParams: url.Values{},
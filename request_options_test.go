@@ -1,6 +1,9 @@
 package spotify
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -16,11 +19,119 @@ func TestOptions(t *testing.T) {
 		Offset(1),
 		Timerange("long"),
 		Timestamp("2000-11-02T13:37:00"),
+		Before("1484811043508"),
+		WithURLParam("include_external", "audio"),
 	)
 
-	expected := "after=example_id&country=GB&limit=13&locale=en_GB&market=AR&offset=1&time_range=long&timestamp=2000-11-02T13%3A37%3A00"
+	expected := "after=example_id&before=1484811043508&country=GB&include_external=audio&limit=13&locale=en_GB&market=AR&offset=1&time_range=long&timestamp=2000-11-02T13%3A37%3A00"
 	actual := resultSet.urlParams.Encode()
 	if actual != expected {
 		t.Errorf("Expected '%v', got '%v'", expected, actual)
 	}
 }
+
+func TestTokenMarket(t *testing.T) {
+	t.Parallel()
+
+	if got := processOptions(TokenMarket()).urlParams.Encode(); got != "market=from_token" {
+		t.Errorf("Expected market=from_token, got %q", got)
+	}
+}
+
+func TestPagingOptionsValidation(t *testing.T) {
+	t.Parallel()
+
+	client := New(nil)
+	tests := []struct {
+		opts []RequestOption
+		ok   bool
+	}{
+		{[]RequestOption{Limit(50), Offset(0)}, true},
+		{[]RequestOption{Limit(51)}, false},
+		{[]RequestOption{Limit(0)}, false},
+		{[]RequestOption{Offset(-1)}, false},
+		{nil, true},
+	}
+	for _, test := range tests {
+		_, err := client.processPagingOptions(50, test.opts...)
+		if test.ok && err != nil {
+			t.Errorf("Unexpected error for %v: %v", processOptions(test.opts...).urlParams, err)
+		}
+		if !test.ok && err == nil {
+			t.Errorf("Expected an error for %v", processOptions(test.opts...).urlParams)
+		}
+	}
+
+	client = New(nil, WithOptionValidation(false))
+	if _, err := client.processPagingOptions(50, Limit(51)); err != nil {
+		t.Errorf("Expected validation to be disabled, got %v", err)
+	}
+}
+
+func TestSearchRejectsLimit(t *testing.T) {
+	t.Parallel()
+
+	client, server := testClientString(200, "{}")
+	defer server.Close()
+
+	_, err := client.Search(context.Background(), "query", SearchTypeTrack, Limit(100))
+	if err == nil {
+		t.Fatal("Expected an error for an out-of-range limit")
+	}
+}
+
+func TestIncludeGroups(t *testing.T) {
+	t.Parallel()
+
+	o := processOptions(IncludeGroups(AlbumTypeAlbum, AlbumTypeSingle|AlbumTypeCompilation))
+	if got := o.urlParams.Get("include_groups"); got != "album,single,compilation" {
+		t.Errorf("Expected 'album,single,compilation', got '%s'", got)
+	}
+
+	client := New(nil)
+	for _, at := range []AlbumType{0, AlbumTypeAlbum | 1<<7} {
+		if _, err := client.processPagingOptions(50, IncludeGroups(at)); err == nil {
+			t.Errorf("Expected an error for album type %d", at)
+		}
+	}
+}
+
+func TestWithHeader(t *testing.T) {
+	t.Parallel()
+
+	client, server := testClientFile(http.StatusOK, "test_data/find_track.txt", func(r *http.Request) {
+		if got := r.Header.Get("X-Experiment"); got != "preview" {
+			t.Errorf("Expected the custom header, got %q", got)
+		}
+		if got := r.Header.Get("Accept-Language"); got != "de" {
+			t.Errorf("Expected the Accept-Language header to be overridden, got %q", got)
+		}
+	})
+	defer server.Close()
+	client.acceptLanguage = "en"
+
+	_, err := client.GetTrack(context.Background(), "1zHlj4dQ8ZAtrayhuDDmkY", WithHeader("X-Experiment", "preview"), WithHeader("Accept-Language", "de"))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithHeaderFallback(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if got := r.Header.Get("X-Experiment"); got != "preview" {
+			t.Errorf("Expected the custom header on %s, got %q", r.URL, got)
+		}
+		w.Write([]byte(`{"playlists": {"items": []}}`))
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	if _, err := client.GetEditorialPlaylistsFallback(context.Background(), "", WithHeader("X-Experiment", "preview")); err != nil {
+		t.Fatal(err)
+	}
+	if requests != len(editorialQueries) {
+		t.Errorf("Expected %d searches, got %d", len(editorialQueries), requests)
+	}
+}
@@ -0,0 +1,60 @@
+package spotify
+
+import (
+	"net/http"
+	"time"
+)
+
+// WithTransientRetry configures the client to retry GET requests that fail
+// with a 500, 502, 503 or 504 status, or with a network error, which are
+// usually transient.  Each request is attempted at most maxAttempts times.
+// The client waits backoff before the first retry, and twice as long before
+// each following one, up to ten minutes.
+//
+// Only GET requests are retried, as they are idempotent.  Rate-limited
+// requests are retried separately, with [WithRetry].  Requests made with a
+// context returned by [NoRetry] aren't retried.
+func WithTransientRetry(maxAttempts int, backoff time.Duration) ClientOption {
+	return func(client *Client) {
+		client.maxAttempts = maxAttempts
+		client.backoff = backoff
+	}
+}
+
+// isTransientStatus reports whether status indicates a server error that is
+// worth retrying.
+func isTransientStatus(status int) bool {
+	switch status {
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryTransient waits before retrying req, which failed on its attempt-th
+// attempt with status, or with a network error if status is 0.  It reports
+// false if the request shouldn't be retried.
+func (c *Client) retryTransient(req *http.Request, attempt, status int) bool {
+	ctx := req.Context()
+	if attempt >= c.maxAttempts || ctx.Err() != nil || ctx.Value(noRetryKey{}) != nil {
+		return false
+	}
+	wait := c.backoff
+	for i := 1; i < attempt && wait < maxRetryDuration; i++ {
+		wait *= 2
+	}
+	if wait > maxRetryDuration {
+		wait = maxRetryDuration
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		c.countRetry(req, status)
+		return true
+	}
+}
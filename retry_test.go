@@ -0,0 +1,142 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTransientRetry(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"id": "track"}`)
+	}))
+	defer server.Close()
+
+	m := &recordingMetrics{}
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithTransientRetry(3, time.Millisecond), WithMetrics(m))
+	track, err := client.GetTrack(context.Background(), "4uLU6hMCjMI75M1A2tKUQC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if track.ID != "track" || calls != 3 {
+		t.Errorf("Expected the third attempt to succeed, got %q after %d attempts", track.ID, calls)
+	}
+	if len(m.retries) != 2 || m.retries[0] != "GET tracks/* 502" {
+		t.Errorf("Expected two retries, got %v", m.retries)
+	}
+}
+
+func TestTransientRetryGivesUp(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithTransientRetry(2, time.Millisecond))
+	if _, err := client.GetTrack(context.Background(), "4uLU6hMCjMI75M1A2tKUQC"); err == nil {
+		t.Error("Expected an error")
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls)
+	}
+
+	calls = 0
+	if _, err := client.GetTrack(NoRetry(context.Background()), "4uLU6hMCjMI75M1A2tKUQC"); err == nil {
+		t.Error("Expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 attempt with NoRetry, got %d", calls)
+	}
+}
+
+func TestTransientRetryNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	m := &recordingMetrics{}
+	client := New(http.DefaultClient, WithBaseURL(url+"/"), WithTransientRetry(3, time.Millisecond), WithMetrics(m))
+	if _, err := client.GetTrack(context.Background(), "4uLU6hMCjMI75M1A2tKUQC"); err == nil {
+		t.Error("Expected an error")
+	}
+	if len(m.retries) != 2 || m.retries[0] != "GET tracks/* 0" {
+		t.Errorf("Expected two retries, got %v", m.retries)
+	}
+}
+
+// closeCountingTransport counts the response bodies that are still open.
+type closeCountingTransport struct {
+	open int32
+}
+
+func (t *closeCountingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddInt32(&t.open, 1)
+	resp.Body = &countingBody{ReadCloser: resp.Body, open: &t.open}
+	return resp, nil
+}
+
+type countingBody struct {
+	io.ReadCloser
+	open   *int32
+	closed bool
+}
+
+func (b *countingBody) Close() error {
+	if !b.closed {
+		b.closed = true
+		atomic.AddInt32(b.open, -1)
+	}
+	return b.ReadCloser.Close()
+}
+
+func TestRetryClosesBodies(t *testing.T) {
+	transport := &closeCountingTransport{}
+	var calls int
+	var openBeforeLast int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(rateLimitExceededStatusCode)
+			fmt.Fprint(w, `{"error": {"status": 429, "message": "slow down"}}`)
+		default:
+			openBeforeLast = atomic.LoadInt32(&transport.open)
+			fmt.Fprint(w, `{"id": "track"}`)
+		}
+	}))
+	defer server.Close()
+
+	client := New(&http.Client{Transport: transport}, WithBaseURL(server.URL+"/"), WithRetry(true), WithTransientRetry(3, time.Millisecond))
+	if _, err := client.GetTrack(context.Background(), "4uLU6hMCjMI75M1A2tKUQC"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+	if openBeforeLast != 0 {
+		t.Errorf("Expected the bodies of retried responses to be closed, %d were open", openBeforeLast)
+	}
+	if open := atomic.LoadInt32(&transport.open); open != 0 {
+		t.Errorf("Expected every response body to be closed, %d are open", open)
+	}
+}
@@ -0,0 +1,211 @@
+package spotify
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Authorization scopes, as documented in the spotifyauth package.  They are
+// repeated here so that this package doesn't depend on spotifyauth.
+const (
+	scopeImageUpload               = "ugc-image-upload"
+	scopePlaylistReadPrivate       = "playlist-read-private"
+	scopePlaylistReadCollaborative = "playlist-read-collaborative"
+	scopePlaylistModifyPublic      = "playlist-modify-public"
+	scopePlaylistModifyPrivate     = "playlist-modify-private"
+	scopeUserFollowModify          = "user-follow-modify"
+	scopeUserFollowRead            = "user-follow-read"
+	scopeUserLibraryModify         = "user-library-modify"
+	scopeUserLibraryRead           = "user-library-read"
+	scopeUserReadCurrentlyPlaying  = "user-read-currently-playing"
+	scopeUserReadPlaybackState     = "user-read-playback-state"
+	scopeUserModifyPlaybackState   = "user-modify-playback-state"
+	scopeUserReadRecentlyPlayed    = "user-read-recently-played"
+	scopeUserTopRead               = "user-top-read"
+)
+
+// ErrInsufficientScope is matched by errors.Is when Spotify rejects a request
+// because the access token wasn't granted a scope that the endpoint requires.
+// Use errors.As with an [InsufficientScopeError] to find out which scopes are
+// needed.
+var ErrInsufficientScope = errors.New("spotify: insufficient client scope")
+
+// InsufficientScopeError is returned when Spotify responds with 403 Forbidden
+// because the access token is missing a scope.
+//
+// Such responses used to be returned as a plain [Error].  InsufficientScopeError
+// wraps it, so code that checks for them with a type assertion such as
+// err.(spotify.Error) has to use errors.As instead.
+type InsufficientScopeError struct {
+	Err Error
+	// Scopes lists the scopes that the endpoint is documented to require.
+	// It is empty if the endpoint isn't known.
+	Scopes []string
+}
+
+func (e InsufficientScopeError) Error() string {
+	if len(e.Scopes) == 0 {
+		return e.Err.Error()
+	}
+	return e.Err.Error() + "; the endpoint requires the scopes: " + strings.Join(e.Scopes, ", ")
+}
+
+// Unwrap returns the underlying [Error].
+func (e InsufficientScopeError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is [ErrInsufficientScope].
+func (e InsufficientScopeError) Is(target error) bool {
+	return target == ErrInsufficientScope
+}
+
+// isScopeError reports whether e is Spotify's response to a request made with
+// a token that is missing a scope.
+func isScopeError(e Error) bool {
+	return e.Status == http.StatusForbidden &&
+		strings.Contains(strings.ToLower(e.Message), "scope")
+}
+
+// endpoint describes the authorization requirements of a Client method.
+type endpoint struct {
+	name   string
+	method string
+	// path is relative to the base URL, with "*" matching any path segment.
+	path   string
+	scopes []string
+}
+
+var (
+	playlistModifyScopes = []string{scopePlaylistModifyPublic, scopePlaylistModifyPrivate}
+	playbackModifyScopes = []string{scopeUserModifyPlaybackState}
+)
+
+// endpoints lists the methods that require authorization scopes.
+var endpoints = []endpoint{
+	{"CurrentUsersTracks", http.MethodGet, "me/tracks", []string{scopeUserLibraryRead}},
+	{"UserHasTracks", http.MethodGet, "me/tracks/contains", []string{scopeUserLibraryRead}},
+	{"AddTracksToLibrary", http.MethodPut, "me/tracks", []string{scopeUserLibraryModify}},
+	{"RemoveTracksFromLibrary", http.MethodDelete, "me/tracks", []string{scopeUserLibraryModify}},
+	{"CurrentUsersAlbums", http.MethodGet, "me/albums", []string{scopeUserLibraryRead}},
+	{"CurrentUsersAlbumsAll", http.MethodGet, "me/albums", []string{scopeUserLibraryRead}},
+	{"UserHasAlbums", http.MethodGet, "me/albums/contains", []string{scopeUserLibraryRead}},
+	{"AddAlbumsToLibrary", http.MethodPut, "me/albums", []string{scopeUserLibraryModify}},
+	{"RemoveAlbumsFromLibrary", http.MethodDelete, "me/albums", []string{scopeUserLibraryModify}},
+	{"CurrentUsersShows", http.MethodGet, "me/shows", []string{scopeUserLibraryRead}},
+	{"CurrentUsersShowsAll", http.MethodGet, "me/shows", []string{scopeUserLibraryRead}},
+	{"SaveShowsForCurrentUser", http.MethodPut, "me/shows", []string{scopeUserLibraryModify}},
+
+	{"CurrentUsersTopArtists", http.MethodGet, "me/top/artists", []string{scopeUserTopRead}},
+	{"CurrentUsersTopTracks", http.MethodGet, "me/top/tracks", []string{scopeUserTopRead}},
+
+	{"CurrentUsersFollowedArtists", http.MethodGet, "me/following", []string{scopeUserFollowRead}},
+	{"CurrentUserFollows", http.MethodGet, "me/following/contains", []string{scopeUserFollowRead}},
+	{"CurrentUserFollowsType", http.MethodGet, "me/following/contains", []string{scopeUserFollowRead}},
+	{"FollowUser", http.MethodPut, "me/following", []string{scopeUserFollowModify}},
+	{"FollowArtist", http.MethodPut, "me/following", []string{scopeUserFollowModify}},
+	{"UnfollowUser", http.MethodDelete, "me/following", []string{scopeUserFollowModify}},
+	{"UnfollowArtist", http.MethodDelete, "me/following", []string{scopeUserFollowModify}},
+	{"FollowUserBatched", http.MethodPut, "me/following", []string{scopeUserFollowModify}},
+	{"FollowArtistBatched", http.MethodPut, "me/following", []string{scopeUserFollowModify}},
+	{"UnfollowUserBatched", http.MethodDelete, "me/following", []string{scopeUserFollowModify}},
+	{"UnfollowArtistBatched", http.MethodDelete, "me/following", []string{scopeUserFollowModify}},
+
+	{"CurrentUsersPlaylists", http.MethodGet, "me/playlists", []string{scopePlaylistReadPrivate}},
+	{"GetPlaylistsForUser", http.MethodGet, "users/*/playlists", []string{scopePlaylistReadPrivate, scopePlaylistReadCollaborative}},
+	{"CreatePlaylistForUser", http.MethodPost, "users/*/playlists", playlistModifyScopes},
+	{"CreatePlaylist", http.MethodPost, "users/*/playlists", playlistModifyScopes},
+	{"FollowPlaylist", http.MethodPut, "playlists/*/followers", playlistModifyScopes},
+	{"UnfollowPlaylist", http.MethodDelete, "playlists/*/followers", playlistModifyScopes},
+	{"ChangePlaylistDetails", http.MethodPut, "playlists/*", playlistModifyScopes},
+	{"ChangePlaylistName", http.MethodPut, "playlists/*", playlistModifyScopes},
+	{"ChangePlaylistAccess", http.MethodPut, "playlists/*", playlistModifyScopes},
+	{"ChangePlaylistDescription", http.MethodPut, "playlists/*", playlistModifyScopes},
+	{"ChangePlaylistNameAndAccess", http.MethodPut, "playlists/*", playlistModifyScopes},
+	{"ChangePlaylistNameAccessAndDescription", http.MethodPut, "playlists/*", playlistModifyScopes},
+	{"AddTracksToPlaylist", http.MethodPost, "playlists/*/tracks", playlistModifyScopes},
+	{"MergePlaylists", http.MethodPost, "playlists/*/tracks", playlistModifyScopes},
+	{"RemoveTracksFromPlaylist", http.MethodDelete, "playlists/*/tracks", playlistModifyScopes},
+	{"RemoveTracksFromPlaylistOpt", http.MethodDelete, "playlists/*/tracks", playlistModifyScopes},
+	{"ReplacePlaylistTracks", http.MethodPut, "playlists/*/tracks", playlistModifyScopes},
+	{"ReplacePlaylistItems", http.MethodPut, "playlists/*/tracks", playlistModifyScopes},
+	{"ReorderPlaylistTracks", http.MethodPut, "playlists/*/tracks", playlistModifyScopes},
+	{"SortPlaylist", http.MethodPut, "playlists/*/tracks", playlistModifyScopes},
+	{"SetPlaylistImage", http.MethodPut, "playlists/*/images", []string{scopeImageUpload, scopePlaylistModifyPublic, scopePlaylistModifyPrivate}},
+	{"SetPlaylistImageFromImage", http.MethodPut, "playlists/*/images", []string{scopeImageUpload, scopePlaylistModifyPublic, scopePlaylistModifyPrivate}},
+
+	{"PlayerDevices", http.MethodGet, "me/player/devices", []string{scopeUserReadPlaybackState}},
+	{"PlayerState", http.MethodGet, "me/player", []string{scopeUserReadPlaybackState}},
+	{"PlayerCurrentlyPlaying", http.MethodGet, "me/player/currently-playing", []string{scopeUserReadCurrentlyPlaying}},
+	{"PlayerRecentlyPlayed", http.MethodGet, "me/player/recently-played", []string{scopeUserReadRecentlyPlayed}},
+	{"PlayerRecentlyPlayedOpt", http.MethodGet, "me/player/recently-played", []string{scopeUserReadRecentlyPlayed}},
+	{"GetQueue", http.MethodGet, "me/player/queue", []string{scopeUserReadCurrentlyPlaying, scopeUserReadPlaybackState}},
+	{"TransferPlayback", http.MethodPut, "me/player", playbackModifyScopes},
+	{"Play", http.MethodPut, "me/player/play", playbackModifyScopes},
+	{"PlayOpt", http.MethodPut, "me/player/play", playbackModifyScopes},
+	{"Pause", http.MethodPut, "me/player/pause", playbackModifyScopes},
+	{"PauseOpt", http.MethodPut, "me/player/pause", playbackModifyScopes},
+	{"QueueSong", http.MethodPost, "me/player/queue", playbackModifyScopes},
+	{"QueueSongOpt", http.MethodPost, "me/player/queue", playbackModifyScopes},
+	{"Next", http.MethodPost, "me/player/next", playbackModifyScopes},
+	{"NextOpt", http.MethodPost, "me/player/next", playbackModifyScopes},
+	{"Previous", http.MethodPost, "me/player/previous", playbackModifyScopes},
+	{"PreviousOpt", http.MethodPost, "me/player/previous", playbackModifyScopes},
+	{"Seek", http.MethodPut, "me/player/seek", playbackModifyScopes},
+	{"SeekOpt", http.MethodPut, "me/player/seek", playbackModifyScopes},
+	{"Repeat", http.MethodPut, "me/player/repeat", playbackModifyScopes},
+	{"RepeatOpt", http.MethodPut, "me/player/repeat", playbackModifyScopes},
+	{"Volume", http.MethodPut, "me/player/volume", playbackModifyScopes},
+	{"VolumeOpt", http.MethodPut, "me/player/volume", playbackModifyScopes},
+	{"Shuffle", http.MethodPut, "me/player/shuffle", playbackModifyScopes},
+	{"ShuffleOpt", http.MethodPut, "me/player/shuffle", playbackModifyScopes},
+}
+
+// RequiredScopes returns the authorization scopes that Spotify documents as
+// required by the named [Client] method, such as "CurrentUsersTopTracks".  It
+// returns nil for methods that don't require any scopes.  Some endpoints only
+// need a subset of the scopes, depending on the resource; for example, adding
+// items to a public playlist doesn't require playlist-modify-private.
+//
+// The returned slice must not be modified.
+func RequiredScopes(method string) []string {
+	for _, e := range endpoints {
+		if e.name == method {
+			return e.scopes
+		}
+	}
+	return nil
+}
+
+// matches reports whether a request with the given method and URL path was
+// made to the endpoint.  Only the trailing segments of the path are compared,
+// so that it doesn't matter which base URL the client uses.
+func (e endpoint) matches(method, path string) bool {
+	if method != e.method {
+		return false
+	}
+	want := strings.Split(e.path, "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(got) < len(want) {
+		return false
+	}
+	got = got[len(got)-len(want):]
+	for i := range want {
+		if want[i] != "*" && want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// scopesForRequest returns the scopes required by the endpoint that a request
+// with the given method and URL path was made to.
+func scopesForRequest(method, path string) []string {
+	for _, e := range endpoints {
+		if e.matches(method, path) {
+			return e.scopes
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestInsufficientScopeError(t *testing.T) {
+	client, server := testClientString(http.StatusForbidden, `{"error": {"status": 403, "message": "Insufficient client scope"}}`)
+	defer server.Close()
+
+	_, err := client.CurrentUsersTopTracks(context.Background())
+	if !errors.Is(err, ErrInsufficientScope) {
+		t.Fatalf("Expected ErrInsufficientScope, got %v", err)
+	}
+	var scopeErr InsufficientScopeError
+	if !errors.As(err, &scopeErr) {
+		t.Fatalf("Expected InsufficientScopeError, got %T", err)
+	}
+	if want := []string{"user-top-read"}; !reflect.DeepEqual(scopeErr.Scopes, want) {
+		t.Errorf("Expected scopes %v, got %v", want, scopeErr.Scopes)
+	}
+	var se Error
+	if !errors.As(err, &se) || se.Status != http.StatusForbidden {
+		t.Error("Expected the underlying Error to be available")
+	}
+}
+
+func TestScopesForRequest(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         []string
+	}{
+		{http.MethodGet, "/v1/me/player", []string{scopeUserReadPlaybackState}},
+		{http.MethodPut, "/v1/me/player", []string{scopeUserModifyPlaybackState}},
+		{http.MethodPost, "/v1/playlists/abc/tracks", []string{scopePlaylistModifyPublic, scopePlaylistModifyPrivate}},
+		{http.MethodGet, "/v1/me/tracks/contains", []string{scopeUserLibraryRead}},
+		{http.MethodGet, "/v1/albums/abc", nil},
+	}
+	for _, test := range tests {
+		if got := scopesForRequest(test.method, test.path); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s %s: expected %v, got %v", test.method, test.path, test.want, got)
+		}
+	}
+}
+
+func TestRequiredScopes(t *testing.T) {
+	if want, got := []string{scopeUserTopRead}, RequiredScopes("CurrentUsersTopTracks"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := RequiredScopes("GetAlbum"); got != nil {
+		t.Errorf("Expected no scopes, got %v", got)
+	}
+}
@@ -0,0 +1,205 @@
+// Package scrobble tails a Spotify user's recently-played history and hands
+// each play to a [Scrobbler], such as a bridge to ListenBrainz or Last.fm.
+//
+// A [Runner] takes care of polling, ordering plays from oldest to newest,
+// skipping plays that were already submitted, and remembering how far it got
+// in a [Store] so that no plays are lost or duplicated across restarts.
+// Implementations only need to submit the plays they are given.
+//
+// Example:
+//
+//	r := scrobble.NewRunner(client, myScrobbler,
+//		scrobble.WithStore(scrobble.FileStore("last-played.txt")))
+//	log.Fatal(r.Run(ctx))
+package scrobble
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// DefaultInterval is the time between polls if [WithInterval] isn't used.
+// Spotify only remembers the fifty most-recent plays, so the interval should
+// be short enough that fewer than fifty tracks are played between polls.
+const DefaultInterval = 5 * time.Minute
+
+// Scrobbler submits a single play to an external service.
+type Scrobbler interface {
+	Scrobble(item spotify.RecentlyPlayedItem) error
+}
+
+// ScrobblerFunc is an adapter to allow the use of ordinary functions as a
+// [Scrobbler].
+type ScrobblerFunc func(item spotify.RecentlyPlayedItem) error
+
+// Scrobble calls f(item).
+func (f ScrobblerFunc) Scrobble(item spotify.RecentlyPlayedItem) error {
+	return f(item)
+}
+
+// Store persists the time of the last play that was successfully scrobbled.
+type Store interface {
+	// LastPlayed returns the time of the last scrobbled play, or the zero
+	// time if nothing has been scrobbled yet.
+	LastPlayed() (time.Time, error)
+	// SetLastPlayed records the time of the last scrobbled play.
+	SetLastPlayed(t time.Time) error
+}
+
+// MemoryStore is a [Store] that keeps the cursor in memory.  It is the default
+// store of a [Runner], and doesn't survive restarts.
+type MemoryStore struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+// LastPlayed implements [Store].
+func (s *MemoryStore) LastPlayed() (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last, nil
+}
+
+// SetLastPlayed implements [Store].
+func (s *MemoryStore) SetLastPlayed(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = t
+	return nil
+}
+
+// FileStore is a [Store] that keeps the cursor in the named file, as an
+// RFC 3339 timestamp.  A missing file is treated as an empty cursor.
+type FileStore string
+
+// LastPlayed implements [Store].
+func (f FileStore) LastPlayed() (time.Time, error) {
+	data, err := os.ReadFile(string(f))
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+}
+
+// SetLastPlayed implements [Store].
+func (f FileStore) SetLastPlayed(t time.Time) error {
+	return os.WriteFile(string(f), []byte(t.UTC().Format(time.RFC3339Nano)+"\n"), 0o600)
+}
+
+// Runner tails the recently-played history of the user that its client is
+// authenticated as.  You should always use [NewRunner] to make them.
+type Runner struct {
+	client    *spotify.Client
+	scrobbler Scrobbler
+	store     Store
+	interval  time.Duration
+}
+
+// Option configures a [Runner] made by [NewRunner].
+type Option func(r *Runner)
+
+// WithStore configures where the runner persists its cursor.
+func WithStore(s Store) Option {
+	return func(r *Runner) {
+		r.store = s
+	}
+}
+
+// WithInterval sets the time between polls.  Intervals that aren't positive
+// are replaced by [DefaultInterval].
+func WithInterval(d time.Duration) Option {
+	return func(r *Runner) {
+		r.interval = d
+	}
+}
+
+// NewRunner creates a runner that submits plays to s.  The client needs the
+// [spotifyauth.ScopeUserReadRecentlyPlayed] scope.
+func NewRunner(client *spotify.Client, s Scrobbler, opts ...Option) *Runner {
+	r := &Runner{
+		client:    client,
+		scrobbler: s,
+		store:     &MemoryStore{},
+		interval:  DefaultInterval,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.interval <= 0 {
+		r.interval = DefaultInterval
+	}
+
+	return r
+}
+
+// Run polls for new plays until ctx is done, and then returns ctx.Err().
+// Failures to fetch or scrobble plays don't stop the runner; they are retried
+// on the next poll.  Use [Runner.Poll] to handle errors yourself.
+func (r *Runner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		_, _ = r.Poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches the plays that happened since the stored cursor and scrobbles
+// them from oldest to newest, advancing the cursor after each one.  It stops
+// at the first play that can't be scrobbled and returns the error, so that the
+// play is retried on the next poll.  It returns the number of plays that were
+// scrobbled.
+func (r *Runner) Poll(ctx context.Context) (int, error) {
+	last, err := r.store.LastPlayed()
+	if err != nil {
+		return 0, err
+	}
+
+	opt := &spotify.RecentlyPlayedOptions{Limit: 50}
+	if !last.IsZero() {
+		opt.AfterEpochMs = last.UnixNano() / int64(time.Millisecond)
+	}
+	items, err := r.client.PlayerRecentlyPlayedOpt(ctx, opt)
+	if err != nil {
+		return 0, err
+	}
+
+	// Spotify returns the most recent play first
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].PlayedAt.Before(items[j].PlayedAt)
+	})
+
+	n := 0
+	for _, item := range items {
+		if !item.PlayedAt.After(last) {
+			continue
+		}
+		if err := r.scrobbler.Scrobble(item); err != nil {
+			return n, err
+		}
+		last = item.PlayedAt
+		if err := r.store.SetLastPlayed(last); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, nil
+}
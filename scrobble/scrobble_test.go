@@ -0,0 +1,145 @@
+package scrobble
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+const recentlyPlayed = `{
+  "items": [
+    {"track": {"id": "c", "name": "Third"}, "played_at": "2021-01-01T00:10:00.000Z"},
+    {"track": {"id": "b", "name": "Second"}, "played_at": "2021-01-01T00:05:00.000Z"},
+    {"track": {"id": "a", "name": "First"}, "played_at": "2021-01-01T00:00:00.000Z"}
+  ]
+}`
+
+func testClient(validate func(*http.Request)) (*spotify.Client, *httptest.Server) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if validate != nil {
+			validate(r)
+		}
+		_, _ = io.WriteString(w, recentlyPlayed)
+	}))
+	return spotify.New(http.DefaultClient, spotify.WithBaseURL(server.URL+"/")), server
+}
+
+func TestPoll(t *testing.T) {
+	client, server := testClient(nil)
+	defer server.Close()
+
+	var scrobbled []spotify.ID
+	r := NewRunner(client, ScrobblerFunc(func(item spotify.RecentlyPlayedItem) error {
+		scrobbled = append(scrobbled, item.Track.ID)
+		return nil
+	}))
+
+	n, err := r.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || len(scrobbled) != 3 {
+		t.Fatalf("Expected 3 scrobbles, got %d", n)
+	}
+	if scrobbled[0] != "a" || scrobbled[2] != "c" {
+		t.Errorf("Expected plays in chronological order, got %v", scrobbled)
+	}
+
+	// the same plays are returned again, but have already been scrobbled
+	n, err = r.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("Expected duplicates to be skipped, got %d scrobbles", n)
+	}
+}
+
+func TestPollStopsAtFailure(t *testing.T) {
+	client, server := testClient(nil)
+	defer server.Close()
+
+	store := &MemoryStore{}
+	r := NewRunner(client, ScrobblerFunc(func(item spotify.RecentlyPlayedItem) error {
+		if item.Track.ID == "b" {
+			return errors.New("service unavailable")
+		}
+		return nil
+	}), WithStore(store))
+
+	n, err := r.Poll(context.Background())
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 scrobble, got %d", n)
+	}
+	last, _ := store.LastPlayed()
+	if want := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC); !last.Equal(want) {
+		t.Errorf("Expected cursor at %v, got %v", want, last)
+	}
+}
+
+func TestPollSendsCursor(t *testing.T) {
+	client, server := testClient(func(r *http.Request) {
+		if after := r.URL.Query().Get("after"); after != "1609459500000" {
+			t.Errorf("Expected after cursor, got %q", after)
+		}
+	})
+	defer server.Close()
+
+	store := &MemoryStore{}
+	_ = store.SetLastPlayed(time.Date(2021, 1, 1, 0, 5, 0, 0, time.UTC))
+
+	var scrobbled []spotify.ID
+	r := NewRunner(client, ScrobblerFunc(func(item spotify.RecentlyPlayedItem) error {
+		scrobbled = append(scrobbled, item.Track.ID)
+		return nil
+	}), WithStore(store))
+
+	if _, err := r.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(scrobbled) != 1 || scrobbled[0] != "c" {
+		t.Errorf("Expected only the newest play, got %v", scrobbled)
+	}
+}
+
+func TestNewRunnerInvalidInterval(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		if r := NewRunner(nil, nil, WithInterval(d)); r.interval != DefaultInterval {
+			t.Errorf("Expected an interval of %v to be replaced by the default, got %v", d, r.interval)
+		}
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	store := FileStore(filepath.Join(t.TempDir(), "cursor"))
+
+	last, err := store.LastPlayed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.IsZero() {
+		t.Error("Expected zero time for missing file, got", last)
+	}
+
+	want := time.Date(2021, 1, 1, 0, 10, 0, 0, time.UTC)
+	if err := store.SetLastPlayed(want); err != nil {
+		t.Fatal(err)
+	}
+	last, err = store.LastPlayed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, last)
+	}
+}
@@ -6,12 +6,13 @@ import (
 )
 
 const (
-	// MarketFromToken can be used in place of the Options.Country parameter
-	// if the Client has a valid access token.  In this case, the
-	// results will be limited to content that is playable in the
+	// MarketFromToken can be passed to the Market option in place of a
+	// country code if the Client has a valid access token.  In this case,
+	// the results will be limited to content that is playable in the
 	// country associated with the user's account.  The user must have
 	// granted access to the user-read-private scope when the access
-	// token was issued.
+	// token was issued.  It can't be used with tokens obtained with the
+	// client credentials flow; see [TokenMarket].
 	MarketFromToken = "from_token"
 )
 
@@ -22,12 +23,13 @@ type SearchType int
 // that can be bitwise OR'd together to search for multiple types of content
 // simultaneously.
 const (
-	SearchTypeAlbum    SearchType = 1 << iota
-	SearchTypeArtist              = 1 << iota
-	SearchTypePlaylist            = 1 << iota
-	SearchTypeTrack               = 1 << iota
-	SearchTypeShow                = 1 << iota
-	SearchTypeEpisode             = 1 << iota
+	SearchTypeAlbum     SearchType = 1 << iota
+	SearchTypeArtist               = 1 << iota
+	SearchTypePlaylist             = 1 << iota
+	SearchTypeTrack                = 1 << iota
+	SearchTypeShow                 = 1 << iota
+	SearchTypeEpisode              = 1 << iota
+	SearchTypeAudiobook            = 1 << iota
 )
 
 func (st SearchType) encode() string {
@@ -50,18 +52,22 @@ func (st SearchType) encode() string {
 	if st&SearchTypeEpisode != 0 {
 		types = append(types, "episode")
 	}
+	if st&SearchTypeAudiobook != 0 {
+		types = append(types, "audiobook")
+	}
 	return strings.Join(types, ",")
 }
 
 // SearchResult contains the results of a call to [Search].
 // Fields that weren't searched for will be nil pointers.
 type SearchResult struct {
-	Artists   *FullArtistPage     `json:"artists"`
-	Albums    *SimpleAlbumPage    `json:"albums"`
-	Playlists *SimplePlaylistPage `json:"playlists"`
-	Tracks    *FullTrackPage      `json:"tracks"`
-	Shows     *SimpleShowPage     `json:"shows"`
-	Episodes  *SimpleEpisodePage  `json:"episodes"`
+	Artists    *FullArtistPage      `json:"artists"`
+	Albums     *SimpleAlbumPage     `json:"albums"`
+	Playlists  *SimplePlaylistPage  `json:"playlists"`
+	Tracks     *FullTrackPage       `json:"tracks"`
+	Shows      *SimpleShowPage      `json:"shows"`
+	Episodes   *SimpleEpisodePage   `json:"episodes"`
+	Audiobooks *SimpleAudiobookPage `json:"audiobooks"`
 }
 
 // Search gets [Spotify catalog information] about artists, albums, tracks,
@@ -115,7 +121,8 @@ type SearchResult struct {
 // If the Market field is specified in the options, then the results will only
 // contain artists, albums, and tracks playable in the specified country
 // (playlist results are not affected by the Market option).  Additionally,
-// the constant MarketFromToken can be used with authenticated clients.
+// the constant MarketFromToken, or the [TokenMarket] option, can be used with
+// clients that act on behalf of a user.
 // If the client has a valid access token, then the results will only include
 // content playable in the user's country.
 //
@@ -123,7 +130,12 @@ type SearchResult struct {
 //
 // [Spotify catalog information]: https://developer.spotify.com/documentation/web-api/reference/search
 func (c *Client) Search(ctx context.Context, query string, t SearchType, opts ...RequestOption) (*SearchResult, error) {
-	v := processOptions(opts...).urlParams
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	v := o.urlParams
 	v.Set("q", query)
 	v.Set("type", t.encode())
 
@@ -131,7 +143,7 @@ func (c *Client) Search(ctx context.Context, query string, t SearchType, opts ..
 
 	var result SearchResult
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -227,6 +239,22 @@ func (c *Client) PreviousEpisodeResults(ctx context.Context, s *SearchResult) er
 	return c.get(ctx, s.Episodes.Previous, s)
 }
 
+// PreviousAudiobookResults loads the previous page of audiobooks into the specified search result.
+func (c *Client) PreviousAudiobookResults(ctx context.Context, s *SearchResult) error {
+	if s.Audiobooks == nil || s.Audiobooks.Previous == "" {
+		return ErrNoMorePages
+	}
+	return c.get(ctx, s.Audiobooks.Previous, s)
+}
+
+// NextAudiobookResults loads the next page of audiobooks into the specified search result.
+func (c *Client) NextAudiobookResults(ctx context.Context, s *SearchResult) error {
+	if s.Audiobooks == nil || s.Audiobooks.Next == "" {
+		return ErrNoMorePages
+	}
+	return c.get(ctx, s.Audiobooks.Next, s)
+}
+
 // NextEpisodeResults loads the next page of episodes into the specified search result.
 func (c *Client) NextEpisodeResults(ctx context.Context, s *SearchResult) error {
 	if s.Episodes == nil || s.Episodes.Next == "" {
@@ -234,3 +262,120 @@ func (c *Client) NextEpisodeResults(ctx context.Context, s *SearchResult) error
 	}
 	return c.get(ctx, s.Episodes.Next, s)
 }
+
+const (
+	// searchResultCeiling is the number of results that the search endpoint
+	// returns at most for each type, whatever the offset.
+	searchResultCeiling = 1000
+	// searchAllConcurrency is the number of pages that SearchAll fetches at
+	// once for each type.
+	searchAllConcurrency = 4
+)
+
+// SearchItems contains the results of a call to [Client.SearchAll], in the
+// order that they were ranked by Spotify.  Fields for types that weren't
+// searched for are empty.
+type SearchItems struct {
+	Artists    []FullArtist
+	Albums     []SimpleAlbum
+	Playlists  []SimplePlaylist
+	Tracks     []FullTrack
+	Shows      []FullShow
+	Episodes   []EpisodePage
+	Audiobooks []SimpleAudiobook
+}
+
+// SearchAll is like [Client.Search], but fetches the pages of results of each
+// type concurrently, and returns up to max results per type.  Spotify returns
+// at most 1000 results of each type for a query, so a max of 0 or above 1000
+// fetches all of them.  Requests that are rate limited are retried after the
+// delay requested by Spotify.
+//
+// Supported options: [Market].
+func (c *Client) SearchAll(ctx context.Context, query string, t SearchType, max int, opts ...RequestOption) (*SearchItems, error) {
+	if max < 1 || max > searchResultCeiling {
+		max = searchResultCeiling
+	}
+
+	items := &SearchItems{}
+	types := []SearchType{SearchTypeArtist, SearchTypeAlbum, SearchTypePlaylist, SearchTypeTrack, SearchTypeShow, SearchTypeEpisode, SearchTypeAudiobook}
+	for _, typ := range types {
+		if t&typ == 0 {
+			continue
+		}
+		var pages OrderedPages
+		err := FetchAllPagesParallel(ctx, func(ctx context.Context, offset, limit int) (int, error) {
+			if offset+limit > max {
+				limit = max - offset
+			}
+			res, err := c.Search(ctx, query, typ, append(opts[:len(opts):len(opts)], Limit(limit), Offset(offset))...)
+			if err != nil {
+				return 0, err
+			}
+			pages.Add(offset, res)
+
+			total := 0
+			if p := res.pageFor(typ).page(); p != nil {
+				total = int(p.Total)
+			}
+			if total > max {
+				total = max
+			}
+			return total, nil
+		}, 50, searchAllConcurrency)
+		if err != nil {
+			return nil, err
+		}
+
+		pages.Each(func(page interface{}) {
+			items.add(page.(*SearchResult))
+		})
+	}
+	return items, nil
+}
+
+// pageFor returns the page of results of type t.
+func (r *SearchResult) pageFor(t SearchType) pageable {
+	switch t {
+	case SearchTypeArtist:
+		return r.Artists
+	case SearchTypeAlbum:
+		return r.Albums
+	case SearchTypePlaylist:
+		return r.Playlists
+	case SearchTypeTrack:
+		return r.Tracks
+	case SearchTypeShow:
+		return r.Shows
+	case SearchTypeEpisode:
+		return r.Episodes
+	case SearchTypeAudiobook:
+		return r.Audiobooks
+	}
+	return (*basePage)(nil)
+}
+
+// add appends the results in r.
+func (s *SearchItems) add(r *SearchResult) {
+	if r.Artists != nil {
+		s.Artists = append(s.Artists, r.Artists.Artists...)
+	}
+	if r.Albums != nil {
+		s.Albums = append(s.Albums, r.Albums.Albums...)
+	}
+	if r.Playlists != nil {
+		s.Playlists = append(s.Playlists, r.Playlists.Playlists...)
+	}
+	if r.Tracks != nil {
+		s.Tracks = append(s.Tracks, r.Tracks.Tracks...)
+	}
+	if r.Shows != nil {
+		s.Shows = append(s.Shows, r.Shows.Shows...)
+	}
+	if r.Episodes != nil {
+		s.Episodes = append(s.Episodes, r.Episodes.Episodes...)
+	}
+	if r.Audiobooks != nil {
+		s.Audiobooks = append(s.Audiobooks, r.Audiobooks.Audiobooks...)
+	}
+}
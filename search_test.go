@@ -2,7 +2,12 @@ package spotify
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -86,13 +91,14 @@ func TestPrevNextSearchPageErrors(t *testing.T) {
 	// under either of these conditions:
 
 	//  1) there are no results (nil)
-	nilResults := &SearchResult{nil, nil, nil, nil, nil, nil}
+	nilResults := &SearchResult{nil, nil, nil, nil, nil, nil, nil}
 	if client.NextAlbumResults(context.Background(), nilResults) != ErrNoMorePages ||
 		client.NextArtistResults(context.Background(), nilResults) != ErrNoMorePages ||
 		client.NextPlaylistResults(context.Background(), nilResults) != ErrNoMorePages ||
 		client.NextTrackResults(context.Background(), nilResults) != ErrNoMorePages ||
 		client.NextShowResults(context.Background(), nilResults) != ErrNoMorePages ||
-		client.NextEpisodeResults(context.Background(), nilResults) != ErrNoMorePages {
+		client.NextEpisodeResults(context.Background(), nilResults) != ErrNoMorePages ||
+		client.NextAudiobookResults(context.Background(), nilResults) != ErrNoMorePages {
 		t.Error("Next search result page should have failed for nil results")
 	}
 	if client.PreviousAlbumResults(context.Background(), nilResults) != ErrNoMorePages ||
@@ -100,24 +106,27 @@ func TestPrevNextSearchPageErrors(t *testing.T) {
 		client.PreviousPlaylistResults(context.Background(), nilResults) != ErrNoMorePages ||
 		client.PreviousTrackResults(context.Background(), nilResults) != ErrNoMorePages ||
 		client.PreviousShowResults(context.Background(), nilResults) != ErrNoMorePages ||
-		client.PreviousEpisodeResults(context.Background(), nilResults) != ErrNoMorePages {
+		client.PreviousEpisodeResults(context.Background(), nilResults) != ErrNoMorePages ||
+		client.PreviousAudiobookResults(context.Background(), nilResults) != ErrNoMorePages {
 		t.Error("Previous search result page should have failed for nil results")
 	}
 	//  2) the prev/next URL is empty
 	emptyURL := &SearchResult{
-		Artists:   new(FullArtistPage),
-		Albums:    new(SimpleAlbumPage),
-		Playlists: new(SimplePlaylistPage),
-		Tracks:    new(FullTrackPage),
-		Shows:     new(SimpleShowPage),
-		Episodes:  new(SimpleEpisodePage),
+		Artists:    new(FullArtistPage),
+		Albums:     new(SimpleAlbumPage),
+		Playlists:  new(SimplePlaylistPage),
+		Tracks:     new(FullTrackPage),
+		Shows:      new(SimpleShowPage),
+		Episodes:   new(SimpleEpisodePage),
+		Audiobooks: new(SimpleAudiobookPage),
 	}
 	if client.NextAlbumResults(context.Background(), emptyURL) != ErrNoMorePages ||
 		client.NextArtistResults(context.Background(), emptyURL) != ErrNoMorePages ||
 		client.NextPlaylistResults(context.Background(), emptyURL) != ErrNoMorePages ||
 		client.NextTrackResults(context.Background(), emptyURL) != ErrNoMorePages ||
 		client.NextShowResults(context.Background(), emptyURL) != ErrNoMorePages ||
-		client.NextEpisodeResults(context.Background(), emptyURL) != ErrNoMorePages {
+		client.NextEpisodeResults(context.Background(), emptyURL) != ErrNoMorePages ||
+		client.NextAudiobookResults(context.Background(), emptyURL) != ErrNoMorePages {
 		t.Error("Next search result page should have failed with empty URL")
 	}
 	if client.PreviousAlbumResults(context.Background(), emptyURL) != ErrNoMorePages ||
@@ -125,7 +134,76 @@ func TestPrevNextSearchPageErrors(t *testing.T) {
 		client.PreviousPlaylistResults(context.Background(), emptyURL) != ErrNoMorePages ||
 		client.PreviousTrackResults(context.Background(), emptyURL) != ErrNoMorePages ||
 		client.PreviousShowResults(context.Background(), emptyURL) != ErrNoMorePages ||
-		client.PreviousEpisodeResults(context.Background(), emptyURL) != ErrNoMorePages {
+		client.PreviousEpisodeResults(context.Background(), emptyURL) != ErrNoMorePages ||
+		client.PreviousAudiobookResults(context.Background(), emptyURL) != ErrNoMorePages {
 		t.Error("Previous search result page should have failed with empty URL")
 	}
 }
+
+func TestSearchAll(t *testing.T) {
+	const total = 2000
+	var mu sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		mu.Lock()
+		requested = append(requested, q.Get("type")+" "+q.Get("offset")+" "+q.Get("limit"))
+		mu.Unlock()
+
+		var items []string
+		for i := offset; i < offset+limit && i < total; i++ {
+			items = append(items, fmt.Sprintf(`{"id": "%d"}`, i))
+		}
+		fmt.Fprintf(w, `{"%ss": {"total": %d, "offset": %d, "items": [%s]}}`, q.Get("type"), total, offset, strings.Join(items, ","))
+	}))
+	defer server.Close()
+
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+	items, err := client.SearchAll(context.Background(), "query", SearchTypeTrack|SearchTypeArtist, 120)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items.Tracks) != 120 || len(items.Artists) != 120 {
+		t.Fatalf("Expected 120 tracks and artists, got %d and %d", len(items.Tracks), len(items.Artists))
+	}
+	for i, track := range items.Tracks {
+		if track.ID != ID(strconv.Itoa(i)) {
+			t.Fatalf("Expected track %d at position %d, got %s", i, i, track.ID)
+		}
+	}
+	if len(requested) != 6 {
+		t.Errorf("Expected 6 requests, got %v", requested)
+	}
+	for _, r := range requested {
+		if strings.HasSuffix(r, " 100 50") {
+			t.Errorf("Expected the last page to be limited to the maximum, got %v", requested)
+		}
+	}
+
+	items, err = client.SearchAll(context.Background(), "query", SearchTypeAlbum, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items.Albums) != searchResultCeiling {
+		t.Errorf("Expected %d albums, got %d", searchResultCeiling, len(items.Albums))
+	}
+}
+
+func TestSearchPlaylistsSkipsNulls(t *testing.T) {
+	client, server := testClientString(http.StatusOK, `{"playlists": {"total": 3, "limit": 3, "items": [null, {"id": "a", "name": "A"}, null]}}`)
+	defer server.Close()
+
+	result, err := client.Search(context.Background(), "party", SearchTypePlaylist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := result.Playlists
+	if len(page.Playlists) != 1 || page.Playlists[0].ID != "a" {
+		t.Errorf("Expected only playlist a, got %v", page.Playlists)
+	}
+	if page.Unavailable != 2 || page.Total != 3 {
+		t.Errorf("Expected 2 unavailable playlists out of 3, got %d out of %d", page.Unavailable, page.Total)
+	}
+}
@@ -0,0 +1,89 @@
+package sessions
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/oauth2"
+)
+
+// encryptedTokenType marks the tokens written by an encrypted store.
+const encryptedTokenType = "encrypted"
+
+// encryptedStore encrypts tokens before passing them to store.
+type encryptedStore struct {
+	store TokenStore
+	aead  cipher.AEAD
+}
+
+// NewEncryptedStore wraps store so that tokens are encrypted at rest with
+// AES-GCM.  The key must be 16, 24 or 32 bytes long, to select AES-128,
+// AES-192 or AES-256, and should come from a secret manager rather than
+// being stored next to the tokens.
+//
+// The underlying store is given tokens whose AccessToken holds the
+// encrypted, base64-encoded token, and whose other fields are empty.  The
+// ciphertext is bound to the user ID, so it can't be moved to another user.
+func NewEncryptedStore(store TokenStore, key []byte) (TokenStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedStore{store: store, aead: aead}, nil
+}
+
+// Load implements [TokenStore].
+func (s *encryptedStore) Load(ctx context.Context, userID string) (*oauth2.Token, error) {
+	stored, err := s.store.Load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if stored.TokenType != encryptedTokenType {
+		return nil, fmt.Errorf("sessions: token for %s isn't encrypted", userID)
+	}
+	data, err := base64.StdEncoding.DecodeString(stored.AccessToken)
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return nil, fmt.Errorf("sessions: malformed encrypted token for %s", userID)
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, []byte(userID))
+	if err != nil {
+		return nil, fmt.Errorf("sessions: couldn't decrypt token for %s: %w", userID, err)
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal(plaintext, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Save implements [TokenStore].
+func (s *encryptedStore) Save(ctx context.Context, userID string, token *oauth2.Token) error {
+	plaintext, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data := s.aead.Seal(nonce, nonce, plaintext, []byte(userID))
+	return s.store.Save(ctx, userID, &oauth2.Token{
+		AccessToken: base64.StdEncoding.EncodeToString(data),
+		TokenType:   encryptedTokenType,
+	})
+}
+
+// Delete implements [TokenStore].
+func (s *encryptedStore) Delete(ctx context.Context, userID string) error {
+	return s.store.Delete(ctx, userID)
+}
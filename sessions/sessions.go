@@ -0,0 +1,327 @@
+// Package sessions manages the tokens of the many users of a multi-tenant
+// Spotify application.
+//
+// A [Manager] keeps each user's token in a [TokenStore], keyed by the user's
+// Spotify ID, and hands out [spotify.Client] values that refresh the token
+// when it expires and save the refreshed token back to the store.
+//
+// Example:
+//
+//	m := sessions.New(auth, sessions.NewMemoryStore())
+//
+//	// in the redirect handler, with the state kept in the login session:
+//	token, err := auth.Token(r.Context(), state, r)
+//	if err != nil {
+//		// handle error
+//	}
+//	userID, client, err := m.Login(r.Context(), token)
+//	// remember userID in the user's session cookie
+//
+//	// in later requests:
+//	client, err := m.Client(r.Context(), userID)
+package sessions
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"golang.org/x/oauth2"
+
+	"github.com/zmb3/spotify/v2"
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
+)
+
+// ErrNoSession is returned when there is no token for a user.
+var ErrNoSession = errors.New("sessions: no token for user")
+
+// TokenStore persists users' tokens.  Implementations must be safe for
+// concurrent use.
+type TokenStore interface {
+	// Load returns the token saved for the user, or [ErrNoSession].
+	Load(ctx context.Context, userID string) (*oauth2.Token, error)
+	// Save replaces the token saved for the user.
+	Save(ctx context.Context, userID string, token *oauth2.Token) error
+	// Delete removes the token saved for the user, if any.
+	Delete(ctx context.Context, userID string) error
+}
+
+// MemoryStore is a [TokenStore] that keeps tokens in memory.  They don't
+// survive restarts, so it's mostly useful for tests and development.
+// You should always use [NewMemoryStore] to make them.
+type MemoryStore struct {
+	mu     sync.Mutex
+	tokens map[string]oauth2.Token
+}
+
+// NewMemoryStore creates an empty [MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tokens: make(map[string]oauth2.Token)}
+}
+
+// Load implements [TokenStore].
+func (m *MemoryStore) Load(ctx context.Context, userID string) (*oauth2.Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	token, ok := m.tokens[userID]
+	if !ok {
+		return nil, ErrNoSession
+	}
+	return &token, nil
+}
+
+// Save implements [TokenStore].
+func (m *MemoryStore) Save(ctx context.Context, userID string, token *oauth2.Token) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[userID] = *token
+	return nil
+}
+
+// Delete implements [TokenStore].
+func (m *MemoryStore) Delete(ctx context.Context, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tokens, userID)
+	return nil
+}
+
+// DefaultMaxClients is the number of clients that a [Manager] keeps for reuse
+// if [WithMaxClients] isn't used.
+const DefaultMaxClients = 1000
+
+// Manager hands out clients for the users whose tokens are in its store.
+// You should always use [New] to make them.
+type Manager struct {
+	auth        *spotifyauth.Authenticator
+	store       TokenStore
+	clientOpts  []spotify.ClientOption
+	onSaveError func(userID string, err error)
+	maxClients  int
+	httpClient  *http.Client
+
+	// saveMu is held for reading while a refreshed token is saved, and
+	// for writing while a session is deleted, so that a refresh can't save
+	// the token of a session that was logged out.
+	saveMu sync.RWMutex
+
+	mu      sync.Mutex
+	order   *list.List
+	clients map[string]*list.Element
+}
+
+type managedClient struct {
+	userID string
+	client *spotify.Client
+}
+
+// Option configures a [Manager] made by [New].
+type Option func(m *Manager)
+
+// WithClientOptions configures the options passed to [spotify.New] for every
+// client made by the manager.
+func WithClientOptions(opts ...spotify.ClientOption) Option {
+	return func(m *Manager) {
+		m.clientOpts = opts
+	}
+}
+
+// WithSaveErrorHandler configures a function that is called when a refreshed
+// token can't be saved.  The request that caused the refresh still succeeds,
+// but the token is refreshed again once the user's client is made anew.  By
+// default, these errors are ignored.
+func WithSaveErrorHandler(f func(userID string, err error)) Option {
+	return func(m *Manager) {
+		m.onSaveError = f
+	}
+}
+
+// WithMaxClients configures the number of clients that the manager keeps for
+// reuse by [Manager.Client].  When there are more, the least recently used
+// client is discarded, and made again from the store when it is next needed.
+// Values less than 1 are ignored.
+func WithMaxClients(n int) Option {
+	return func(m *Manager) {
+		if n > 0 {
+			m.maxClients = n
+		}
+	}
+}
+
+// WithHTTPClient configures the [net/http.Client] that the manager's clients
+// use to send their requests, including the requests that refresh tokens.  By
+// default, [net/http.DefaultClient] is used.
+func WithHTTPClient(c *http.Client) Option {
+	return func(m *Manager) {
+		m.httpClient = c
+	}
+}
+
+// New creates a manager that refreshes tokens with auth and keeps them in
+// store.
+func New(auth *spotifyauth.Authenticator, store TokenStore, opts ...Option) *Manager {
+	m := &Manager{
+		auth:       auth,
+		store:      store,
+		maxClients: DefaultMaxClients,
+		order:      list.New(),
+		clients:    make(map[string]*list.Element),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Login starts a session with a token obtained from [spotifyauth.Authenticator.Token]
+// or Exchange.  It looks up the user's ID, saves the token under it, and
+// returns the ID along with a client for the user.  An existing session of
+// the same user is replaced.
+func (m *Manager) Login(ctx context.Context, token *oauth2.Token) (string, *spotify.Client, error) {
+	client := spotify.New(m.auth.Client(m.background(), token), m.clientOpts...)
+	user, err := client.CurrentUser(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	m.saveMu.RLock()
+	err = m.store.Save(ctx, user.ID, token)
+	m.saveMu.RUnlock()
+	if err != nil {
+		return "", nil, err
+	}
+
+	client = m.newClient(user.ID, token)
+	m.mu.Lock()
+	m.remember(user.ID, client)
+	m.mu.Unlock()
+	return user.ID, client, nil
+}
+
+// Client returns a client for the user, or [ErrNoSession] if the user has no
+// token in the store.  Clients are made once per user and then reused, up to
+// the limit set by [WithMaxClients].
+func (m *Manager) Client(ctx context.Context, userID string) (*spotify.Client, error) {
+	if client, ok := m.cached(userID); ok {
+		return client, nil
+	}
+
+	token, err := m.store.Load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// another goroutine may have made the client in the meantime
+	if el, ok := m.clients[userID]; ok {
+		m.order.MoveToFront(el)
+		return el.Value.(*managedClient).client, nil
+	}
+	client := m.newClient(userID, token)
+	m.remember(userID, client)
+	return client, nil
+}
+
+// Logout ends the user's session, deleting the token from the store.  Clients
+// that were already handed out keep working until their access token
+// expires; they then fail with [ErrNoSession] instead of refreshing it.
+func (m *Manager) Logout(ctx context.Context, userID string) error {
+	m.mu.Lock()
+	if el, ok := m.clients[userID]; ok {
+		m.order.Remove(el)
+		delete(m.clients, userID)
+	}
+	m.mu.Unlock()
+
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+	return m.store.Delete(ctx, userID)
+}
+
+func (m *Manager) cached(userID string) (*spotify.Client, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.clients[userID]
+	if !ok {
+		return nil, false
+	}
+	m.order.MoveToFront(el)
+	return el.Value.(*managedClient).client, true
+}
+
+// remember adds the user's client, replacing any other, and evicts the least
+// recently used clients if there are too many.  m.mu must be held.
+func (m *Manager) remember(userID string, client *spotify.Client) {
+	if el, ok := m.clients[userID]; ok {
+		el.Value.(*managedClient).client = client
+		m.order.MoveToFront(el)
+		return
+	}
+	m.clients[userID] = m.order.PushFront(&managedClient{userID: userID, client: client})
+	for m.order.Len() > m.maxClients {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.clients, oldest.Value.(*managedClient).userID)
+	}
+}
+
+// newClient makes a client that saves refreshed tokens under userID.  Tokens
+// are refreshed with a background context, as clients outlive the requests
+// that they are made for.
+func (m *Manager) newClient(userID string, token *oauth2.Token) *spotify.Client {
+	src := &savingTokenSource{m: m, userID: userID, token: token}
+	return spotify.New(oauth2.NewClient(m.background(), src), m.clientOpts...)
+}
+
+// background returns the context that tokens are refreshed with, which
+// carries the client configured by [WithHTTPClient].
+func (m *Manager) background() context.Context {
+	ctx := context.Background()
+	if m.httpClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, m.httpClient)
+	}
+	return ctx
+}
+
+// savingTokenSource refreshes the user's token when it expires, and saves the
+// refreshed token.  Before refreshing, it loads the token from the store, so
+// that it uses a token refreshed by another client of the same user, and stops
+// working once the user has logged out.
+type savingTokenSource struct {
+	m      *Manager
+	userID string
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() {
+		return s.token, nil
+	}
+
+	ctx := s.m.background()
+	s.m.saveMu.RLock()
+	defer s.m.saveMu.RUnlock()
+	stored, err := s.m.store.Load(ctx, s.userID)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.m.auth.RefreshToken(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+	if t.AccessToken != stored.AccessToken {
+		if err := s.m.store.Save(ctx, s.userID, t); err != nil && s.m.onSaveError != nil {
+			s.m.onSaveError(s.userID, err)
+		}
+	}
+	s.token = t
+	return t, nil
+}
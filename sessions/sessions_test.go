@@ -0,0 +1,191 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/zmb3/spotify/v2"
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
+)
+
+func TestManager(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"id": "wizzler"}`)
+	}))
+	defer server.Close()
+
+	store := NewMemoryStore()
+	m := New(spotifyauth.New(), store, WithClientOptions(spotify.WithBaseURL(server.URL+"/")))
+	ctx := context.Background()
+
+	userID, _, err := m.Login(ctx, &oauth2.Token{AccessToken: "access", TokenType: "Bearer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID != "wizzler" {
+		t.Errorf("Expected wizzler, got %s", userID)
+	}
+	if token, err := store.Load(ctx, "wizzler"); err != nil || token.AccessToken != "access" {
+		t.Errorf("Expected the token to be saved, got %v, %v", token, err)
+	}
+
+	client, err := m.Client(ctx, "wizzler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := m.Client(ctx, "wizzler"); again != client {
+		t.Error("Expected the client to be reused")
+	}
+
+	if err := m.Logout(ctx, "wizzler"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Client(ctx, "wizzler"); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Expected ErrNoSession, got %v", err)
+	}
+}
+
+// accountsTransport sends the requests made to the Spotify Accounts Service
+// to server instead.
+type accountsTransport struct {
+	server *url.URL
+	next   http.RoundTripper
+}
+
+func (t accountsTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Host == "accounts.spotify.com" {
+		r = r.Clone(r.Context())
+		r.URL.Scheme = t.server.Scheme
+		r.URL.Host = t.server.Host
+	}
+	return t.next.RoundTrip(r)
+}
+
+func TestManagerRefresh(t *testing.T) {
+	var refreshes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/token" {
+			atomic.AddInt32(&refreshes, 1)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token": "fresh", "token_type": "Bearer", "expires_in": 3600}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"id": "wizzler"}`)
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	httpClient := &http.Client{Transport: accountsTransport{server: serverURL, next: http.DefaultTransport}}
+
+	store := NewMemoryStore()
+	m := New(spotifyauth.New(), store,
+		WithHTTPClient(httpClient),
+		WithClientOptions(spotify.WithBaseURL(server.URL+"/")),
+	)
+	ctx := context.Background()
+	expired := func(access string) *oauth2.Token {
+		return &oauth2.Token{AccessToken: access, RefreshToken: "refresh", TokenType: "Bearer", Expiry: time.Now().Add(-time.Hour)}
+	}
+
+	// a refreshed token is saved
+	_ = store.Save(ctx, "wizzler", expired("old"))
+	client, err := m.Client(ctx, "wizzler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CurrentUser(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := store.Load(ctx, "wizzler"); err != nil || token.AccessToken != "fresh" || token.RefreshToken != "refresh" {
+		t.Errorf("Expected the refreshed token to be saved, got %+v, %v", token, err)
+	}
+
+	// a client handed out before Logout doesn't refresh or save its token
+	_ = store.Save(ctx, "mallory", expired("old"))
+	client, err = m.Client(ctx, "mallory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Logout(ctx, "mallory"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CurrentUser(ctx); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Expected ErrNoSession after Logout, got %v", err)
+	}
+	if token, err := store.Load(ctx, "mallory"); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Expected nothing to be saved after Logout, got %+v", token)
+	}
+	if refreshes != 1 {
+		t.Errorf("Expected 1 refresh, got %d", refreshes)
+	}
+}
+
+func TestManagerMaxClients(t *testing.T) {
+	store := NewMemoryStore()
+	m := New(spotifyauth.New(), store, WithMaxClients(1))
+	ctx := context.Background()
+	_ = store.Save(ctx, "a", &oauth2.Token{AccessToken: "a"})
+	_ = store.Save(ctx, "b", &oauth2.Token{AccessToken: "b"})
+
+	a, _ := m.Client(ctx, "a")
+	if again, _ := m.Client(ctx, "a"); again != a {
+		t.Error("Expected the client to be reused")
+	}
+	if _, err := m.Client(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.clients) != 1 {
+		t.Errorf("Expected 1 client to be kept, got %d", len(m.clients))
+	}
+	if again, _ := m.Client(ctx, "a"); again == a {
+		t.Error("Expected the least recently used client to be evicted")
+	}
+}
+
+func TestEncryptedStore(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemoryStore()
+	store, err := NewEncryptedStore(inner, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer"}
+	if err := store.Save(ctx, "wizzler", token); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := inner.Load(ctx, "wizzler")
+	if stored.RefreshToken != "" || stored.AccessToken == "access" {
+		t.Errorf("Expected the token to be encrypted, got %+v", stored)
+	}
+
+	loaded, err := store.Load(ctx, "wizzler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.AccessToken != "access" || loaded.RefreshToken != "refresh" {
+		t.Errorf("Unexpected token %+v", loaded)
+	}
+
+	// ciphertexts are bound to the user
+	_ = inner.Save(ctx, "mallory", stored)
+	if _, err := store.Load(ctx, "mallory"); err == nil {
+		t.Error("Expected an error when loading another user's token")
+	}
+}
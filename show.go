@@ -2,7 +2,11 @@ package spotify
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -54,7 +58,7 @@ type SimpleShow struct {
 
 	// The cover art for the show in various sizes,
 	// widest first.
-	Images []Image `json:"images"`
+	Images Images `json:"images"`
 
 	// True if all of the show’s episodes are hosted outside
 	// of Spotify’s CDN. This field might be null in some cases.
@@ -108,7 +112,7 @@ type EpisodePage struct {
 	ID ID `json:"id"`
 
 	// The cover art for the episode in various sizes, widest first.
-	Images []Image `json:"images"`
+	Images Images `json:"images"`
 
 	// True if the episode is hosted outside of Spotify’s CDN.
 	IsExternallyHosted bool `json:"is_externally_hosted"`
@@ -157,6 +161,40 @@ type ResumePointObject struct {
 	ResumePositionMs Numeric `json:"resume_position_ms"`
 }
 
+// TimeDuration returns the episode's duration as a [time.Duration] value.
+func (e *EpisodePage) TimeDuration() time.Duration {
+	return time.Duration(e.Duration_ms) * time.Millisecond
+}
+
+// RemainingTime returns how much of the episode the user has left to listen
+// to, based on [EpisodePage.ResumePoint].  It returns zero if the episode has
+// been fully played.
+func (e *EpisodePage) RemainingTime() time.Duration {
+	if e.ResumePoint.FullyPlayed {
+		return 0
+	}
+	remaining := e.TimeDuration() - e.ResumePoint.Position()
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// DownloadPreview downloads the episode's 30 second preview and writes its
+// (MP3) data to dst.  It returns [ErrNoPreview] if the episode has no preview.
+func (e *EpisodePage) DownloadPreview(ctx context.Context, dst io.Writer) error {
+	if e.AudioPreviewURL == "" {
+		return ErrNoPreview
+	}
+	return download(ctx, http.DefaultClient, e.AudioPreviewURL, dst, "audio/")
+}
+
+// Position returns the user's most recent position in the episode as a
+// [time.Duration] value.
+func (r ResumePointObject) Position() time.Duration {
+	return time.Duration(r.ResumePositionMs) * time.Millisecond
+}
+
 // ReleaseDateTime converts [EpisodePage.ReleaseDate] to a [time.Time].
 // All of the fields in the result may not be valid.  For example, if
 // [EpisodePage.ReleaseDatePrecision] is "month", then only the month and year
@@ -182,14 +220,17 @@ func (e *EpisodePage) ReleaseDateTime() time.Time {
 //
 // [specific show]: https://developer.spotify.com/documentation/web-api/reference/get-a-show
 func (c *Client) GetShow(ctx context.Context, id ID, opts ...RequestOption) (*FullShow, error) {
-	spotifyURL := c.baseURL + "shows/" + string(id)
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
-		spotifyURL += "?" + params
+	id, err := resolveID(id, "show")
+	if err != nil {
+		return nil, err
 	}
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	spotifyURL := c.apiURL(o.urlParams, "shows/", string(id))
 
 	var result FullShow
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -201,16 +242,34 @@ func (c *Client) GetShow(ctx context.Context, id ID, opts ...RequestOption) (*Fu
 //
 // Supported options: [Market], [Limit], [Offset].
 //
+// Deprecated: use [Client.GetShowEpisodesByID], which accepts an [ID] like the
+// rest of the package.
+//
 // [episode information]: https://developer.spotify.com/documentation/web-api/reference/get-a-shows-episodes
 func (c *Client) GetShowEpisodes(ctx context.Context, id string, opts ...RequestOption) (*SimpleEpisodePage, error) {
-	spotifyURL := c.baseURL + "shows/" + id + "/episodes"
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
-		spotifyURL += "?" + params
+	return c.GetShowEpisodesByID(ctx, ID(id), opts...)
+}
+
+// GetShowEpisodesByID retrieves paginated [episode information] about a specific show.
+//
+// Supported options: [Market], [Limit], [Offset].
+//
+// [episode information]: https://developer.spotify.com/documentation/web-api/reference/get-a-shows-episodes
+func (c *Client) GetShowEpisodesByID(ctx context.Context, id ID, opts ...RequestOption) (*SimpleEpisodePage, error) {
+	id, err := resolveID(id, "show")
+	if err != nil {
+		return nil, err
 	}
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	spotifyURL := c.apiURL(o.urlParams, "shows/", string(id), "/episodes")
 
 	var result SimpleEpisodePage
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -218,11 +277,117 @@ func (c *Client) GetShowEpisodes(ctx context.Context, id string, opts ...Request
 	return &result, nil
 }
 
+// GetAllShowEpisodes retrieves every episode of a show, following the paging
+// links returned by [Client.GetShowEpisodesByID] until the last page is reached.
+//
+// Supported options: [Market], [Limit], [Offset].
+func (c *Client) GetAllShowEpisodes(ctx context.Context, id ID, opts ...RequestOption) ([]EpisodePage, error) {
+	page, err := c.GetShowEpisodesByID(ctx, id, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	episodes := make([]EpisodePage, 0, page.Total)
+	for {
+		episodes = append(episodes, page.Episodes...)
+		err = c.NextPage(ctx, page)
+		if errors.Is(err, ErrNoMorePages) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return episodes, nil
+}
+
+// GetEpisodesInProgress returns the episodes that the current user started
+// but hasn't finished, for a "continue listening" view.  Candidates are the
+// user's saved episodes, and the latest 50 episodes of each of the user's
+// saved shows.  The Web API doesn't report when an episode was last played,
+// so the episodes are sorted by release date, newest first.
+//
+// Resume points are only reported if the client was granted the
+// "user-read-playback-position" scope; without it, no episodes are returned.
+// Reading the user's saved episodes and shows requires [ScopeUserLibraryRead].
+//
+// Supported options: [Market].
+func (c *Client) GetEpisodesInProgress(ctx context.Context, opts ...RequestOption) ([]EpisodePage, error) {
+	seen := make(map[ID]bool)
+	var episodes []EpisodePage
+	add := func(e EpisodePage) {
+		if seen[e.ID] || e.ResumePoint.FullyPlayed || e.ResumePoint.ResumePositionMs <= 0 {
+			return
+		}
+		seen[e.ID] = true
+		episodes = append(episodes, e)
+	}
+
+	saved, err := c.savedEpisodes(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range saved {
+		add(e)
+	}
+
+	shows, err := c.CurrentUsersShowsAll(ctx, nil, Limit(50))
+	if err != nil {
+		return nil, err
+	}
+	for _, show := range shows {
+		page, err := c.GetShowEpisodesByID(ctx, show.ID, append([]RequestOption{Limit(50)}, opts...)...)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range page.Episodes {
+			// episodes listed by show don't include the show
+			e.Show = show.SimpleShow
+			add(e)
+		}
+	}
+
+	sort.SliceStable(episodes, func(i, j int) bool {
+		return episodes[i].ReleaseDateTime().After(episodes[j].ReleaseDateTime())
+	})
+	return episodes, nil
+}
+
+// savedEpisodes returns every episode saved in the current user's library.
+func (c *Client) savedEpisodes(ctx context.Context, opts ...RequestOption) ([]EpisodePage, error) {
+	o := processOptions(append([]RequestOption{Limit(50)}, opts...)...)
+	ctx = o.context(ctx)
+	spotifyURL := c.apiURL(o.urlParams, "me/episodes")
+
+	var episodes []EpisodePage
+	for spotifyURL != "" {
+		var page struct {
+			Items []struct {
+				Episode EpisodePage `json:"episode"`
+			} `json:"items"`
+			Next string `json:"next"`
+		}
+		if err := c.get(ctx, spotifyURL, &page); err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			episodes = append(episodes, item.Episode)
+		}
+		spotifyURL = page.Next
+	}
+	return episodes, nil
+}
+
 // SaveShowsForCurrentUser [saves one or more shows] to current Spotify user's library.
 //
 // [saves one or more shows]: https://developer.spotify.com/documentation/web-api/reference/save-shows-user
 func (c *Client) SaveShowsForCurrentUser(ctx context.Context, ids []ID) error {
-	spotifyURL := c.baseURL + "me/shows?ids=" + strings.Join(toStringSlice(ids), ",")
+	ids, err := resolveIDs(ids, "show")
+	if err != nil {
+		return err
+	}
+	spotifyURL := c.apiURL(url.Values{"ids": {joinIDs(ids)}}, "me/shows")
 	req, err := http.NewRequestWithContext(ctx, http.MethodPut, spotifyURL, nil)
 	if err != nil {
 		return err
@@ -233,16 +398,31 @@ func (c *Client) SaveShowsForCurrentUser(ctx context.Context, ids []ID) error {
 
 // GetEpisode gets an [episode] from a show.
 //
+// Deprecated: use [Client.GetEpisodeByID], which accepts an [ID] like the rest
+// of the package.
+//
 // [episode]: https://developer.spotify.com/documentation/web-api/reference/get-an-episode
 func (c *Client) GetEpisode(ctx context.Context, id string, opts ...RequestOption) (*EpisodePage, error) {
-	spotifyURL := c.baseURL + "episodes/" + id
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
-		spotifyURL += "?" + params
+	return c.GetEpisodeByID(ctx, ID(id), opts...)
+}
+
+// GetEpisodeByID gets an [episode] from a show.
+//
+// Supported options: [Market].
+//
+// [episode]: https://developer.spotify.com/documentation/web-api/reference/get-an-episode
+func (c *Client) GetEpisodeByID(ctx context.Context, id ID, opts ...RequestOption) (*EpisodePage, error) {
+	id, err := resolveID(id, "episode")
+	if err != nil {
+		return nil, err
 	}
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	spotifyURL := c.apiURL(o.urlParams, "episodes/", string(id))
 
 	var result EpisodePage
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -3,8 +3,11 @@ package spotify
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestGetShow(t *testing.T) {
@@ -42,6 +45,33 @@ func TestGetShowEpisodes(t *testing.T) {
 	}
 }
 
+func TestGetAllShowEpisodes(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/shows/1234/episodes" {
+			t.Error("Unexpected path:", r.URL.Path)
+		}
+		if r.URL.Query().Get("offset") == "1" {
+			fmt.Fprint(w, `{"items": [{"id": "2", "type": "episode"}], "limit": 1, "offset": 1, "total": 2, "next": null}`)
+			return
+		}
+		fmt.Fprintf(w, `{"items": [{"id": "1", "type": "episode"}], "limit": 1, "offset": 0, "total": 2, "next": "%s/shows/1234/episodes?offset=1&limit=1"}`, server.URL)
+	}))
+	defer server.Close()
+
+	c := &Client{http: http.DefaultClient, baseURL: server.URL + "/"}
+	episodes, err := c.GetAllShowEpisodes(context.Background(), "1234", Limit(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(episodes) != 2 {
+		t.Fatalf("Expected 2 episodes, got %d", len(episodes))
+	}
+	if episodes[0].ID != "1" || episodes[1].ID != "2" {
+		t.Error("Invalid data:", episodes[0].ID, episodes[1].ID)
+	}
+}
+
 func TestSaveShowsForCurrentUser(t *testing.T) {
 	c, s := testClient(http.StatusOK, new(bytes.Buffer), func(req *http.Request) {
 		if ids := req.URL.Query().Get("ids"); ids != "1,2" {
@@ -82,3 +112,78 @@ func TestGetEpisode(t *testing.T) {
 		t.Error("Invalid data:", r.ID)
 	}
 }
+
+func TestGetEpisodeByID(t *testing.T) {
+	c, s := testClientFile(http.StatusOK, "test_data/get_episode.txt", func(req *http.Request) {
+		if got := req.URL.RequestURI(); got != "/episodes/2DSKnz9Hqm1tKimcXqcMJD?market=SE" {
+			t.Error("Unexpected request:", got)
+		}
+	})
+	defer s.Close()
+
+	r, err := c.GetEpisodeByID(context.Background(), "spotify:episode:2DSKnz9Hqm1tKimcXqcMJD", Market("SE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != "2DSKnz9Hqm1tKimcXqcMJD" || r.Type != "episode" {
+		t.Error("Invalid data:", r.ID)
+	}
+}
+
+func TestEpisodeDurations(t *testing.T) {
+	e := EpisodePage{
+		Duration_ms: 90000,
+		ResumePoint: ResumePointObject{ResumePositionMs: 30000},
+	}
+	if d := e.TimeDuration(); d != 90*time.Second {
+		t.Error("Invalid duration:", d)
+	}
+	if p := e.ResumePoint.Position(); p != 30*time.Second {
+		t.Error("Invalid position:", p)
+	}
+	if r := e.RemainingTime(); r != time.Minute {
+		t.Error("Invalid remaining time:", r)
+	}
+
+	e.ResumePoint.FullyPlayed = true
+	if r := e.RemainingTime(); r != 0 {
+		t.Error("Expected no remaining time, got", r)
+	}
+}
+
+func TestGetEpisodesInProgress(t *testing.T) {
+	episode := func(id, released string, position int, played bool) string {
+		return fmt.Sprintf(`{"id": %q, "duration_ms": 60000, "release_date": %q, "release_date_precision": "day",
+			"resume_point": {"fully_played": %t, "resume_position_ms": %d}}`, id, released, played, position)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/me/episodes":
+			fmt.Fprintf(w, `{"items": [{"episode": %s}, {"episode": %s}], "next": null}`,
+				episode("e1", "2023-01-01", 1000, false),
+				episode("e2", "2023-02-01", 0, false))
+		case "/me/shows":
+			fmt.Fprint(w, `{"items": [{"show": {"id": "s1", "name": "Show"}}], "next": null, "total": 1}`)
+		case "/shows/s1/episodes":
+			fmt.Fprintf(w, `{"items": [%s, %s, %s], "next": null}`,
+				episode("e3", "2023-03-01", 5000, false),
+				episode("e4", "2023-04-01", 60000, true),
+				episode("e1", "2023-01-01", 1000, false))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	episodes, err := client.GetEpisodesInProgress(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(episodes) != 2 || episodes[0].ID != "e3" || episodes[1].ID != "e1" {
+		t.Fatalf("Expected e3 and e1, got %v", episodes)
+	}
+	if episodes[0].Show.Name != "Show" {
+		t.Errorf("Expected the show to be set, got %q", episodes[0].Show.Name)
+	}
+}
@@ -4,13 +4,16 @@ package spotify
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -30,16 +33,60 @@ const (
 	// defaultRetryDurationS helps us fix an apparent server bug whereby we will
 	// be told to retry but not be given a wait-interval.
 	defaultRetryDuration = time.Second * 5
+	// maxRetryDuration caps the wait requested by a Retry-After header, so that
+	// a malformed or far-off value can't block a request indefinitely.
+	maxRetryDuration = time.Minute * 10
+	// minRetryDateDuration is the shortest wait for a Retry-After header given
+	// as an HTTP-date, so that clock skew doesn't cause immediate retries.
+	minRetryDateDuration = time.Second
+
+	// rateLimitExceededStatusCode is the code that the server returns when our
+	// request frequency is too high.
+	rateLimitExceededStatusCode = 429
 )
 
 // Client is a client for working with the Spotify Web API.
 // It is best to create this using spotify.New()
 type Client struct {
-	http    *http.Client
-	baseURL string
+	http       *http.Client
+	baseURL    string
+	apiVersion string
+
+	autoRetry         bool
+	maxAttempts       int
+	backoff           time.Duration
+	acceptLanguage    string
+	canonicalTrackIDs bool
+
+	skipOptionValidation bool
+
+	onResponseHeader ResponseHeaderFunc
+	capturedHeaders  []string
+
+	cache    CacheBackend
+	cacheTTL time.Duration
+
+	playlistCache    CacheBackend
+	playlistCacheTTL time.Duration
+
+	dryRun    bool
+	dryRunLog io.Writer
+
+	metrics Metrics
 
-	autoRetry      bool
-	acceptLanguage string
+	debug io.Writer
+
+	prefetcher *prefetcher
+
+	quota     *Quota
+	quotaUser string
+
+	entities *entityCache
+
+	offline    CacheBackend
+	offlineTTL time.Duration
+
+	userMarket *userMarket
 }
 
 type ClientOption func(client *Client)
@@ -51,6 +98,23 @@ func WithRetry(shouldRetry bool) ClientOption {
 	}
 }
 
+type noRetryKey struct{}
+
+// NoRetry returns a context that disables automatic retries for the requests
+// made with it, even if the client was configured with [WithRetry].  Use it
+// for latency-sensitive calls, such as player commands triggered by a button,
+// that should fail fast with a [RateLimitError] instead of waiting:
+//
+//	err := client.Next(spotify.NoRetry(ctx))
+func NoRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// retries reports whether requests made with ctx are retried automatically.
+func (c *Client) retries(ctx context.Context) bool {
+	return c.autoRetry && ctx.Value(noRetryKey{}) == nil
+}
+
 // WithBaseURL provides an alternative base url to use for requests to the Spotify API. This can be used to connect to a
 // staging or other alternative environment.
 func WithBaseURL(url string) ClientOption {
@@ -59,6 +123,44 @@ func WithBaseURL(url string) ClientOption {
 	}
 }
 
+// WithAPIVersion configures the version of the Spotify Web API that the client
+// targets, such as "v1".  The version replaces the last element of the base
+// URL's path, so it can be combined with [WithBaseURL] in any order.
+func WithAPIVersion(version string) ClientOption {
+	return func(client *Client) {
+		client.apiVersion = version
+	}
+}
+
+// ResponseHeaderFunc receives the headers of a response from the Spotify API,
+// along with the request that it answers.
+type ResponseHeaderFunc func(req *http.Request, header http.Header)
+
+// WithResponseHeaderCapture configures the client to call fn with the headers
+// of every response it receives, including responses to requests that are
+// retried.  If names are given, only those headers are passed to fn; otherwise
+// fn receives all of them.  This is useful to record headers such as ETag or
+// Retry-After for caching layers and support requests.  fn must not modify
+// the request.
+func WithResponseHeaderCapture(fn ResponseHeaderFunc, names ...string) ClientOption {
+	return func(client *Client) {
+		client.onResponseHeader = fn
+		client.capturedHeaders = names
+	}
+}
+
+// WithDryRun configures the client to only send GET requests.  Requests that
+// would modify data, such as adding tracks to a playlist or starting playback,
+// are not sent; instead, they are described on out (if it isn't nil) and the
+// method returns a [DryRunError].  This is useful for testing scripts against
+// a real account.
+func WithDryRun(out io.Writer) ClientOption {
+	return func(client *Client) {
+		client.dryRun = true
+		client.dryRunLog = out
+	}
+}
+
 // WithAcceptLanguage configures the client to provide the accept language header on all requests.
 func WithAcceptLanguage(lang string) ClientOption {
 	return func(client *Client) {
@@ -66,6 +168,30 @@ func WithAcceptLanguage(lang string) ClientOption {
 	}
 }
 
+// WithOptionValidation configures whether the client checks the values of the
+// [Limit] and [Offset] options against the bounds documented for each endpoint
+// before sending a request.  Validation is enabled by default; disable it if
+// Spotify starts accepting values that this package doesn't know about yet.
+func WithOptionValidation(validate bool) ClientOption {
+	return func(client *Client) {
+		client.skipOptionValidation = !validate
+	}
+}
+
+// WithCanonicalTrackIDs configures the client to resolve [Track Relinking] in
+// playlist and library responses.  When enabled, tracks that carry linked_from
+// information report the ID, URI and endpoint of the originally requested track,
+// so that the same song is not counted twice under different market-specific
+// IDs.  [FullTrack.LinkedFrom] is left untouched, so relinked tracks can still
+// be identified.
+//
+// [Track Relinking]: https://developer.spotify.com/documentation/general/guides/track-relinking-guide/
+func WithCanonicalTrackIDs(canonical bool) ClientOption {
+	return func(client *Client) {
+		client.canonicalTrackIDs = canonical
+	}
+}
+
 // New returns a client for working with the Spotify Web API.
 // The provided httpClient must provide Authentication with the requests.
 // The auth package may be used to generate a suitable client.
@@ -74,12 +200,58 @@ func New(httpClient *http.Client, opts ...ClientOption) *Client {
 		http:    httpClient,
 		baseURL: "https://api.spotify.com/v1/",
 	}
+	c.apply(opts)
 
+	return c
+}
+
+// With returns a copy of the client with opts applied on top of its current
+// settings, for example to make requests in another language with
+// [WithAcceptLanguage].  The copy shares the client's HTTP client, and so its
+// transport and token, as well as the cache configured with [WithCache].
+// The original client isn't modified.
+func (c *Client) With(opts ...ClientOption) *Client {
+	derived := *c
+	derived.apply(opts)
+	// prefetched pages are keyed by URL only, so they mustn't be shared with
+	// a client that requests another language
+	if derived.prefetcher != nil && derived.prefetcher == c.prefetcher && derived.acceptLanguage != c.acceptLanguage {
+		derived.prefetcher = &prefetcher{pages: make(map[string]*prefetchedPage)}
+	}
+	return &derived
+}
+
+func (c *Client) apply(opts []ClientOption) {
 	for _, opt := range opts {
 		opt(c)
 	}
+	if c.apiVersion != "" {
+		c.baseURL = versionedURL(c.baseURL, c.apiVersion)
+	}
+}
 
-	return c
+// NewFromTokenSource returns a client that authenticates its requests with the
+// tokens returned by src, for applications that manage tokens themselves.
+// The tokens are cached until they expire, and [Client.Token] reports the
+// current one.  ctx is used to make the underlying HTTP client, as described
+// in [oauth2.NewClient]; it should outlive the client.
+func NewFromTokenSource(ctx context.Context, src oauth2.TokenSource, opts ...ClientOption) *Client {
+	return New(oauth2.NewClient(ctx, oauth2.ReuseTokenSource(nil, src)), opts...)
+}
+
+// versionedURL replaces the last element of base's path with version.  If
+// base has an empty path, the version is appended instead.
+func versionedURL(base, version string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base
+	}
+	p := strings.TrimSuffix(u.Path, "/")
+	if i := strings.LastIndex(p, "/"); i >= 0 {
+		p = p[:i]
+	}
+	u.Path = p + "/" + version + "/"
+	return u.String()
 }
 
 // URI identifies an artist, album, track, or category.  For example,
@@ -98,7 +270,28 @@ func (id *ID) String() string {
 type Numeric int
 
 // UnmarshalJSON unmarshals a JSON number (float or int) into the Numeric type.
+// Spotify occasionally sends numbers as strings, so quoted numbers are accepted
+// too.  A null value or an empty string leaves n unchanged.
 func (n *Numeric) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("spotify: invalid number %q", s)
+		}
+		*n = Numeric(int(f))
+		return nil
+	}
 	var f float64
 	if err := json.Unmarshal(data, &f); err != nil {
 		return err
@@ -129,7 +322,21 @@ type Image struct {
 
 // Download downloads the image and writes its data to the specified io.Writer.
 func (i Image) Download(dst io.Writer) error {
-	resp, err := http.Get(i.URL)
+	return i.DownloadContext(context.Background(), dst, nil)
+}
+
+// DownloadContext is like [Image.Download], but the request is bound to ctx and
+// is made with the specified HTTP client.  If client is nil,
+// [net/http.DefaultClient] is used.
+func (i Image) DownloadContext(ctx context.Context, dst io.Writer, client *http.Client) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, i.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -142,50 +349,277 @@ func (i Image) Download(dst io.Writer) error {
 	return err
 }
 
+// Images is a list of renditions of the same image in various sizes, as found
+// on albums, artists, playlists and other entities.
+type Images []Image
+
+// Largest returns the widest image in the list, or the zero Image if
+// the list is empty.
+func (imgs Images) Largest() Image {
+	var largest Image
+	for _, img := range imgs {
+		if img.Width > largest.Width || largest.URL == "" {
+			largest = img
+		}
+	}
+	return largest
+}
+
+// Closest returns the image whose width is closest to the specified width,
+// preferring the larger image when two are equally close.  It returns the
+// zero Image if the list is empty.  Images of unknown size are only returned
+// if no other image is available.
+func (imgs Images) Closest(width int) Image {
+	var closest Image
+	bestDiff := -1
+	for _, img := range imgs {
+		if img.Width == 0 {
+			if closest.URL == "" {
+				closest = img
+			}
+			continue
+		}
+		diff := int(img.Width) - width
+		if diff < 0 {
+			diff = -diff
+		}
+		if bestDiff < 0 || diff < bestDiff || (diff == bestDiff && img.Width > closest.Width) {
+			closest = img
+			bestDiff = diff
+		}
+	}
+	return closest
+}
+
+// ErrNoPreview is the error returned when attempting to download the preview
+// of an item that doesn't have one.
+var ErrNoPreview = errors.New("spotify: no preview available")
+
+// download fetches the resource at url and writes its data to dst.  If
+// mediaType is not empty, the response's Content-Type must start with it.
+func download(ctx context.Context, client *http.Client, url string, dst io.Writer, mediaType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("spotify: couldn't download %s - HTTP %d", url, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); mediaType != "" && ct != "" && !strings.HasPrefix(ct, mediaType) {
+		return fmt.Errorf("spotify: unexpected content type %q, wanted %s", ct, mediaType)
+	}
+	_, err = io.Copy(dst, resp.Body)
+	return err
+}
+
 // Error represents an error returned by the Spotify Web API.
 type Error struct {
 	// A short description of the error.
 	Message string `json:"message"`
 	// The HTTP status code.
 	Status int `json:"status"`
+	// Reason is a code that some endpoints, such as the player endpoints,
+	// send along with the message, for example "NO_ACTIVE_DEVICE".
+	Reason string `json:"reason"`
 	// RetryAfter contains the time before which client should not retry a
 	// rate-limited request, calculated from the Retry-After header, when present.
 	RetryAfter time.Time `json:"-"`
+	// Method and Path identify the request that failed, when known.
+	Method string `json:"-"`
+	Path   string `json:"-"`
 }
 
 func (e Error) Error() string {
-	return e.Message
+	if e.Method == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%s %s)", e.Message, e.Method, e.Path)
+}
+
+// RateLimitError is returned when a request is rejected because the client
+// has exceeded Spotify's rate limit.  It wraps the [Error] returned by the API,
+// so it can be inspected with either errors.As(err, &spotify.RateLimitError{})
+// or errors.As(err, &spotify.Error{}).
+type RateLimitError struct {
+	Err Error
+	// RetryAfter is how long the client should wait before retrying, as
+	// requested by the Retry-After header.
+	RetryAfter time.Duration
+}
+
+func (e RateLimitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying [Error].
+func (e RateLimitError) Unwrap() error {
+	return e.Err
 }
 
-// decodeError decodes an Error from an io.Reader.
+// Wait blocks until RetryAfter has elapsed or ctx is done, in which case it
+// returns ctx.Err().
+func (e RateLimitError) Wait(ctx context.Context) error {
+	t := time.NewTimer(e.RetryAfter)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// RetryRateLimited calls f until it succeeds or fails with an error other than
+// a [RateLimitError], waiting for the delay requested by Spotify in between.
+// It is useful for helpers that make many requests with a client that wasn't
+// configured with [WithRetry].  Calls made with a context returned by
+// [NoRetry] aren't retried.
+//
+//	err := spotify.RetryRateLimited(ctx, func() error {
+//		var err error
+//		albums, err = client.GetAlbums(ctx, ids)
+//		return err
+//	})
+func RetryRateLimited(ctx context.Context, f func() error) error {
+	for {
+		err := f()
+		var rateLimited RateLimitError
+		if errors.As(err, &rateLimited) && ctx.Err() == nil && ctx.Value(noRetryKey{}) == nil {
+			if err := rateLimited.Wait(ctx); err == nil {
+				continue
+			}
+		}
+		return err
+	}
+}
+
+// ErrDryRun is matched by errors.Is for requests that were not sent because
+// the client was configured with [WithDryRun].
+var ErrDryRun = errors.New("spotify: request not sent in dry-run mode")
+
+// DryRunError describes a request that was not sent because the client was
+// configured with [WithDryRun].
+type DryRunError struct {
+	Method string
+	URL    string
+	Body   []byte
+}
+
+func (e DryRunError) Error() string {
+	return fmt.Sprintf("spotify: dry run: %s %s not sent", e.Method, e.URL)
+}
+
+// Is reports whether target is [ErrDryRun].
+func (e DryRunError) Is(target error) bool {
+	return target == ErrDryRun
+}
+
+// dryRunMaxLogBody is the size of the longest request body that is logged in
+// dry-run mode, so that image uploads don't flood the log.
+const dryRunMaxLogBody = 1024
+
+// skipRequest logs a request that isn't sent in dry-run mode.
+func (c *Client) skipRequest(req *http.Request) error {
+	var body []byte
+	if req.GetBody != nil {
+		if rc, err := req.GetBody(); err == nil {
+			body, _ = io.ReadAll(rc)
+			rc.Close()
+		}
+	}
+	if c.dryRunLog != nil {
+		logged := body
+		if len(logged) > dryRunMaxLogBody {
+			logged = append(logged[:dryRunMaxLogBody:dryRunMaxLogBody], "..."...)
+		}
+		fmt.Fprintf(c.dryRunLog, "spotify: dry run: %s %s %s\n", req.Method, req.URL, logged)
+	}
+	return DryRunError{Method: req.Method, URL: req.URL.String(), Body: body}
+}
+
+// BatchError is returned by methods that split their input into several
+// requests, when some of those requests fail.  The IDs and URIs that aren't
+// listed in any failure were processed successfully.
+type BatchError struct {
+	Failures []BatchFailure
+}
+
+// BatchFailure describes a request made on behalf of a batched method that
+// failed.
+type BatchFailure struct {
+	// IDs are the IDs that weren't processed because of the failure.
+	IDs []ID
+	// URIs are the URIs that weren't processed because of the failure, for
+	// methods that take URIs.
+	URIs []URI
+	Err  error
+}
+
+func (e *BatchError) Error() string {
+	if len(e.Failures) == 1 {
+		return e.Failures[0].Err.Error()
+	}
+	return fmt.Sprintf("spotify: %d batches failed, first error: %v", len(e.Failures), e.Failures[0].Err)
+}
+
+// Unwrap returns the error of the first failure, so that errors.Is and
+// errors.As can be used to inspect it.
+func (e *BatchError) Unwrap() error {
+	if len(e.Failures) == 0 {
+		return nil
+	}
+	return e.Failures[0].Err
+}
+
+// decodeError decodes an Error from an http.Response, wrapping it in a
+// [RateLimitError] if the response reports that the rate limit was exceeded,
+// or in an [InsufficientScopeError] if the token is missing a scope.
 func decodeError(resp *http.Response) error {
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
+	err := decodeAPIError(resp)
+	var e Error
+	if resp.StatusCode == rateLimitExceededStatusCode && errors.As(err, &e) {
+		return RateLimitError{Err: e, RetryAfter: retryDuration(resp)}
+	}
+	if errors.As(err, &e) && isScopeError(e) {
+		return InsufficientScopeError{Err: e, Scopes: scopesForRequest(e.Method, e.Path)}
+	}
+	return err
+}
+
+func decodeAPIError(resp *http.Response) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
 		return err
 	}
+	responseBody := buf.Bytes()
 	if ctHeader := resp.Header.Get("Content-Type"); ctHeader == "" {
 		msg := string(responseBody)
 		if len(msg) == 0 {
 			msg = http.StatusText(resp.StatusCode)
 		}
 
-		return Error{
+		e := Error{
 			Message: msg,
 			Status:  resp.StatusCode,
 		}
+		e.setRequest(resp.Request)
+		return e
 	}
 
 	if len(responseBody) == 0 {
 		return fmt.Errorf("spotify: HTTP %d: %s (body empty)", resp.StatusCode, http.StatusText(resp.StatusCode))
 	}
 
-	buf := bytes.NewBuffer(responseBody)
-
 	var e struct {
 		E Error `json:"error"`
 	}
-	err = json.NewDecoder(buf).Decode(&e)
-	if err != nil {
+	if err := json.Unmarshal(responseBody, &e); err != nil {
 		return fmt.Errorf("spotify: couldn't decode error: (%d) [%s]", len(responseBody), responseBody)
 	}
 
@@ -199,13 +633,76 @@ func decodeError(resp *http.Response) error {
 		e.E.Message = fmt.Sprintf("spotify: unexpected HTTP %d: %s (empty error)",
 			resp.StatusCode, http.StatusText(resp.StatusCode))
 	}
-	if retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After")); retryAfter != 0 {
-		e.E.RetryAfter = time.Now().Add(time.Duration(retryAfter) * time.Second)
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok && retryAfter != 0 {
+		e.E.RetryAfter = time.Now().Add(retryAfter)
 	}
+	e.E.setRequest(resp.Request)
 
 	return e.E
 }
 
+// setRequest records the method and path of the request that caused the error.
+func (e *Error) setRequest(req *http.Request) {
+	if req == nil || req.URL == nil {
+		return
+	}
+	e.Method = req.Method
+	e.Path = req.URL.Path
+}
+
+// captureHeaders passes the headers of resp to the callback configured with
+// [WithResponseHeaderCapture], if any.
+func (c *Client) captureHeaders(resp *http.Response) {
+	if c.onResponseHeader == nil {
+		return
+	}
+	header := resp.Header.Clone()
+	if len(c.capturedHeaders) > 0 {
+		header = make(http.Header, len(c.capturedHeaders))
+		for _, name := range c.capturedHeaders {
+			if values := resp.Header.Values(name); len(values) > 0 {
+				header[http.CanonicalHeaderKey(name)] = values
+			}
+		}
+	}
+	c.onResponseHeader(resp.Request, header)
+}
+
+// decompress replaces the body of resp with a decompressing reader if the
+// response is gzip-encoded.  Requests made by the client always accept gzip,
+// so that compression is used even with transports that don't request it
+// themselves; net/http's transport only decompresses responses transparently
+// when it added the Accept-Encoding header on its own.
+func decompress(resp *http.Response) error {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err == io.EOF {
+		// empty body, e.g. 204 No Content
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("spotify: couldn't decompress response: %w", err)
+	}
+	resp.Body = gzipBody{Reader: gz, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b gzipBody) Close() error {
+	_ = b.Reader.Close()
+	return b.body.Close()
+}
+
 // shouldRetry determines whether the status code indicates that the
 // previous operation should be retried at a later time
 func shouldRetry(status int) bool {
@@ -222,6 +719,29 @@ func isFailure(code int, validCodes []int) bool {
 	return true
 }
 
+// do sends req, and returns the decompressed response.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.quota != nil {
+		if err := c.quota.acquire(req.Context(), c.quotaUser); err != nil {
+			return nil, err
+		}
+	}
+	c.dumpRequest(req)
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	c.observeRequest(req, resp, start)
+	if err != nil {
+		return nil, err
+	}
+	c.captureHeaders(resp)
+	if err := decompress(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	c.dumpResponse(resp)
+	return resp, nil
+}
+
 // `execute` executes a non-GET request. `needsStatus` describes other HTTP
 // status codes that will be treated as success. Note that we allow all 200s
 // even if there are additional success codes that represent success.
@@ -229,20 +749,26 @@ func (c *Client) execute(req *http.Request, result interface{}, needsStatus ...i
 	if c.acceptLanguage != "" {
 		req.Header.Set("Accept-Language", c.acceptLanguage)
 	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	addHeaders(req)
+	if c.dryRun && req.Method != http.MethodGet {
+		return c.skipRequest(req)
+	}
 	for {
-		resp, err := c.http.Do(req)
+		resp, err := c.do(req)
 		if err != nil {
 			return err
 		}
 		defer resp.Body.Close()
 
-		if c.autoRetry &&
+		if c.retries(req.Context()) &&
 			isFailure(resp.StatusCode, needsStatus) &&
 			shouldRetry(resp.StatusCode) {
 			select {
 			case <-req.Context().Done():
 				// If the context is cancelled, return the original error
 			case <-time.After(retryDuration(resp)):
+				c.countRetry(req, resp.StatusCode)
 				continue
 			}
 		}
@@ -256,7 +782,7 @@ func (c *Client) execute(req *http.Request, result interface{}, needsStatus ...i
 		}
 
 		if result != nil {
-			if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			if err := decodeJSON(resp.Body, result); err != nil {
 				return err
 			}
 		}
@@ -265,20 +791,111 @@ func (c *Client) execute(req *http.Request, result interface{}, needsStatus ...i
 	return nil
 }
 
+// retryDuration returns how long to wait before retrying the request that
+// received resp, as requested by its Retry-After header.
 func retryDuration(resp *http.Response) time.Duration {
-	raw := resp.Header.Get("Retry-After")
-	if raw == "" {
+	d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
 		return defaultRetryDuration
 	}
-	seconds, err := strconv.ParseInt(raw, 10, 32)
-	if err != nil {
-		return defaultRetryDuration
+	return d
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a number
+// of seconds or an HTTP-date, relative to now.  The result is capped at
+// maxRetryDuration.  A date that has already passed, usually because of clock
+// skew, results in a wait of minRetryDateDuration.
+func parseRetryAfter(raw string, now time.Time) (time.Duration, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, false
+	}
+	var d time.Duration
+	if seconds, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > int64(maxRetryDuration/time.Second) {
+			return maxRetryDuration, true
+		}
+		d = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(raw); err == nil {
+		d = date.Sub(now)
+		if d < minRetryDateDuration {
+			d = minRetryDateDuration
+		}
+	} else {
+		return 0, false
+	}
+	if d > maxRetryDuration {
+		d = maxRetryDuration
 	}
-	return time.Duration(seconds) * time.Second
+	return d, true
+}
+
+// apiURL returns the URL of the endpoint whose path, relative to the base URL,
+// is the concatenation of path, with query as its query string.  It builds the
+// URL in a single allocation, rather than formatting and concatenating it in
+// steps, as it is on the path of every request.
+func (c *Client) apiURL(query url.Values, path ...string) string {
+	var params string
+	if len(query) > 0 {
+		params = query.Encode()
+	}
+	n := len(c.baseURL) + 1 + len(params)
+	for _, p := range path {
+		n += len(p)
+	}
+
+	var b strings.Builder
+	b.Grow(n)
+	b.WriteString(c.baseURL)
+	for _, p := range path {
+		b.WriteString(p)
+	}
+	if params != "" {
+		b.WriteByte('?')
+		b.WriteString(params)
+	}
+	return b.String()
+}
+
+// joinIDs joins ids with commas, as expected by the ids query parameter.
+func joinIDs(ids []ID) string {
+	n := len(ids)
+	for _, id := range ids {
+		n += len(id)
+	}
+
+	var b strings.Builder
+	b.Grow(n)
+	for i, id := range ids {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(string(id))
+	}
+	return b.String()
 }
 
 func (c *Client) get(ctx context.Context, url string, result interface{}) error {
-	for {
+	url, err := c.addUserMarket(ctx, url)
+	if err != nil {
+		return err
+	}
+	key, cacheable := c.cacheKey(url)
+	cacheable = cacheable && !hasHeaders(ctx)
+	if cacheable {
+		if data, ok, err := c.cache.Get(key); err == nil && ok {
+			if err := json.Unmarshal(data, result); err == nil {
+				c.prefetchNext(ctx, result)
+				return nil
+			}
+		}
+	}
+	staleKey, keepStale := c.staleKey(ctx, url)
+
+	for attempt := 1; ; attempt++ {
 		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if c.acceptLanguage != "" {
 			req.Header.Set("Accept-Language", c.acceptLanguage)
@@ -286,37 +903,117 @@ func (c *Client) get(ctx context.Context, url string, result interface{}) error
 		if err != nil {
 			return err
 		}
-		resp, err := c.http.Do(req)
+		req.Header.Set("Accept-Encoding", "gzip")
+		addHeaders(req)
+		resp, err := c.do(req)
 		if err != nil {
+			if c.retryTransient(req, attempt, 0) {
+				continue
+			}
+			if keepStale && ctx.Err() == nil && c.serveStale(ctx, staleKey, result) {
+				return nil
+			}
 			return err
 		}
 
-		defer resp.Body.Close()
+		if isTransientStatus(resp.StatusCode) && c.retryTransient(req, attempt, resp.StatusCode) {
+			discardBody(resp)
+			continue
+		}
 
-		if resp.StatusCode == http.StatusTooManyRequests && c.autoRetry {
+		if resp.StatusCode == http.StatusTooManyRequests && c.retries(ctx) && (!keepStale || canWait(ctx, retryDuration(resp))) {
 			select {
 			case <-ctx.Done():
 				// If the context is cancelled, return the original error
 			case <-time.After(retryDuration(resp)):
+				c.countRetry(req, resp.StatusCode)
+				discardBody(resp)
 				continue
 			}
 		}
+		// this is the last attempt, so the body is only closed on return
+		defer resp.Body.Close()
 		if resp.StatusCode == http.StatusNoContent {
 			return nil
 		}
 		if resp.StatusCode != http.StatusOK {
+			if keepStale && isOfflineStatus(resp.StatusCode) && c.serveStale(ctx, staleKey, result) {
+				return nil
+			}
 			return decodeError(resp)
 		}
 
-		return json.NewDecoder(resp.Body).Decode(result)
+		if cacheable || keepStale {
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(data, result); err != nil {
+				return err
+			}
+			if cacheable {
+				_ = c.cache.Set(key, data, c.cacheTTL)
+			}
+			if keepStale {
+				_ = c.offline.Set(staleKey, data, c.offlineTTL)
+			}
+			c.prefetchNext(ctx, result)
+			return nil
+		}
+
+		if err := decodeJSON(resp.Body, result); err != nil {
+			return err
+		}
+		c.prefetchNext(ctx, result)
+		return nil
 	}
 }
 
+// discardBody drains and closes the body of a response that is about to be
+// retried, so that its connection can be reused by the next attempt.
+func discardBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// GetInto sends a GET request to the endpoint at path, relative to the client's
+// base URL, and decodes the response into result, which must be a pointer.
+// It is useful for decoding responses that were filtered with the [Fields]
+// option into your own, smaller types:
+//
+//	var playlist struct {
+//		Name   string `json:"name"`
+//		Tracks struct {
+//			Total int `json:"total"`
+//		} `json:"tracks"`
+//	}
+//	err := client.GetInto(ctx, "playlists/"+id, &playlist, spotify.Fields("name,tracks.total"))
+//
+// Options are added to any query parameters already present in path.
+func (c *Client) GetInto(ctx context.Context, path string, result interface{}, opts ...RequestOption) error {
+	spotifyURL := c.baseURL + strings.TrimPrefix(path, "/")
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	if params := o.urlParams.Encode(); params != "" {
+		if strings.Contains(spotifyURL, "?") {
+			spotifyURL += "&" + params
+		} else {
+			spotifyURL += "?" + params
+		}
+	}
+	return c.get(ctx, spotifyURL, result)
+}
+
 // NewReleases gets a list of new album releases featured in Spotify.
 // Supported options: Country, Limit, Offset
 func (c *Client) NewReleases(ctx context.Context, opts ...RequestOption) (albums *SimpleAlbumPage, err error) {
 	spotifyURL := c.baseURL + "browse/new-releases"
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	if params := o.urlParams.Encode(); params != "" {
 		spotifyURL += "?" + params
 	}
 
@@ -335,6 +1032,38 @@ func (c *Client) NewReleases(ctx context.Context, opts ...RequestOption) (albums
 	return &result, nil
 }
 
+// NewReleasesAll gets all of the new album releases featured in Spotify,
+// following the pages of [Client.NewReleases].  The pages of this endpoint are
+// wrapped in an object, so they can't be followed with [Client.NextPage].  If
+// progress isn't nil, it is called after each page.
+//
+// Supported options: [Country], [Limit], [Offset].
+func (c *Client) NewReleasesAll(ctx context.Context, progress ProgressFunc, opts ...RequestOption) ([]SimpleAlbum, error) {
+	page, err := c.NewReleases(ctx, append([]RequestOption{Limit(50)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	albums := make([]SimpleAlbum, 0, page.Total)
+	for {
+		albums = append(albums, page.Albums...)
+		if progress != nil {
+			progress(len(albums), int(page.Total))
+		}
+		if page.Next == "" {
+			return albums, nil
+		}
+
+		var next struct {
+			Albums SimpleAlbumPage `json:"albums"`
+		}
+		if err := c.get(ctx, page.Next, &next); err != nil {
+			return nil, err
+		}
+		page = &next.Albums
+	}
+}
+
 // Token gets the client's current token.
 func (c *Client) Token() (*oauth2.Token, error) {
 	transport, ok := c.http.Transport.(*oauth2.Transport)
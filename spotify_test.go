@@ -1,11 +1,15 @@
 package spotify
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -67,6 +71,30 @@ func TestNewReleases(t *testing.T) {
 	}
 }
 
+func TestNewReleasesAll(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("country") != "SE" {
+			t.Error("Expected the country to be kept on every page")
+		}
+		if r.URL.Query().Get("offset") == "1" {
+			fmt.Fprint(w, `{"albums": {"items": [{"id": "b"}], "offset": 1, "total": 2}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"albums": {"items": [{"id": "a"}], "total": 2, "next": "%s/browse/new-releases?country=SE&offset=1"}}`, server.URL)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	albums, err := client.NewReleasesAll(context.Background(), nil, Country("SE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(albums) != 2 || albums[0].ID != "a" || albums[1].ID != "b" {
+		t.Errorf("Unexpected albums: %v", albums)
+	}
+}
+
 func TestNewReleasesRateLimitExceeded(t *testing.T) {
 	t.Parallel()
 	handlers := []http.HandlerFunc{
@@ -107,6 +135,86 @@ func TestNewReleasesRateLimitExceeded(t *testing.T) {
 	}
 }
 
+func TestNoRetry(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(rateLimitExceededStatusCode)
+		_, _ = io.WriteString(w, `{ "error": { "message": "slow down", "status": 429 } }`)
+	}))
+	defer server.Close()
+
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithRetry(true))
+	if _, err := client.NewReleases(NoRetry(context.Background())); !errors.As(err, &RateLimitError{}) {
+		t.Errorf("Expected a rate limit error, got %v", err)
+	}
+	if err := client.Next(NoRetry(context.Background())); !errors.As(err, &RateLimitError{}) {
+		t.Errorf("Expected a rate limit error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 requests, got %d", calls)
+	}
+}
+
+func TestRetryRateLimited(t *testing.T) {
+	calls := 0
+	err := RetryRateLimited(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return RateLimitError{RetryAfter: time.Millisecond}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Expected success after 3 calls, got %v after %d", err, calls)
+	}
+
+	other := errors.New("other")
+	calls = 0
+	err = RetryRateLimited(context.Background(), func() error {
+		calls++
+		return other
+	})
+	if err != other || calls != 1 {
+		t.Errorf("Expected other errors not to be retried, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = RetryRateLimited(NoRetry(context.Background()), func() error {
+		calls++
+		return RateLimitError{}
+	})
+	if !errors.As(err, &RateLimitError{}) || calls != 1 {
+		t.Errorf("Expected NoRetry to be respected, got %v after %d calls", err, calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		raw  string
+		want time.Duration
+		ok   bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"30", 30 * time.Second, true},
+		{"-1", 0, false},
+		{"999999999999", maxRetryDuration, true},
+		{"Fri, 01 Mar 2024 12:00:10 GMT", 10 * time.Second, true},
+		{"Fri, 01 Mar 2024 11:59:00 GMT", minRetryDateDuration, true},
+		{"Sat, 02 Mar 2024 12:00:00 GMT", maxRetryDuration, true},
+		{"soon", 0, false},
+	}
+	for _, test := range tests {
+		got, ok := parseRetryAfter(test.raw, now)
+		if got != test.want || ok != test.ok {
+			t.Errorf("%q: expected %v, %v, got %v, %v", test.raw, test.want, test.ok, got, ok)
+		}
+	}
+}
+
 func TestRateLimitExceededReportsRetryAfter(t *testing.T) {
 	t.Parallel()
 	const retryAfter = 2
@@ -151,6 +259,13 @@ func TestRateLimitExceededReportsRetryAfter(t *testing.T) {
 	if retryAfter*time.Second-time.Until(spotifyError.RetryAfter) > time.Second {
 		t.Error("expected RetryAfter value")
 	}
+	var rateLimitError RateLimitError
+	if !errors.As(err, &rateLimitError) {
+		t.Fatalf("expected a rate limit error, got %T", err)
+	}
+	if rateLimitError.RetryAfter != retryAfter*time.Second {
+		t.Errorf("expected RetryAfter %v, got %v", retryAfter*time.Second, rateLimitError.RetryAfter)
+	}
 }
 
 func TestClient_Token(t *testing.T) {
@@ -226,3 +341,319 @@ func TestDecode429Error(t *testing.T) {
 		t.Error("Invalid error message:", err.Error())
 	}
 }
+
+func TestImagesSelection(t *testing.T) {
+	imgs := Images{
+		{Width: 640, Height: 640, URL: "large"},
+		{Width: 300, Height: 300, URL: "medium"},
+		{Width: 64, Height: 64, URL: "small"},
+	}
+	if img := imgs.Largest(); img.URL != "large" {
+		t.Error("Expected large image, got", img.URL)
+	}
+	for width, want := range map[int]string{1000: "large", 400: "medium", 182: "medium", 10: "small"} {
+		if img := imgs.Closest(width); img.URL != want {
+			t.Errorf("Closest(%d): expected %s, got %s", width, want, img.URL)
+		}
+	}
+	if img := (Images{}).Largest(); img.URL != "" {
+		t.Error("Expected zero image, got", img.URL)
+	}
+}
+
+func TestImageDownloadContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = io.WriteString(w, "image data")
+	}))
+	defer server.Close()
+
+	var buf strings.Builder
+	img := Image{URL: server.URL}
+	if err := img.DownloadContext(context.Background(), &buf, server.Client()); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "image data" {
+		t.Error("Invalid data:", buf.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := img.DownloadContext(ctx, io.Discard, nil); !errors.Is(err, context.Canceled) {
+		t.Error("Expected context.Canceled, got", err)
+	}
+}
+
+func TestImageDownloadErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		// the content type isn't checked
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "image data")
+	}))
+	defer server.Close()
+
+	err := Image{URL: server.URL + "/missing"}.Download(io.Discard)
+	if err == nil || err.Error() != "Couldn't download image - HTTP404" {
+		t.Errorf("Unexpected error %v", err)
+	}
+	var buf strings.Builder
+	if err := (Image{URL: server.URL}).Download(&buf); err != nil || buf.String() != "image data" {
+		t.Errorf("Expected the image to be downloaded, got %q, %v", buf.String(), err)
+	}
+}
+
+func TestErrorIncludesRequest(t *testing.T) {
+	client, server := testClientString(http.StatusNotFound, `{"error": {"status": 404, "message": "non existing id"}}`)
+	defer server.Close()
+
+	_, err := client.GetAlbum(context.Background(), "asdf")
+	var se Error
+	if !errors.As(err, &se) {
+		t.Fatalf("Expected spotify.Error, got %T", err)
+	}
+	if se.Method != http.MethodGet || se.Path != "/albums/asdf" {
+		t.Errorf("Unexpected request %s %s", se.Method, se.Path)
+	}
+	if want := "non existing id (GET /albums/asdf)"; se.Error() != want {
+		t.Errorf("Expected %q, got %q", want, se.Error())
+	}
+}
+
+func TestWithAPIVersion(t *testing.T) {
+	tests := []struct {
+		opts []ClientOption
+		want string
+	}{
+		{[]ClientOption{WithAPIVersion("v2")}, "https://api.spotify.com/v2/"},
+		{[]ClientOption{WithAPIVersion("v2"), WithBaseURL("https://staging.example.com/api/v1/")}, "https://staging.example.com/api/v2/"},
+		{[]ClientOption{WithBaseURL("http://127.0.0.1:8080/"), WithAPIVersion("v2")}, "http://127.0.0.1:8080/v2/"},
+	}
+	for _, test := range tests {
+		if got := New(nil, test.opts...).baseURL; got != test.want {
+			t.Errorf("Expected %s, got %s", test.want, got)
+		}
+	}
+}
+
+func TestGetInto(t *testing.T) {
+	client, server := testClientString(http.StatusOK, `{"name": "Example", "tracks": {"total": 3}}`, func(r *http.Request) {
+		if r.URL.Path != "/playlists/abc" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("fields"); got != "name,tracks.total" {
+			t.Errorf("Unexpected fields %q", got)
+		}
+	})
+	defer server.Close()
+
+	var playlist struct {
+		Name   string `json:"name"`
+		Tracks struct {
+			Total int `json:"total"`
+		} `json:"tracks"`
+	}
+	err := client.GetInto(context.Background(), "playlists/abc", &playlist, Fields("name,tracks.total"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if playlist.Name != "Example" || playlist.Tracks.Total != 3 {
+		t.Errorf("Unexpected result %+v", playlist)
+	}
+}
+
+func TestWithResponseHeaderCapture(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+		w.Header().Set("X-Other", "ignored")
+		_, _ = io.WriteString(w, `{"id": "user"}`)
+	}))
+	defer server.Close()
+
+	var got http.Header
+	var path string
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"),
+		WithResponseHeaderCapture(func(req *http.Request, header http.Header) {
+			path = req.URL.Path
+			got = header
+		}, "etag"))
+
+	if _, err := client.CurrentUser(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/me" {
+		t.Errorf("Expected request for /me, got %s", path)
+	}
+	if got.Get("ETag") != `"abc"` {
+		t.Errorf("Expected ETag to be captured, got %v", got)
+	}
+	if got.Get("X-Other") != "" {
+		t.Error("Expected other headers to be filtered out")
+	}
+}
+
+func TestGzipResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Error("Expected the request to accept gzip")
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = io.WriteString(gz, `{"id": "user", "display_name": "Compressed"}`)
+		_ = gz.Close()
+	}))
+	defer server.Close()
+
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+	user, err := client.CurrentUser(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.DisplayName != "Compressed" {
+		t.Errorf("Expected decompressed response, got %q", user.DisplayName)
+	}
+}
+
+func TestWithDryRun(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = io.WriteString(w, `{"id": "user"}`)
+	}))
+	defer server.Close()
+
+	var log strings.Builder
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"), WithDryRun(&log))
+
+	if _, err := client.CurrentUser(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_, err := client.AddTracksToPlaylist(context.Background(), "playlist", "track")
+	if !errors.Is(err, ErrDryRun) {
+		t.Fatalf("Expected ErrDryRun, got %v", err)
+	}
+	var dryRunErr DryRunError
+	if !errors.As(err, &dryRunErr) || dryRunErr.Method != http.MethodPost {
+		t.Errorf("Unexpected error %#v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected only the GET request to be sent, got %d requests", requests)
+	}
+	if !strings.Contains(log.String(), `POST `+server.URL+`/playlists/playlist/tracks {"uris":["spotify:track:track"]}`) {
+		t.Errorf("Unexpected log %q", log.String())
+	}
+}
+
+func TestNumericUnmarshal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Numeric
+	}{
+		{`{"n": 3}`, 3},
+		{`{"n": 3.7}`, 3},
+		{`{"n": "42"}`, 42},
+		{`{"n": " 1.5 "}`, 1},
+		{`{"n": null}`, 7},
+		{`{"n": ""}`, 7},
+	}
+	for _, test := range tests {
+		v := struct {
+			N Numeric `json:"n"`
+		}{N: 7}
+		if err := json.Unmarshal([]byte(test.in), &v); err != nil {
+			t.Errorf("%s: %v", test.in, err)
+			continue
+		}
+		if v.N != test.want {
+			t.Errorf("%s: expected %d, got %d", test.in, test.want, v.N)
+		}
+	}
+
+	var n Numeric
+	if err := json.Unmarshal([]byte(`"many"`), &n); err == nil {
+		t.Error("Expected an error for a non-numeric string")
+	}
+}
+
+func TestAPIURL(t *testing.T) {
+	c := New(nil)
+	if got := c.apiURL(nil, "playlists/", "abc", "/tracks"); got != "https://api.spotify.com/v1/playlists/abc/tracks" {
+		t.Errorf("Unexpected URL %s", got)
+	}
+	v := url.Values{"limit": {"10"}, "market": {"SE"}}
+	if got := c.apiURL(v, "me/tracks"); got != "https://api.spotify.com/v1/me/tracks?limit=10&market=SE" {
+		t.Errorf("Unexpected URL %s", got)
+	}
+	if got := joinIDs([]ID{"a", "bb", "ccc"}); got != "a,bb,ccc" {
+		t.Errorf("Unexpected IDs %s", got)
+	}
+}
+
+func BenchmarkAPIURL(b *testing.B) {
+	c := New(nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = c.apiURL(nil, "playlists/", "37i9dQZF1DXcBWIGoYBM5M", "/tracks")
+	}
+}
+
+func BenchmarkAPIURLWithQuery(b *testing.B) {
+	c := New(nil)
+	o := processOptions(Limit(50), Offset(100), Market("SE"))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = c.apiURL(o.urlParams, "playlists/", "37i9dQZF1DXcBWIGoYBM5M", "/tracks")
+	}
+}
+
+func BenchmarkJoinIDs(b *testing.B) {
+	ids := make([]ID, 50)
+	for i := range ids {
+		ids[i] = ID(fmt.Sprintf("%022d", i))
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = joinIDs(ids)
+	}
+}
+
+func TestNewFromTokenSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			t.Errorf("Unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		fmt.Fprint(w, `{"id": "wizzler"}`)
+	}))
+	defer server.Close()
+
+	src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "abc", TokenType: "Bearer"})
+	client := NewFromTokenSource(context.Background(), src, WithBaseURL(server.URL+"/"))
+	if _, err := client.CurrentUser(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	token, err := client.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "abc" {
+		t.Errorf("Unexpected token %v", token)
+	}
+}
+
+func TestClientWith(t *testing.T) {
+	client := New(http.DefaultClient, WithAcceptLanguage("en"), WithPagePrefetch())
+	derived := client.With(WithAcceptLanguage("de"), WithRetry(true))
+
+	if client.acceptLanguage != "en" || client.autoRetry {
+		t.Error("Expected the original client to be unchanged")
+	}
+	if derived.acceptLanguage != "de" || !derived.autoRetry || derived.http != client.http {
+		t.Error("Expected the derived client to have the new settings and the same HTTP client")
+	}
+	if derived.prefetcher == client.prefetcher {
+		t.Error("Expected the derived client not to share prefetched pages")
+	}
+}
@@ -0,0 +1,374 @@
+// Package stats summarises a Spotify user's library and listening history,
+// for analytics apps and "year in review" style reports.
+//
+// The aggregation functions, such as [ArtistsBySavedTracks] and
+// [DecadeHistogram], work on data that has already been fetched.  A
+// [Collector] fetches everything needed, following every page, and returns a
+// [Summary] of it.
+//
+// Example:
+//
+//	summary, err := stats.New(client).Collect(ctx)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, d := range summary.Decades {
+//		fmt.Printf("%ds: %d tracks\n", d.Decade, d.Tracks)
+//	}
+package stats
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// ArtistCount is the number of tracks by an artist.
+type ArtistCount struct {
+	Artist spotify.SimpleArtist
+	Tracks int
+}
+
+// DecadeCount is the number of tracks released in a decade, identified by its
+// first year, such as 1990.
+type DecadeCount struct {
+	Decade int
+	Tracks int
+}
+
+// GrowthPoint is the size of the library at the end of a month.
+type GrowthPoint struct {
+	// Month is the first day of the month, in UTC.
+	Month time.Time
+	// Added is the number of tracks saved during the month.
+	Added int
+	// Total is the number of tracks saved by the end of the month, among
+	// those still in the library.
+	Total int
+}
+
+// ArtistsBySavedTracks counts the tracks by each artist, crediting every
+// artist of a track, and returns the artists with the most tracks first.
+func ArtistsBySavedTracks(tracks []spotify.SavedTrack) []ArtistCount {
+	simple := make([]spotify.SimpleTrack, len(tracks))
+	for i := range tracks {
+		simple[i] = tracks[i].SimpleTrack
+	}
+	return countArtists(simple)
+}
+
+// ArtistsByPlays counts the plays of each artist, and returns the artists with
+// the most plays first.
+func ArtistsByPlays(items []spotify.RecentlyPlayedItem) []ArtistCount {
+	tracks := make([]spotify.SimpleTrack, len(items))
+	for i := range items {
+		tracks[i] = items[i].Track
+	}
+	return countArtists(tracks)
+}
+
+func countArtists(tracks []spotify.SimpleTrack) []ArtistCount {
+	counts := make(map[spotify.ID]*ArtistCount)
+	for _, t := range tracks {
+		for _, a := range t.Artists {
+			c, ok := counts[a.ID]
+			if !ok {
+				c = &ArtistCount{Artist: a}
+				counts[a.ID] = c
+			}
+			c.Tracks++
+		}
+	}
+
+	result := make([]ArtistCount, 0, len(counts))
+	for _, c := range counts {
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Tracks != result[j].Tracks {
+			return result[i].Tracks > result[j].Tracks
+		}
+		return result[i].Artist.Name < result[j].Artist.Name
+	})
+	return result
+}
+
+// DecadeHistogram counts the tracks released in each decade, using the release
+// dates of their albums, oldest decade first.  Decades without tracks between
+// the oldest and newest are included with a count of zero.  Tracks without a
+// release date are left out.
+func DecadeHistogram(tracks []spotify.SavedTrack) []DecadeCount {
+	counts := make(map[int]int)
+	first, last := 0, 0
+	for i := range tracks {
+		released := tracks[i].Album.ReleaseDateTime()
+		if released.IsZero() {
+			continue
+		}
+		decade := released.Year() / 10 * 10
+		if len(counts) == 0 || decade < first {
+			first = decade
+		}
+		if len(counts) == 0 || decade > last {
+			last = decade
+		}
+		counts[decade]++
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	var result []DecadeCount
+	for decade := first; decade <= last; decade += 10 {
+		result = append(result, DecadeCount{Decade: decade, Tracks: counts[decade]})
+	}
+	return result
+}
+
+// LibraryGrowth returns the number of tracks saved in each month, and the size
+// of the library at the end of it, from the first month a track was saved in
+// to the last.  Tracks that were removed from the library aren't known, so the
+// totals only count the tracks that are still saved.
+func LibraryGrowth(tracks []spotify.SavedTrack) []GrowthPoint {
+	added := make(map[time.Time]int)
+	var first, last time.Time
+	for i := range tracks {
+		at := tracks[i].AddedAtTime()
+		if at.IsZero() {
+			continue
+		}
+		at = at.UTC()
+		month := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if first.IsZero() || month.Before(first) {
+			first = month
+		}
+		if month.After(last) {
+			last = month
+		}
+		added[month]++
+	}
+	if first.IsZero() {
+		return nil
+	}
+
+	var result []GrowthPoint
+	total := 0
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		total += added[month]
+		result = append(result, GrowthPoint{Month: month, Added: added[month], Total: total})
+	}
+	return result
+}
+
+// PlaysByHour counts the plays in each hour of the day, in loc.
+func PlaysByHour(items []spotify.RecentlyPlayedItem, loc *time.Location) [24]int {
+	var hours [24]int
+	for _, item := range items {
+		hours[item.PlayedAt.In(loc).Hour()]++
+	}
+	return hours
+}
+
+// Summary summarises a user's library and listening history.
+type Summary struct {
+	// SavedTracks is the number of tracks in the user's library.
+	SavedTracks int
+	// TopSavedArtists are the artists with the most saved tracks, most
+	// first.
+	TopSavedArtists []ArtistCount
+	// Decades counts the saved tracks by decade of release.
+	Decades []DecadeCount
+	// Growth is the size of the library by month.
+	Growth []GrowthPoint
+
+	// TopArtists and TopTracks are the user's top items, as computed by
+	// Spotify for the collector's time range.  All of the pages are fetched,
+	// though Spotify usually ranks no more than a hundred or so of each.
+	TopArtists []spotify.FullArtist
+	TopTracks  []spotify.FullTrack
+
+	// RecentArtists are the artists played most among the recently played
+	// tracks, and PlaysByHour counts those plays by hour of the day.
+	// Spotify only remembers the last fifty plays.
+	RecentArtists []ArtistCount
+	PlaysByHour   [24]int
+}
+
+// Collector fetches the data summarised by a [Summary].  You should always
+// use [New] to make them.
+type Collector struct {
+	client      *spotify.Client
+	timeRange   spotify.Range
+	location    *time.Location
+	concurrency int
+}
+
+// Option configures a [Collector] made by [New].
+type Option func(c *Collector)
+
+// WithTimeRange sets the time range over which Spotify computes the top
+// items.  It defaults to [spotify.MediumTermRange].
+func WithTimeRange(r spotify.Range) Option {
+	return func(c *Collector) {
+		c.timeRange = r
+	}
+}
+
+// WithLocation sets the time zone in which plays are counted by hour.  It
+// defaults to UTC.
+func WithLocation(loc *time.Location) Option {
+	return func(c *Collector) {
+		c.location = loc
+	}
+}
+
+// DefaultConcurrency is the number of pages of saved tracks that are fetched
+// at once if [WithConcurrency] isn't used.
+const DefaultConcurrency = 4
+
+// WithConcurrency sets the number of pages of saved tracks that are fetched
+// at once.  Values below 1 are treated as 1.
+func WithConcurrency(n int) Option {
+	return func(c *Collector) {
+		c.concurrency = n
+	}
+}
+
+// New creates a collector that uses client, which needs the
+// [spotifyauth.ScopeUserLibraryRead], [spotifyauth.ScopeUserTopRead] and
+// [spotifyauth.ScopeUserReadRecentlyPlayed] scopes.
+func New(client *spotify.Client, opts ...Option) *Collector {
+	c := &Collector{
+		client:      client,
+		timeRange:   spotify.MediumTermRange,
+		location:    time.UTC,
+		concurrency: DefaultConcurrency,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.concurrency < 1 {
+		c.concurrency = 1
+	}
+
+	return c
+}
+
+// Collect fetches the user's whole library, top items and recently played
+// tracks, and summarises them.
+func (c *Collector) Collect(ctx context.Context) (*Summary, error) {
+	tracks, err := c.savedTracks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	topArtists, err := c.topArtists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	topTracks, err := c.topTracks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := c.client.PlayerRecentlyPlayedOpt(ctx, &spotify.RecentlyPlayedOptions{Limit: 50})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Summary{
+		SavedTracks:     len(tracks),
+		TopSavedArtists: ArtistsBySavedTracks(tracks),
+		Decades:         DecadeHistogram(tracks),
+		Growth:          LibraryGrowth(tracks),
+		TopArtists:      topArtists,
+		TopTracks:       topTracks,
+		RecentArtists:   ArtistsByPlays(recent),
+		PlaysByHour:     PlaysByHour(recent, c.location),
+	}, nil
+}
+
+// savedTracks fetches every track in the user's library, most recently saved
+// first.
+func (c *Collector) savedTracks(ctx context.Context) ([]spotify.SavedTrack, error) {
+	var pages spotify.OrderedPages
+	err := spotify.FetchAllPagesParallel(ctx, func(ctx context.Context, offset, limit int) (int, error) {
+		page, err := c.client.CurrentUsersTracks(ctx, spotify.Offset(offset), spotify.Limit(limit))
+		if err != nil {
+			return 0, err
+		}
+		pages.Add(offset, page.Tracks)
+		return int(page.Total), nil
+	}, 50, c.concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []spotify.SavedTrack
+	pages.Each(func(page interface{}) {
+		tracks = append(tracks, page.([]spotify.SavedTrack)...)
+	})
+	return tracks, nil
+}
+
+// topArtists fetches every page of the user's top artists.
+func (c *Collector) topArtists(ctx context.Context) ([]spotify.FullArtist, error) {
+	var (
+		page    *spotify.FullArtistPage
+		artists []spotify.FullArtist
+	)
+	err := c.topItems(func(opts ...spotify.RequestOption) (err error) {
+		page, err = c.client.CurrentUsersTopArtists(ctx, opts...)
+		return err
+	}, func() error {
+		return c.client.NextPage(ctx, page)
+	}, func() {
+		artists = append(artists, page.Artists...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return artists, nil
+}
+
+// topTracks fetches every page of the user's top tracks.
+func (c *Collector) topTracks(ctx context.Context) ([]spotify.FullTrack, error) {
+	var (
+		page   *spotify.FullTrackPage
+		tracks []spotify.FullTrack
+	)
+	err := c.topItems(func(opts ...spotify.RequestOption) (err error) {
+		page, err = c.client.CurrentUsersTopTracks(ctx, opts...)
+		return err
+	}, func() error {
+		return c.client.NextPage(ctx, page)
+	}, func() {
+		tracks = append(tracks, page.Tracks...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tracks, nil
+}
+
+// topItems fetches every page of one of the user's lists of top items.  first
+// requests the first page with opts, and next the page after the one last
+// fetched, returning [spotify.ErrNoMorePages] after the last page.  collect is
+// called after each page is fetched.
+func (c *Collector) topItems(first func(opts ...spotify.RequestOption) error, next func() error, collect func()) error {
+	if err := first(spotify.Timerange(c.timeRange), spotify.Limit(50)); err != nil {
+		return err
+	}
+	for {
+		collect()
+		err := next()
+		if errors.Is(err, spotify.ErrNoMorePages) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+func savedTrack(addedAt, released string, artists ...string) spotify.SavedTrack {
+	var t spotify.SavedTrack
+	t.AddedAt = addedAt
+	t.Album.ReleaseDate = released
+	t.Album.ReleaseDatePrecision = "day"
+	for _, a := range artists {
+		t.Artists = append(t.Artists, spotify.SimpleArtist{ID: spotify.ID(a), Name: a})
+	}
+	return t
+}
+
+func TestAggregations(t *testing.T) {
+	tracks := []spotify.SavedTrack{
+		savedTrack("2021-03-05T10:00:00Z", "1994-01-01", "b"),
+		savedTrack("2021-01-20T10:00:00Z", "1991-06-01", "a", "b"),
+		savedTrack("2021-01-02T10:00:00Z", "1975-01-01", "a"),
+		savedTrack("2021-01-01T10:00:00Z", "", "c"),
+	}
+
+	artists := ArtistsBySavedTracks(tracks)
+	if got := fmt.Sprintf("%s %d %s %s", artists[0].Artist.Name, artists[0].Tracks, artists[1].Artist.Name, artists[2].Artist.Name); got != "a 2 b c" {
+		t.Errorf("Unexpected artists %v", artists)
+	}
+
+	decades := DecadeHistogram(tracks)
+	want := []DecadeCount{{1970, 1}, {1980, 0}, {1990, 2}}
+	if fmt.Sprint(decades) != fmt.Sprint(want) {
+		t.Errorf("Expected decades %v, got %v", want, decades)
+	}
+
+	growth := LibraryGrowth(tracks)
+	if len(growth) != 3 {
+		t.Fatalf("Expected 3 months, got %v", growth)
+	}
+	if growth[0].Added != 3 || growth[1].Added != 0 || growth[2].Total != 4 {
+		t.Errorf("Unexpected growth %v", growth)
+	}
+
+	items := []spotify.RecentlyPlayedItem{
+		{PlayedAt: time.Date(2021, 1, 1, 23, 30, 0, 0, time.UTC)},
+		{PlayedAt: time.Date(2021, 1, 2, 23, 10, 0, 0, time.UTC)},
+	}
+	if hours := PlaysByHour(items, time.UTC); hours[23] != 2 {
+		t.Errorf("Expected 2 plays at 23h, got %v", hours)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/me/tracks":
+			if r.URL.Query().Get("offset") == "0" {
+				fmt.Fprint(w, `{"total": 51, "items": [{"added_at": "2021-01-01T00:00:00Z", "track": {"id": "t1", "artists": [{"id": "a"}]}}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"total": 51, "items": [{"added_at": "2020-01-01T00:00:00Z", "track": {"id": "t2", "artists": [{"id": "a"}]}}]}`)
+		case "/me/top/artists":
+			if r.URL.Query().Get("time_range") != "short_term" {
+				t.Errorf("Unexpected time range %s", r.URL.RawQuery)
+			}
+			if r.URL.Query().Get("offset") == "50" {
+				fmt.Fprint(w, `{"items": [{"id": "b"}]}`)
+				return
+			}
+			fmt.Fprintf(w, `{"items": [{"id": "a"}], "next": "http://%s/me/top/artists?offset=50&time_range=short_term"}`, r.Host)
+		case "/me/top/tracks":
+			fmt.Fprint(w, `{"items": [{"id": "t1"}]}`)
+		case "/me/player/recently-played":
+			fmt.Fprint(w, `{"items": [{"played_at": "2021-01-01T08:00:00Z", "track": {"id": "t1", "artists": [{"id": "a"}]}}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := spotify.New(http.DefaultClient, spotify.WithBaseURL(server.URL+"/"))
+	summary, err := New(client, WithTimeRange(spotify.ShortTermRange)).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.SavedTracks != 2 || summary.TopSavedArtists[0].Tracks != 2 || len(summary.Growth) != 13 {
+		t.Errorf("Unexpected library summary %+v", summary)
+	}
+	if len(summary.TopArtists) != 2 || summary.TopArtists[1].ID != "b" || len(summary.TopTracks) != 1 || summary.PlaysByHour[8] != 1 {
+		t.Errorf("Unexpected listening summary %+v", summary)
+	}
+}
+
+func TestWithConcurrency(t *testing.T) {
+	if c := New(nil, WithConcurrency(0)); c.concurrency != 1 {
+		t.Errorf("Expected the concurrency to be clamped to 1, got %d", c.concurrency)
+	}
+	if c := New(nil); c.concurrency != DefaultConcurrency {
+		t.Errorf("Expected the default concurrency, got %d", c.concurrency)
+	}
+}
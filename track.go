@@ -4,6 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
 )
@@ -98,6 +102,78 @@ type FullTrack struct {
 	LinkedFrom *LinkedFromInfo `json:"linked_from"`
 }
 
+// CanonicalID returns the ID of the track that was originally requested.  When
+// [Track Relinking] has been applied, this is the ID found in
+// [FullTrack.LinkedFrom] rather than the market-specific ID of the playable
+// track.  Otherwise, it is the track's own ID.
+//
+// [Track Relinking]: https://developer.spotify.com/documentation/general/guides/track-relinking-guide/
+func (t *FullTrack) CanonicalID() ID {
+	if t.LinkedFrom != nil && t.LinkedFrom.ID != "" {
+		return t.LinkedFrom.ID
+	}
+	return t.ID
+}
+
+// CanonicalURI is like [FullTrack.CanonicalID], but returns the track's URI.
+func (t *FullTrack) CanonicalURI() URI {
+	if t.LinkedFrom != nil && t.LinkedFrom.URI != "" {
+		return URI(t.LinkedFrom.URI)
+	}
+	return t.URI
+}
+
+// resolveRelink replaces the market-specific identifiers of a relinked track
+// with those of the originally requested track.
+func (t *FullTrack) resolveRelink() {
+	if t.LinkedFrom == nil || t.LinkedFrom.ID == "" {
+		return
+	}
+	t.ID = t.LinkedFrom.ID
+	t.URI = t.CanonicalURI()
+	if t.LinkedFrom.Href != "" {
+		t.Endpoint = t.LinkedFrom.Href
+	}
+}
+
+// relinkable is implemented by responses containing tracks that may have
+// been relinked.
+type relinkable interface {
+	resolveRelinks()
+}
+
+func (p *SavedTrackPage) resolveRelinks() {
+	for i := range p.Tracks {
+		p.Tracks[i].resolveRelink()
+	}
+}
+
+func (p *PlaylistTrackPage) resolveRelinks() {
+	for i := range p.Tracks {
+		p.Tracks[i].Track.resolveRelink()
+	}
+}
+
+func (p *PlaylistItemPage) resolveRelinks() {
+	for i := range p.Items {
+		if t := p.Items[i].Track.Track; t != nil {
+			t.resolveRelink()
+		}
+	}
+}
+
+func (p *FullPlaylist) resolveRelinks() {
+	p.Tracks.resolveRelinks()
+}
+
+// resolveRelinks rewrites relinked tracks in v if the client was configured
+// with [WithCanonicalTrackIDs].
+func (c *Client) resolveRelinks(v interface{}) {
+	if r, ok := v.(relinkable); ok && c.canonicalTrackIDs {
+		r.resolveRelinks()
+	}
+}
+
 // PlaylistTrack contains info about a track in a playlist.
 type PlaylistTrack struct {
 	// The date and time the track was added to the playlist. You can use
@@ -122,31 +198,79 @@ type SavedTrack struct {
 	FullTrack `json:"track"`
 }
 
+// AddedAtTime converts [SavedTrack.AddedAt] to a [time.Time].  It returns the
+// zero time if AddedAt can't be parsed.
+func (t *SavedTrack) AddedAtTime() time.Time {
+	result, _ := time.Parse(TimestampLayout, t.AddedAt)
+	return result
+}
+
+// FilterSavedTracksAddedAfter returns the tracks that were saved after the
+// specified time, in their original order.  It is meant to be used on the
+// combined results of every page of [Client.CurrentUsersTracks], for example
+// to export recently liked tracks.
+func FilterSavedTracksAddedAfter(tracks []SavedTrack, after time.Time) []SavedTrack {
+	var result []SavedTrack
+	for i := range tracks {
+		if tracks[i].AddedAtTime().After(after) {
+			result = append(result, tracks[i])
+		}
+	}
+	return result
+}
+
+// SortSavedTracksByAddedAt sorts tracks in place so that the most recently
+// saved tracks come first, which is the order used by
+// [Client.CurrentUsersTracks].  Tracks saved at the same time keep their
+// relative order.
+func SortSavedTracksByAddedAt(tracks []SavedTrack) {
+	sort.SliceStable(tracks, func(i, j int) bool {
+		return tracks[i].AddedAtTime().After(tracks[j].AddedAtTime())
+	})
+}
+
 // TimeDuration returns the track's duration as a [time.Duration] value.
 func (t *SimpleTrack) TimeDuration() time.Duration {
 	return time.Duration(t.Duration) * time.Millisecond
 }
 
+// DownloadPreview downloads the track's 30 second preview and writes its
+// (MP3) data to dst.  It returns [ErrNoPreview] if the track has no preview.
+func (t *SimpleTrack) DownloadPreview(ctx context.Context, dst io.Writer) error {
+	if t.PreviewURL == "" {
+		return ErrNoPreview
+	}
+	return download(ctx, http.DefaultClient, t.PreviewURL, dst, "audio/")
+}
+
 // GetTrack gets Spotify catalog information for
-// a [single track] identified by its unique [Spotify ID].
+// a [single track] identified by its unique [Spotify ID].  The ID may also
+// be given as a URI or share URL, see [ResolveID].
 //
 // Supported options: [Market].
 //
 // [single track]: https://developer.spotify.com/documentation/web-api/reference/get-track
 // [Spotify ID]: https://developer.spotify.com/documentation/web-api/#spotify-uris-and-ids
 func (c *Client) GetTrack(ctx context.Context, id ID, opts ...RequestOption) (*FullTrack, error) {
-	spotifyURL := c.baseURL + "tracks/" + string(id)
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	id, err := resolveID(id, "track")
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := c.cachedEntity(ctx, "track", o.urlParams, id); ok {
+		t := cached.(FullTrack)
+		return &t, nil
+	}
+	spotifyURL := c.apiURL(o.urlParams, "tracks/", string(id))
 
 	var t FullTrack
 
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
-		spotifyURL += "?" + params
-	}
-
-	err := c.get(ctx, spotifyURL, &t)
+	err = c.get(ctx, spotifyURL, &t)
 	if err != nil {
 		return nil, err
 	}
+	c.cacheEntity(ctx, "track", o.urlParams, id, t)
 
 	return &t, nil
 }
@@ -165,18 +289,92 @@ func (c *Client) GetTracks(ctx context.Context, ids []ID, opts ...RequestOption)
 		return nil, errors.New("spotify: FindTracks supports up to 50 tracks")
 	}
 
-	params := processOptions(opts...).urlParams
-	params.Set("ids", strings.Join(toStringSlice(ids), ","))
-	spotifyURL := c.baseURL + "tracks?" + params.Encode()
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	ids, err := resolveIDs(ids, "track")
+	if err != nil {
+		return nil, err
+	}
+	tracks := make([]*FullTrack, len(ids))
+	missing, positions := c.uncachedEntities(ctx, "track", o.urlParams, ids, func(i int, cached interface{}) {
+		t := cached.(FullTrack)
+		tracks[i] = &t
+	})
+	if len(missing) == 0 && len(ids) > 0 {
+		return tracks, nil
+	}
+
+	params := url.Values{"ids": {joinIDs(missing)}}
+	for k, v := range o.urlParams {
+		params[k] = v
+	}
+	spotifyURL := c.apiURL(params, "tracks")
 
 	var t struct {
 		Tracks []*FullTrack `json:"tracks"`
 	}
 
-	err := c.get(ctx, spotifyURL, &t)
+	err = c.get(ctx, spotifyURL, &t)
 	if err != nil {
 		return nil, err
 	}
+	if c.entities == nil {
+		return t.Tracks, nil
+	}
+	for j, track := range t.Tracks {
+		if j >= len(positions) {
+			break
+		}
+		tracks[positions[j]] = track
+		if track != nil {
+			c.cacheEntity(ctx, "track", o.urlParams, missing[j], *track)
+		}
+	}
+
+	return tracks, nil
+}
+
+// CheckAvailability reports the markets in which each of the specified tracks
+// is playable.  Tracks are fetched in batches of 50 using [Client.GetTracks], so
+// any number of IDs may be given.  Only the markets listed in markets are
+// reported; if markets is empty, every market the track is available in is
+// returned.  Tracks that are not found, or that are not available in any of the
+// requested markets, map to an empty slice.
+//
+// Markets are identified by their [ISO 3166-1 alpha-2] codes.
+//
+// [ISO 3166-1 alpha-2]: https://en.wikipedia.org/wiki/ISO_3166-1_alpha-2
+func (c *Client) CheckAvailability(ctx context.Context, trackIDs []ID, markets []string) (map[ID][]string, error) {
+	wanted := make(map[string]bool, len(markets))
+	for _, m := range markets {
+		wanted[strings.ToUpper(m)] = true
+	}
+
+	result := make(map[ID][]string, len(trackIDs))
+	for start := 0; start < len(trackIDs); start += 50 {
+		end := start + 50
+		if end > len(trackIDs) {
+			end = len(trackIDs)
+		}
+		batch := trackIDs[start:end]
+
+		tracks, err := c.GetTracks(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, id := range batch {
+			available := []string{}
+			if i < len(tracks) && tracks[i] != nil {
+				for _, m := range tracks[i].AvailableMarkets {
+					if len(wanted) == 0 || wanted[m] {
+						available = append(available, m)
+					}
+				}
+			}
+			result[id] = available
+		}
+	}
 
-	return t.Tracks, nil
+	return result, nil
 }
@@ -0,0 +1,64 @@
+package spotify
+
+import (
+	"sort"
+	"strings"
+)
+
+// DedupeTracksByISRC returns tracks without the tracks that have the same
+// ISRC as an earlier track, such as the same recording released on a single
+// and on an album.  ISRCs are compared ignoring case and surrounding spaces.
+// Tracks without an ISRC, such as local files, are only dropped if they have
+// the same ID as an earlier track.  The order of the tracks is preserved, and
+// tracks isn't modified; to keep the most popular copy of each recording,
+// call [SortTracksByPopularity] first.
+func DedupeTracksByISRC(tracks []FullTrack) []FullTrack {
+	seen := make(map[string]bool, len(tracks))
+	var result []FullTrack
+	for _, t := range tracks {
+		key := "isrc:" + strings.ToUpper(strings.TrimSpace(t.ExternalIDs.ISRC))
+		if key == "isrc:" {
+			if t.ID == "" {
+				result = append(result, t)
+				continue
+			}
+			key = "id:" + string(t.ID)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, t)
+	}
+	return result
+}
+
+// SortTracksByPopularity sorts tracks in place, from the most to the least
+// popular.  Tracks with the same popularity keep their relative order.
+func SortTracksByPopularity(tracks []FullTrack) {
+	sort.SliceStable(tracks, func(i, j int) bool {
+		return tracks[i].Popularity > tracks[j].Popularity
+	})
+}
+
+// UniqueArtists returns the artists of tracks, in the order in which they
+// first appear, without duplicates.  Artists are identified by their ID, or
+// by their name if they don't have one, as on local files.
+func UniqueArtists(tracks []FullTrack) []SimpleArtist {
+	seen := make(map[string]bool)
+	var artists []SimpleArtist
+	for _, t := range tracks {
+		for _, a := range t.Artists {
+			key := "id:" + string(a.ID)
+			if a.ID == "" {
+				key = "name:" + a.Name
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			artists = append(artists, a)
+		}
+	}
+	return artists
+}
@@ -0,0 +1,67 @@
+package spotify
+
+import (
+	"fmt"
+	"testing"
+)
+
+func trackIDs(tracks []FullTrack) string {
+	ids := make([]ID, len(tracks))
+	for i, t := range tracks {
+		ids[i] = t.ID
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestDedupeTracksByISRC(t *testing.T) {
+	track := func(id ID, isrc string) FullTrack {
+		return FullTrack{SimpleTrack: SimpleTrack{ID: id, ExternalIDs: TrackExternalIDs{ISRC: isrc}}}
+	}
+	tracks := []FullTrack{
+		track("single", "USUM71703861"),
+		track("album", " usum71703861"),
+		track("other", "GBAYE0601498"),
+		track("noisrc", ""),
+		track("noisrc", ""),
+		track("", ""),
+		track("", ""),
+		track("again", "GBAYE0601498"),
+	}
+
+	if got, want := trackIDs(DedupeTracksByISRC(tracks)), "[single other noisrc  ]"; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if tracks[1].ID != "album" {
+		t.Error("Expected the input to be unchanged")
+	}
+}
+
+func TestSortTracksByPopularity(t *testing.T) {
+	track := func(id ID, popularity Numeric) FullTrack {
+		return FullTrack{SimpleTrack: SimpleTrack{ID: id}, Popularity: popularity}
+	}
+	tracks := []FullTrack{track("a", 10), track("b", 80), track("c", 10), track("d", 95)}
+
+	SortTracksByPopularity(tracks)
+	if got, want := trackIDs(tracks), "[d b a c]"; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestUniqueArtists(t *testing.T) {
+	track := func(artists ...SimpleArtist) FullTrack {
+		return FullTrack{SimpleTrack: SimpleTrack{Artists: artists}}
+	}
+	a := SimpleArtist{ID: "a", Name: "A"}
+	b := SimpleArtist{ID: "b", Name: "B"}
+	local := SimpleArtist{Name: "Local"}
+	tracks := []FullTrack{track(a), track(b, a), track(local), track(local, b)}
+
+	var names []string
+	for _, artist := range UniqueArtists(tracks) {
+		names = append(names, artist.Name)
+	}
+	if got, want := fmt.Sprint(names), "[A B Local]"; got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
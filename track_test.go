@@ -1,9 +1,14 @@
 package spotify
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestFindTrack(t *testing.T) {
@@ -70,3 +75,136 @@ func TestFindTracksNotFound(t *testing.T) {
 		t.Error("Expected nil track (invalid ID) but got valid track")
 	}
 }
+
+func TestCanonicalID(t *testing.T) {
+	track := FullTrack{
+		SimpleTrack: SimpleTrack{ID: "6kLCHFM39wkFjOuyPGLGeQ", URI: "spotify:track:6kLCHFM39wkFjOuyPGLGeQ"},
+	}
+	if id := track.CanonicalID(); id != "6kLCHFM39wkFjOuyPGLGeQ" {
+		t.Errorf("Expected track's own ID, got %s", id)
+	}
+
+	track.LinkedFrom = &LinkedFromInfo{ID: "6ozxplTAjWO0BlUxN8ia0A", URI: "spotify:track:6ozxplTAjWO0BlUxN8ia0A"}
+	if id := track.CanonicalID(); id != "6ozxplTAjWO0BlUxN8ia0A" {
+		t.Errorf("Expected linked ID, got %s", id)
+	}
+	if uri := track.CanonicalURI(); uri != "spotify:track:6ozxplTAjWO0BlUxN8ia0A" {
+		t.Errorf("Expected linked URI, got %s", uri)
+	}
+}
+
+func TestCanonicalTrackIDs(t *testing.T) {
+	const response = `{
+  "href": "https://api.spotify.com/v1/me/tracks?offset=0&limit=20&market=US",
+  "items": [ {
+    "added_at": "2014-07-08T14:05:27Z",
+    "track": {
+      "id": "6kLCHFM39wkFjOuyPGLGeQ",
+      "uri": "spotify:track:6kLCHFM39wkFjOuyPGLGeQ",
+      "name": "Heaven and Hell",
+      "linked_from": {
+        "id": "6ozxplTAjWO0BlUxN8ia0A",
+        "uri": "spotify:track:6ozxplTAjWO0BlUxN8ia0A",
+        "href": "https://api.spotify.com/v1/tracks/6ozxplTAjWO0BlUxN8ia0A",
+        "type": "track"
+      }
+    }
+  } ],
+  "limit": 20,
+  "next": null,
+  "offset": 0,
+  "previous": null,
+  "total": 1
+}`
+
+	client, server := testClientString(http.StatusOK, response)
+	defer server.Close()
+	client.canonicalTrackIDs = true
+
+	tracks, err := client.CurrentUsersTracks(context.Background(), Market(CountryUSA))
+	if err != nil {
+		t.Fatal(err)
+	}
+	track := tracks.Tracks[0]
+	if track.ID != "6ozxplTAjWO0BlUxN8ia0A" {
+		t.Errorf("Expected canonical ID, got %s", track.ID)
+	}
+	if track.URI != "spotify:track:6ozxplTAjWO0BlUxN8ia0A" {
+		t.Errorf("Expected canonical URI, got %s", track.URI)
+	}
+	if track.Endpoint != "https://api.spotify.com/v1/tracks/6ozxplTAjWO0BlUxN8ia0A" {
+		t.Errorf("Expected canonical endpoint, got %s", track.Endpoint)
+	}
+	if track.LinkedFrom == nil {
+		t.Error("Expected LinkedFrom to be preserved")
+	}
+}
+
+func TestCheckAvailability(t *testing.T) {
+	client, server := testClientFile(http.StatusOK, "test_data/find_tracks_notfound.txt")
+	defer server.Close()
+
+	ids := []ID{"0eGsygTp906u18L0Oimnem", "1lDWb6b6iecccdsdckTC3G"}
+	availability, err := client.CheckAvailability(context.Background(), ids, []string{CountryArgentina, CountryUSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(availability); l != 2 {
+		t.Fatalf("Expected 2 results, got %d", l)
+	}
+	if markets := availability["0eGsygTp906u18L0Oimnem"]; len(markets) != 1 || markets[0] != CountryArgentina {
+		t.Errorf("Expected [AR], got %v", markets)
+	}
+	if markets := availability["1lDWb6b6iecccdsdckTC3G"]; len(markets) != 0 {
+		t.Errorf("Expected no markets for missing track, got %v", markets)
+	}
+}
+
+func TestDownloadPreview(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/image" {
+			w.Header().Set("Content-Type", "image/jpeg")
+		} else {
+			w.Header().Set("Content-Type", "audio/mpeg")
+		}
+		_, _ = io.WriteString(w, "preview")
+	}))
+	defer server.Close()
+
+	track := SimpleTrack{PreviewURL: server.URL + "/mp3-preview"}
+	var buf bytes.Buffer
+	if err := track.DownloadPreview(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "preview" {
+		t.Error("Invalid data:", buf.String())
+	}
+
+	track.PreviewURL = server.URL + "/image"
+	if err := track.DownloadPreview(context.Background(), io.Discard); err == nil {
+		t.Error("Expected content type error")
+	}
+
+	track.PreviewURL = ""
+	if err := track.DownloadPreview(context.Background(), io.Discard); !errors.Is(err, ErrNoPreview) {
+		t.Error("Expected ErrNoPreview, got", err)
+	}
+}
+
+func TestSavedTracksByAddedAt(t *testing.T) {
+	tracks := []SavedTrack{
+		{AddedAt: "2021-01-01T00:00:00Z", FullTrack: FullTrack{SimpleTrack: SimpleTrack{ID: "old"}}},
+		{AddedAt: "2021-03-01T00:00:00Z", FullTrack: FullTrack{SimpleTrack: SimpleTrack{ID: "new"}}},
+		{AddedAt: "2021-02-01T00:00:00Z", FullTrack: FullTrack{SimpleTrack: SimpleTrack{ID: "mid"}}},
+	}
+
+	recent := FilterSavedTracksAddedAfter(tracks, time.Date(2021, 1, 15, 0, 0, 0, 0, time.UTC))
+	if len(recent) != 2 || recent[0].ID != "new" || recent[1].ID != "mid" {
+		t.Errorf("Unexpected filtered tracks %v", recent)
+	}
+
+	SortSavedTracksByAddedAt(tracks)
+	if tracks[0].ID != "new" || tracks[1].ID != "mid" || tracks[2].ID != "old" {
+		t.Errorf("Unexpected order %v", tracks)
+	}
+}
@@ -0,0 +1,105 @@
+package spotify
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ResolveID extracts the ID from s, which may be a bare ID, a Spotify URI
+// such as "spotify:track:6rqhFgbbKwnb9MLmUQDhG6", or a share URL such as
+// "https://open.spotify.com/track/6rqhFgbbKwnb9MLmUQDhG6?si=abc".  It also
+// returns the type of object identified, such as "track" or "playlist", which
+// is empty for a bare ID.
+//
+// Legacy playlist URIs and URLs that include the owner, like
+// "spotify:user:wizzler:playlist:...", resolve to the playlist.  Shortened
+// spotify.link URLs can't be resolved without following the redirect, and
+// result in an error.
+//
+// Methods that take IDs, such as [Client.GetTrack], [Client.GetShow] and the
+// playlist and library methods, resolve them this way, so URIs and share URLs
+// can be passed to them directly.  They return an error for URIs and URLs of another
+// type, such as an album URI passed to GetTrack:
+//
+//	track, err := client.GetTrack(ctx, "https://open.spotify.com/track/6rqhFgbbKwnb9MLmUQDhG6")
+func ResolveID(s string) (ID, string, error) {
+	s = strings.TrimSpace(s)
+	var segments []string
+	switch {
+	case strings.HasPrefix(s, "spotify:"):
+		segments = strings.Split(strings.TrimPrefix(s, "spotify:"), ":")
+	case strings.Contains(s, "://"):
+		u, err := url.Parse(s)
+		if err != nil {
+			return "", "", fmt.Errorf("spotify: invalid URL %q: %v", s, err)
+		}
+		if u.Host != "open.spotify.com" && u.Host != "play.spotify.com" {
+			return "", "", fmt.Errorf("spotify: %q isn't an open.spotify.com URL", s)
+		}
+		segments = strings.Split(strings.Trim(u.Path, "/"), "/")
+		// localized links look like /intl-de/track/...
+		if len(segments) > 0 && strings.HasPrefix(segments[0], "intl-") {
+			segments = segments[1:]
+		}
+	default:
+		if s == "" {
+			return "", "", errors.New("spotify: empty ID")
+		}
+		if strings.ContainsAny(s, ":/?# ") {
+			return "", "", fmt.Errorf("spotify: invalid ID %q", s)
+		}
+		return ID(s), "", nil
+	}
+
+	// spotify:user:<owner>:playlist:<id>
+	if len(segments) == 4 && segments[0] == "user" && segments[2] == "playlist" {
+		segments = segments[2:]
+	}
+	if len(segments) != 2 || segments[0] == "" || segments[1] == "" {
+		return "", "", fmt.Errorf("spotify: can't find an ID in %q", s)
+	}
+	return ID(segments[1]), segments[0], nil
+}
+
+// resolveID returns the ID identified by id, which may be a URI or share URL
+// for an object of the given kind, such as "track".  If id identifies an
+// object of another kind, an error is returned.  IDs that can't be resolved
+// are returned unchanged, so that the Web API reports the error.
+func resolveID(id ID, kind string) (ID, error) {
+	resolved, t, err := ResolveID(string(id))
+	if err != nil {
+		return id, nil
+	}
+	if t != "" && t != kind {
+		return "", fmt.Errorf("spotify: %q identifies a %s, not a %s", id, t, kind)
+	}
+	return resolved, nil
+}
+
+// resolveIDs is like resolveID for several IDs.  ids isn't modified.
+func resolveIDs(ids []ID, kind string) ([]ID, error) {
+	resolved := make([]ID, len(ids))
+	for i, id := range ids {
+		var err error
+		if resolved[i], err = resolveID(id, kind); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// resolveTrackURIs returns the track URIs for ids, which may be URIs or share
+// URLs for tracks.
+func resolveTrackURIs(ids []ID) ([]string, error) {
+	resolved, err := resolveIDs(ids, "track")
+	if err != nil {
+		return nil, err
+	}
+	uris := make([]string, len(resolved))
+	for i, id := range resolved {
+		uris[i] = "spotify:track:" + string(id)
+	}
+	return uris, nil
+}
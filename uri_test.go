@@ -0,0 +1,114 @@
+package spotify
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestResolveID(t *testing.T) {
+	tests := []struct {
+		in, id, kind string
+	}{
+		{"6rqhFgbbKwnb9MLmUQDhG6", "6rqhFgbbKwnb9MLmUQDhG6", ""},
+		{"spotify:track:6rqhFgbbKwnb9MLmUQDhG6", "6rqhFgbbKwnb9MLmUQDhG6", "track"},
+		{"spotify:user:wizzler:playlist:37i9dQZF1DXcBWIGoYBM5M", "37i9dQZF1DXcBWIGoYBM5M", "playlist"},
+		{"https://open.spotify.com/album/0sNOF9WDwhWunNAHPD3Baj?si=abc", "0sNOF9WDwhWunNAHPD3Baj", "album"},
+		{"https://open.spotify.com/intl-de/track/6rqhFgbbKwnb9MLmUQDhG6", "6rqhFgbbKwnb9MLmUQDhG6", "track"},
+		{" https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M/ ", "37i9dQZF1DXcBWIGoYBM5M", "playlist"},
+	}
+	for _, test := range tests {
+		id, kind, err := ResolveID(test.in)
+		if err != nil {
+			t.Errorf("%q: %v", test.in, err)
+			continue
+		}
+		if string(id) != test.id || kind != test.kind {
+			t.Errorf("%q: expected %s %s, got %s %s", test.in, test.kind, test.id, kind, id)
+		}
+	}
+
+	for _, in := range []string{"", "spotify:track", "https://spotify.link/abc", "https://open.spotify.com/track", "a/b"} {
+		if _, _, err := ResolveID(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
+
+func TestGetTrackByURL(t *testing.T) {
+	client, server := testClientString(http.StatusOK, `{"id": "6rqhFgbbKwnb9MLmUQDhG6"}`, func(r *http.Request) {
+		if r.URL.Path != "/tracks/6rqhFgbbKwnb9MLmUQDhG6" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	if _, err := client.GetTrack(context.Background(), "https://open.spotify.com/track/6rqhFgbbKwnb9MLmUQDhG6?si=x"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetShowEpisodesByURL(t *testing.T) {
+	client, server := testClientString(http.StatusOK, `{"items": []}`, func(r *http.Request) {
+		if r.URL.Path != "/shows/5CfCWKI5pZ28U0uOzXkDHe/episodes" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	if _, err := client.GetShowEpisodesByID(context.Background(), "https://open.spotify.com/show/5CfCWKI5pZ28U0uOzXkDHe"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddTracksToPlaylistByURI(t *testing.T) {
+	client, server := testClientString(http.StatusCreated, `{"snapshot_id": "abc"}`, func(r *http.Request) {
+		if r.URL.Path != "/playlists/37i9dQZF1DXcBWIGoYBM5M/tracks" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	_, err := client.AddTracksToPlaylist(context.Background(), "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M", "spotify:track:6rqhFgbbKwnb9MLmUQDhG6")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveIDWrongType(t *testing.T) {
+	var requests int
+	client, server := testClientString(http.StatusOK, `{}`, func(*http.Request) {
+		requests++
+	})
+	defer server.Close()
+	ctx := context.Background()
+
+	if _, err := client.GetTrack(ctx, "spotify:album:0sNOF9WDwhWunNAHPD3Baj"); err == nil {
+		t.Error("Expected an error for an album URI passed to GetTrack")
+	}
+	if _, err := client.AddTracksToPlaylist(ctx, "37i9dQZF1DXcBWIGoYBM5M", "spotify:episode:512ojhOuo1ktJprKbVcKyQ"); err == nil {
+		t.Error("Expected an error for an episode URI passed to AddTracksToPlaylist")
+	}
+	if _, err := client.GetPlaylist(ctx, "https://open.spotify.com/album/0sNOF9WDwhWunNAHPD3Baj"); err == nil {
+		t.Error("Expected an error for an album URL passed to GetPlaylist")
+	}
+	if _, err := client.GetArtist(ctx, "spotify:track:6rqhFgbbKwnb9MLmUQDhG6"); err == nil {
+		t.Error("Expected an error for a track URI passed to GetArtist")
+	}
+	if _, err := client.GetShow(ctx, "https://open.spotify.com/episode/512ojhOuo1ktJprKbVcKyQ"); err == nil {
+		t.Error("Expected an error for an episode URL passed to GetShow")
+	}
+	if _, err := client.GetEpisodeByID(ctx, "spotify:show:5CfCWKI5pZ28U0uOzXkDHe"); err == nil {
+		t.Error("Expected an error for a show URI passed to GetEpisodeByID")
+	}
+	if err := client.FollowArtist(ctx, "spotify:user:wizzler"); err == nil {
+		t.Error("Expected an error for a user URI passed to FollowArtist")
+	}
+	if requests != 0 {
+		t.Errorf("Expected no requests, got %d", requests)
+	}
+
+	if got := NewTrackToRemove("spotify:episode:512ojhOuo1ktJprKbVcKyQ", nil).URI; got != "spotify:episode:512ojhOuo1ktJprKbVcKyQ" {
+		t.Errorf("Expected the episode URI to be kept, got %s", got)
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"strings"
+	"sync"
 )
 
 // User contains the basic, publicly available information about a Spotify user.
@@ -24,7 +24,7 @@ type User struct {
 	// The Spotify user ID for the user.
 	ID string `json:"id"`
 	// The user's profile image.
-	Images []Image `json:"images"`
+	Images Images `json:"images"`
 	// The Spotify URI for the user.
 	URI URI `json:"uri"`
 }
@@ -54,18 +54,66 @@ type PrivateUser struct {
 	// available when the current user has granted access to the
 	// [ScopeUserReadBirthdate] scope.
 	Birthdate string `json:"birthdate"`
+	// The user's explicit content settings.  This field is only available
+	// when the current user has granted access to the [ScopeUserReadPrivate]
+	// scope.
+	ExplicitContent ExplicitContentSettings `json:"explicit_content"`
+}
+
+// ExplicitContentSettings contains a user's settings for explicit content.
+type ExplicitContentSettings struct {
+	// FilterEnabled is true when the user doesn't want to play explicit content.
+	FilterEnabled bool `json:"filter_enabled"`
+	// FilterLocked is true when the user can't change the setting, for
+	// example because it's set by a parent on a family plan.
+	FilterLocked bool `json:"filter_locked"`
+}
+
+// ErrUserNotFound is matched by errors.Is when a user doesn't exist.
+var ErrUserNotFound = errors.New("spotify: user not found")
+
+// UserNotFoundError is returned by [Client.GetUsersPublicProfile] when
+// Spotify reports that the user doesn't exist.
+type UserNotFoundError struct {
+	UserID ID
+	Err    Error
+}
+
+func (e UserNotFoundError) Error() string {
+	return fmt.Sprintf("spotify: user %s not found", e.UserID)
+}
+
+// Unwrap returns the underlying [Error].
+func (e UserNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is [ErrUserNotFound].
+func (e UserNotFoundError) Is(target error) bool {
+	return target == ErrUserNotFound
 }
 
 // GetUsersPublicProfile gets [public profile] information about a
-// Spotify User.  It does not require authentication.
+// Spotify User.  It does not require authentication.  If the user doesn't
+// exist, a [UserNotFoundError] is returned.
 //
 // [public profile]: https://developer.spotify.com/documentation/web-api/reference/get-users-profile
-func (c *Client) GetUsersPublicProfile(ctx context.Context, userID ID) (*User, error) {
-	spotifyURL := c.baseURL + "users/" + string(userID)
+func (c *Client) GetUsersPublicProfile(ctx context.Context, userID ID, opts ...RequestOption) (*User, error) {
+	userID, err := resolveID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	o := processOptions(opts...)
+	ctx = o.context(ctx)
+	spotifyURL := c.apiURL(o.urlParams, "users/", string(userID))
 
 	var user User
 
-	err := c.get(ctx, spotifyURL, &user)
+	err = c.get(ctx, spotifyURL, &user)
+	var e Error
+	if errors.As(err, &e) && e.Status == http.StatusNotFound {
+		return nil, UserNotFoundError{UserID: userID, Err: e}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +121,66 @@ func (c *Client) GetUsersPublicProfile(ctx context.Context, userID ID) (*User, e
 	return &user, nil
 }
 
+// publicProfileConcurrency is the number of profiles that
+// GetUsersPublicProfiles fetches at once.
+const publicProfileConcurrency = 4
+
+// GetUsersPublicProfiles gets the public profiles of several users.  The API
+// has no endpoint for several users, so up to four profiles are fetched at
+// once.  Profiles are returned in the order requested; if a user doesn't
+// exist, that position in the result is nil.  If any other request fails,
+// the remaining requests are cancelled and the first error is returned.
+func (c *Client) GetUsersPublicProfiles(ctx context.Context, ids ...ID) ([]*User, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		users    = make([]*User, len(ids))
+		work     = make(chan int)
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for i := 0; i < publicProfileConcurrency && i < len(ids); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				user, err := c.GetUsersPublicProfile(ctx, ids[i])
+				if err != nil && !errors.Is(err, ErrUserNotFound) {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+					mu.Unlock()
+					continue
+				}
+				users[i] = user
+			}
+		}()
+	}
+
+feed:
+	for i := range ids {
+		select {
+		case work <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // CurrentUser gets detailed profile information about the
 // [current user].
 //
@@ -106,13 +214,18 @@ func (c *Client) CurrentUser(ctx context.Context) (*PrivateUser, error) {
 // [list of shows]: https://developer.spotify.com/documentation/web-api/reference/get-users-saved-shows
 func (c *Client) CurrentUsersShows(ctx context.Context, opts ...RequestOption) (*SavedShowPage, error) {
 	spotifyURL := c.baseURL + "me/shows"
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	if params := o.urlParams.Encode(); params != "" {
 		spotifyURL += "?" + params
 	}
 
 	var result SavedShowPage
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -120,6 +233,35 @@ func (c *Client) CurrentUsersShows(ctx context.Context, opts ...RequestOption) (
 	return &result, nil
 }
 
+// CurrentUsersShowsAll gets every show saved in the current Spotify user's
+// "Your Music" library, following the pages of [Client.CurrentUsersShows].
+// If progress isn't nil, it is called after each page.
+//
+// Supported options: [Limit], [Offset].
+func (c *Client) CurrentUsersShowsAll(ctx context.Context, progress ProgressFunc, opts ...RequestOption) ([]SavedShow, error) {
+	page, err := c.CurrentUsersShows(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	shows := make([]SavedShow, 0, page.Total)
+	for {
+		shows = append(shows, page.Shows...)
+		if progress != nil {
+			progress(len(shows), int(page.Total))
+		}
+		err = c.NextPage(ctx, page)
+		if errors.Is(err, ErrNoMorePages) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return shows, nil
+}
+
 // CurrentUsersTracks gets a [list of songs] saved in the current
 // Spotify user's "Your Music" library.
 //
@@ -128,16 +270,22 @@ func (c *Client) CurrentUsersShows(ctx context.Context, opts ...RequestOption) (
 // [list of songs]: https://developer.spotify.com/documentation/web-api/reference/get-users-saved-tracks
 func (c *Client) CurrentUsersTracks(ctx context.Context, opts ...RequestOption) (*SavedTrackPage, error) {
 	spotifyURL := c.baseURL + "me/tracks"
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	if params := o.urlParams.Encode(); params != "" {
 		spotifyURL += "?" + params
 	}
 
 	var result SavedTrackPage
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
+	c.resolveRelinks(&result)
 
 	return &result, nil
 }
@@ -151,7 +299,7 @@ func (c *Client) CurrentUsersTracks(ctx context.Context, opts ...RequestOption)
 // [adds the current user as a follower]: https://developer.spotify.com/documentation/web-api/reference/follow-artists-users
 // [Spotify ID]: https://developer.spotify.com/documentation/web-api/#spotify-uris-and-ids
 func (c *Client) FollowUser(ctx context.Context, ids ...ID) error {
-	return c.modifyFollowers(ctx, "user", true, ids...)
+	return c.modifyFollowers(ctx, FollowTypeUser, true, ids...)
 }
 
 // FollowArtist [adds the current user as a follower] of one or more
@@ -163,7 +311,7 @@ func (c *Client) FollowUser(ctx context.Context, ids ...ID) error {
 // [adds the current user as a follower]: https://developer.spotify.com/documentation/web-api/reference/follow-artists-users
 // [Spotify ID]: https://developer.spotify.com/documentation/web-api/#spotify-uris-and-ids
 func (c *Client) FollowArtist(ctx context.Context, ids ...ID) error {
-	return c.modifyFollowers(ctx, "artist", true, ids...)
+	return c.modifyFollowers(ctx, FollowTypeArtist, true, ids...)
 }
 
 // UnfollowUser [removes the current user as a follower] of one or more
@@ -174,7 +322,7 @@ func (c *Client) FollowArtist(ctx context.Context, ids ...ID) error {
 //
 // [removes the current user as a follower]: https://developer.spotify.com/documentation/web-api/reference/unfollow-artists-users
 func (c *Client) UnfollowUser(ctx context.Context, ids ...ID) error {
-	return c.modifyFollowers(ctx, "user", false, ids...)
+	return c.modifyFollowers(ctx, FollowTypeUser, false, ids...)
 }
 
 // UnfollowArtist [removes the current user as a follower] of one or more
@@ -185,33 +333,55 @@ func (c *Client) UnfollowUser(ctx context.Context, ids ...ID) error {
 //
 // [removes the current user as a follower]: https://developer.spotify.com/documentation/web-api/reference/unfollow-artists-users
 func (c *Client) UnfollowArtist(ctx context.Context, ids ...ID) error {
-	return c.modifyFollowers(ctx, "artist", false, ids...)
+	return c.modifyFollowers(ctx, FollowTypeArtist, false, ids...)
 }
 
+// FollowType is the type of the IDs passed to [Client.CurrentUserFollowsType].
+type FollowType string
+
+const (
+	FollowTypeArtist FollowType = "artist"
+	FollowTypeUser   FollowType = "user"
+)
+
 // CurrentUserFollows [checks to see if the current user is following]
 // one or more artists or other Spotify Users.  This call requires
 // [ScopeUserFollowRead].
 //
 // The t argument indicates the type of the IDs, and must be either
-// "user" or "artist".
+// "user" or "artist".  [Client.CurrentUserFollowsType] should be preferred,
+// as it checks the type at compile time.
 //
 // The result is returned as a slice of bool values in the same order
 // in which the IDs were specified.
 //
 // [checks to see if the current user is following]: https://developer.spotify.com/documentation/web-api/reference/check-current-user-follows
 func (c *Client) CurrentUserFollows(ctx context.Context, t string, ids ...ID) ([]bool, error) {
+	if t != string(FollowTypeArtist) && t != string(FollowTypeUser) {
+		return nil, errors.New("spotify: t must be 'artist' or 'user'")
+	}
+	return c.CurrentUserFollowsType(ctx, FollowType(t), ids...)
+}
+
+// CurrentUserFollowsType [checks to see if the current user is following]
+// one or more artists or other Spotify Users, depending on t.  This call
+// requires [ScopeUserFollowRead].
+//
+// The result is returned as a slice of bool values in the same order
+// in which the IDs were specified.
+func (c *Client) CurrentUserFollowsType(ctx context.Context, t FollowType, ids ...ID) ([]bool, error) {
 	if l := len(ids); l == 0 || l > 50 {
 		return nil, errors.New("spotify: UserFollows supports 1 to 50 IDs")
 	}
-	if t != "artist" && t != "user" {
-		return nil, errors.New("spotify: t must be 'artist' or 'user'")
+	ids, err := resolveIDs(ids, string(t))
+	if err != nil {
+		return nil, err
 	}
-	spotifyURL := fmt.Sprintf("%sme/following/contains?type=%s&ids=%s",
-		c.baseURL, t, strings.Join(toStringSlice(ids), ","))
+	spotifyURL := c.apiURL(url.Values{"type": {string(t)}, "ids": {joinIDs(ids)}}, "me/following/contains")
 
 	var result []bool
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -219,14 +389,15 @@ func (c *Client) CurrentUserFollows(ctx context.Context, t string, ids ...ID) ([
 	return result, nil
 }
 
-func (c *Client) modifyFollowers(ctx context.Context, usertype string, follow bool, ids ...ID) error {
+func (c *Client) modifyFollowers(ctx context.Context, usertype FollowType, follow bool, ids ...ID) error {
 	if l := len(ids); l == 0 || l > 50 {
 		return errors.New("spotify: Follow/Unfollow supports 1 to 50 IDs")
 	}
-	v := url.Values{}
-	v.Add("type", usertype)
-	v.Add("ids", strings.Join(toStringSlice(ids), ","))
-	spotifyURL := c.baseURL + "me/following?" + v.Encode()
+	ids, err := resolveIDs(ids, string(usertype))
+	if err != nil {
+		return err
+	}
+	spotifyURL := c.apiURL(url.Values{"type": {string(usertype)}, "ids": {joinIDs(ids)}}, "me/following")
 	method := "PUT"
 	if !follow {
 		method = "DELETE"
@@ -238,6 +409,63 @@ func (c *Client) modifyFollowers(ctx context.Context, usertype string, follow bo
 	return c.execute(req, nil, http.StatusNoContent)
 }
 
+// FollowUserBatched is like [Client.FollowUser], but accepts any number of IDs.
+// They are sent in batches of 50, one request at a time.  A batch that is
+// rate limited is retried once the Retry-After period has passed; other
+// failures don't stop the remaining batches from being sent, and are reported
+// together in a [*BatchError].
+func (c *Client) FollowUserBatched(ctx context.Context, ids ...ID) error {
+	return c.modifyFollowersBatched(ctx, FollowTypeUser, true, ids)
+}
+
+// FollowArtistBatched is like [Client.FollowArtist], but accepts any number of
+// IDs.  They are sent in batches of 50, one request at a time.
+func (c *Client) FollowArtistBatched(ctx context.Context, ids ...ID) error {
+	return c.modifyFollowersBatched(ctx, FollowTypeArtist, true, ids)
+}
+
+// UnfollowUserBatched is like [Client.UnfollowUser], but accepts any number of
+// IDs.  They are sent in batches of 50, one request at a time.
+func (c *Client) UnfollowUserBatched(ctx context.Context, ids ...ID) error {
+	return c.modifyFollowersBatched(ctx, FollowTypeUser, false, ids)
+}
+
+// UnfollowArtistBatched is like [Client.UnfollowArtist], but accepts any number
+// of IDs.  They are sent in batches of 50, one request at a time.
+func (c *Client) UnfollowArtistBatched(ctx context.Context, ids ...ID) error {
+	return c.modifyFollowersBatched(ctx, FollowTypeArtist, false, ids)
+}
+
+// modifyFollowersBatched splits ids into batches of 50 and sends them one at a
+// time, collecting failures into a [*BatchError].
+func (c *Client) modifyFollowersBatched(ctx context.Context, usertype FollowType, follow bool, ids []ID) error {
+	if len(ids) == 0 {
+		return errors.New("spotify: Follow/Unfollow requires at least 1 ID")
+	}
+	var batchErr BatchError
+	for start := 0; start < len(ids); start += 50 {
+		end := start + 50
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[start:end]
+		err := RetryRateLimited(ctx, func() error {
+			return c.modifyFollowers(ctx, usertype, follow, batch...)
+		})
+		if err != nil {
+			batchErr.Failures = append(batchErr.Failures, BatchFailure{IDs: batch, Err: err})
+		}
+		if ctx.Err() != nil {
+			batchErr.Failures = append(batchErr.Failures, BatchFailure{IDs: ids[end:], Err: ctx.Err()})
+			break
+		}
+	}
+	if len(batchErr.Failures) > 0 {
+		return &batchErr
+	}
+	return nil
+}
+
 // CurrentUsersFollowedArtists gets the [current user's followed artists].
 // This call requires that the user has granted the [ScopeUserFollowRead] scope.
 //
@@ -246,7 +474,12 @@ func (c *Client) modifyFollowers(ctx context.Context, usertype string, follow bo
 // [current user's followed artists]: https://developer.spotify.com/documentation/web-api/reference/get-followed
 func (c *Client) CurrentUsersFollowedArtists(ctx context.Context, opts ...RequestOption) (*FullArtistCursorPage, error) {
 	spotifyURL := c.baseURL + "me/following"
-	v := processOptions(opts...).urlParams
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	v := o.urlParams
 	v.Set("type", "artist")
 	if params := v.Encode(); params != "" {
 		spotifyURL += "?" + params
@@ -256,7 +489,7 @@ func (c *Client) CurrentUsersFollowedArtists(ctx context.Context, opts ...Reques
 		A FullArtistCursorPage `json:"artists"`
 	}
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -264,6 +497,40 @@ func (c *Client) CurrentUsersFollowedArtists(ctx context.Context, opts ...Reques
 	return &result.A, nil
 }
 
+// ProgressFunc is called by methods that fetch several pages after each page
+// is received, with the number of items fetched so far and the total number of
+// items reported by Spotify.
+type ProgressFunc func(fetched, total int)
+
+// CurrentUsersAlbumsAll gets every album saved in the current Spotify user's
+// "Your Music" library, following the pages of [Client.CurrentUsersAlbums].
+// If progress isn't nil, it is called after each page.
+//
+// Supported options: [Market], [Limit], [Offset].
+func (c *Client) CurrentUsersAlbumsAll(ctx context.Context, progress ProgressFunc, opts ...RequestOption) ([]SavedAlbum, error) {
+	page, err := c.CurrentUsersAlbums(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	albums := make([]SavedAlbum, 0, page.Total)
+	for {
+		albums = append(albums, page.Albums...)
+		if progress != nil {
+			progress(len(albums), int(page.Total))
+		}
+		err = c.NextPage(ctx, page)
+		if errors.Is(err, ErrNoMorePages) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return albums, nil
+}
+
 // CurrentUsersAlbums gets a [list of albums] saved in the current
 // Spotify user's "Your Music" library.
 //
@@ -272,13 +539,18 @@ func (c *Client) CurrentUsersFollowedArtists(ctx context.Context, opts ...Reques
 // [list of albums]: https://developer.spotify.com/documentation/web-api/reference/get-users-saved-albums
 func (c *Client) CurrentUsersAlbums(ctx context.Context, opts ...RequestOption) (*SavedAlbumPage, error) {
 	spotifyURL := c.baseURL + "me/albums"
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	if params := o.urlParams.Encode(); params != "" {
 		spotifyURL += "?" + params
 	}
 
 	var result SavedAlbumPage
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -299,13 +571,18 @@ func (c *Client) CurrentUsersAlbums(ctx context.Context, opts ...RequestOption)
 // [list of the playlists]: https://developer.spotify.com/documentation/web-api/reference/get-a-list-of-current-users-playlists
 func (c *Client) CurrentUsersPlaylists(ctx context.Context, opts ...RequestOption) (*SimplePlaylistPage, error) {
 	spotifyURL := c.baseURL + "me/playlists"
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	if params := o.urlParams.Encode(); params != "" {
 		spotifyURL += "?" + params
 	}
 
 	var result SimplePlaylistPage
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -321,13 +598,18 @@ func (c *Client) CurrentUsersPlaylists(ctx context.Context, opts ...RequestOptio
 // [user's top artists]: https://developer.spotify.com/documentation/web-api/reference/get-users-top-artists-and-tracks
 func (c *Client) CurrentUsersTopArtists(ctx context.Context, opts ...RequestOption) (*FullArtistPage, error) {
 	spotifyURL := c.baseURL + "me/top/artists"
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	if params := o.urlParams.Encode(); params != "" {
 		spotifyURL += "?" + params
 	}
 
 	var result FullArtistPage
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -344,13 +626,18 @@ func (c *Client) CurrentUsersTopArtists(ctx context.Context, opts ...RequestOpti
 // [user's top tracks]: https://developer.spotify.com/documentation/web-api/reference/get-users-top-artists-and-tracks
 func (c *Client) CurrentUsersTopTracks(ctx context.Context, opts ...RequestOption) (*FullTrackPage, error) {
 	spotifyURL := c.baseURL + "me/top/tracks"
-	if params := processOptions(opts...).urlParams.Encode(); params != "" {
+	o, err := c.processPagingOptions(50, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	if params := o.urlParams.Encode(); params != "" {
 		spotifyURL += "?" + params
 	}
 
 	var result FullTrackPage
 
-	err := c.get(ctx, spotifyURL, &result)
+	err = c.get(ctx, spotifyURL, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -2,6 +2,7 @@ package spotify
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -101,10 +102,20 @@ func TestFollowUsersMissingScope(t *testing.T) {
 	defer server.Close()
 
 	err := client.FollowUser(context.Background(), ID("exampleuser01"))
-	serr, ok := err.(Error)
-	if !ok {
+	// scope errors are no longer returned as a plain Error
+	if _, ok := err.(Error); ok {
+		t.Error("Expected the Error to be wrapped")
+	}
+	if _, ok := err.(InsufficientScopeError); !ok {
+		t.Errorf("Expected an InsufficientScopeError, got %T", err)
+	}
+	var serr Error
+	if !errors.As(err, &serr) {
 		t.Fatal("Expected insufficient client scope error")
 	}
+	if !errors.Is(err, ErrInsufficientScope) {
+		t.Error("Expected ErrInsufficientScope")
+	}
 	if serr.Status != http.StatusForbidden {
 		t.Error("Expected HTTP 403")
 	}
@@ -452,3 +463,142 @@ func TestCurrentUsersTopTracks(t *testing.T) {
 		t.Errorf("Wrong ISRC: want %s, got %s\n", isrc, i)
 	}
 }
+
+func TestFollowArtistBatched(t *testing.T) {
+	var batches []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := strings.Split(r.URL.Query().Get("ids"), ",")
+		batches = append(batches, len(ids))
+		if len(batches) == 2 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error": {"status": 400, "message": "invalid id"}}`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	ids := make([]ID, 120)
+	for i := range ids {
+		ids[i] = ID(fmt.Sprintf("artist%d", i))
+	}
+	err := client.FollowArtistBatched(context.Background(), ids...)
+	if len(batches) != 3 || batches[0] != 50 || batches[2] != 20 {
+		t.Errorf("Unexpected batches %v", batches)
+	}
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a BatchError, got %v", err)
+	}
+	if len(batchErr.Failures) != 1 || batchErr.Failures[0].IDs[0] != "artist50" {
+		t.Errorf("Unexpected failures %+v", batchErr.Failures)
+	}
+	var se Error
+	if !errors.As(err, &se) || se.Status != http.StatusBadRequest {
+		t.Errorf("Expected the underlying error to be available, got %v", err)
+	}
+}
+
+func TestCurrentUserFollowsType(t *testing.T) {
+	client, server := testClientString(http.StatusOK, `[true]`, func(r *http.Request) {
+		if got := r.URL.Query().Get("type"); got != "user" {
+			t.Errorf("Expected type user, got %q", got)
+		}
+	})
+	defer server.Close()
+
+	follows, err := client.CurrentUserFollowsType(context.Background(), FollowTypeUser, "exampleuser01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(follows) != 1 || !follows[0] {
+		t.Errorf("Unexpected result %v", follows)
+	}
+}
+
+func TestCurrentUsersAlbumsAll(t *testing.T) {
+	client, server := testClientFile(http.StatusOK, "test_data/current_users_albums.txt")
+	defer server.Close()
+
+	var calls []int
+	albums, err := client.CurrentUsersAlbumsAll(context.Background(), func(fetched, total int) {
+		calls = append(calls, fetched, total)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(albums) != 2 {
+		t.Errorf("Expected 2 albums, got %d", len(albums))
+	}
+	if len(calls) != 2 || calls[0] != 2 || calls[1] != 2 {
+		t.Errorf("Unexpected progress calls %v", calls)
+	}
+}
+
+func TestUserProfileNotFound(t *testing.T) {
+	client, server := testClientString(http.StatusNotFound, `{"error": {"status": 404, "message": "No such user"}}`)
+	defer server.Close()
+
+	_, err := client.GetUsersPublicProfile(context.Background(), "nobody")
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("Expected ErrUserNotFound, got %v", err)
+	}
+	var e UserNotFoundError
+	if !errors.As(err, &e) || e.UserID != "nobody" || e.Err.Status != http.StatusNotFound {
+		t.Errorf("Expected UserNotFoundError for nobody, got %#v", err)
+	}
+}
+
+func TestGetUsersPublicProfiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/users/")
+		if id == "nobody" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"status": 404, "message": "No such user"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"id": %q}`, id)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	ids := []ID{"a", "b", "nobody", "c", "d", "e"}
+	users, err := client.GetUsersPublicProfiles(context.Background(), ids...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != len(ids) {
+		t.Fatalf("Expected %d users, got %d", len(ids), len(users))
+	}
+	for i, id := range ids {
+		if id == "nobody" {
+			if users[i] != nil {
+				t.Errorf("Expected nil for missing user, got %v", users[i])
+			}
+			continue
+		}
+		if users[i] == nil || users[i].ID != string(id) {
+			t.Errorf("Expected user %s at %d, got %v", id, i, users[i])
+		}
+	}
+}
+
+func TestGetUsersPublicProfilesError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": {"status": 400, "message": "Invalid username"}}`)
+	}))
+	defer server.Close()
+	client := New(http.DefaultClient, WithBaseURL(server.URL+"/"))
+
+	users, err := client.GetUsersPublicProfiles(context.Background(), "a", "b")
+	var e Error
+	if !errors.As(err, &e) || e.Status != http.StatusBadRequest {
+		t.Errorf("Expected a 400 error, got %v", err)
+	}
+	if users != nil {
+		t.Errorf("Expected no users, got %v", users)
+	}
+}
@@ -0,0 +1,127 @@
+package watch
+
+import (
+	"github.com/zmb3/spotify/v2"
+	"github.com/zmb3/spotify/v2/internal/lis"
+)
+
+// diff returns the events that describe the changes between two snapshots.
+func diff(old, new *snapshot) []Event {
+	var events []Event
+
+	oldTracks := make(map[spotify.ID]bool, len(old.tracks))
+	for _, t := range old.tracks {
+		oldTracks[t.ID] = true
+	}
+	newTracks := make(map[spotify.ID]bool, len(new.tracks))
+	for _, t := range new.tracks {
+		newTracks[t.ID] = true
+		if !oldTracks[t.ID] {
+			events = append(events, TrackSaved{Track: t})
+		}
+	}
+	for _, t := range old.tracks {
+		if !newTracks[t.ID] {
+			events = append(events, TrackRemoved{Track: t})
+		}
+	}
+
+	oldArtists := make(map[spotify.ID]bool, len(old.artists))
+	for _, a := range old.artists {
+		oldArtists[a.ID] = true
+	}
+	newArtists := make(map[spotify.ID]bool, len(new.artists))
+	for _, a := range new.artists {
+		newArtists[a.ID] = true
+		if !oldArtists[a.ID] {
+			events = append(events, ArtistFollowed{Artist: a})
+		}
+	}
+	for _, a := range old.artists {
+		if !newArtists[a.ID] {
+			events = append(events, ArtistUnfollowed{Artist: a})
+		}
+	}
+
+	oldPlaylists := make(map[spotify.ID]playlistSnapshot, len(old.playlists))
+	for _, p := range old.playlists {
+		oldPlaylists[p.playlist.ID] = p
+	}
+	for _, p := range new.playlists {
+		prev := oldPlaylists[p.playlist.ID]
+		if prev.playlist.SnapshotID == p.playlist.SnapshotID {
+			continue
+		}
+		events = append(events, diffPlaylist(prev, p)...)
+	}
+
+	return events
+}
+
+// diffPlaylist compares the items of two versions of a playlist.  A playlist
+// may contain the same item more than once, so each occurrence in the new
+// version is matched with an unmatched occurrence in the old version, in
+// order.  Matched items whose order relative to the others changed are
+// reported as moved.
+func diffPlaylist(old, new playlistSnapshot) []Event {
+	var events []Event
+
+	positions := make(map[spotify.URI][]int, len(old.items))
+	for i, item := range old.items {
+		if uri := item.URI(); uri != "" {
+			positions[uri] = append(positions[uri], i)
+		}
+	}
+	matched := make([]bool, len(old.items))
+	var kept []move
+	for i, item := range new.items {
+		uri := item.URI()
+		if uri == "" {
+			continue
+		}
+		if p := positions[uri]; len(p) > 0 {
+			positions[uri] = p[1:]
+			matched[p[0]] = true
+			kept = append(kept, move{from: p[0], to: i})
+			continue
+		}
+		events = append(events, PlaylistItemAdded{Playlist: new.playlist, Item: item, Position: i})
+	}
+	// whatever wasn't matched was removed
+	for i, item := range old.items {
+		if !matched[i] && item.URI() != "" {
+			events = append(events, PlaylistItemRemoved{Playlist: new.playlist, Item: item, Position: i})
+		}
+	}
+	for _, m := range moved(kept) {
+		events = append(events, PlaylistItemMoved{Playlist: new.playlist, Item: new.items[m.to], From: m.from, To: m.to})
+	}
+
+	return events
+}
+
+// move records the position of an item in the old and new versions of a
+// playlist.
+type move struct {
+	from, to int
+}
+
+// moved returns the items that were moved, given the kept items in their new
+// order.  The items whose old positions form the longest increasing
+// subsequence kept their relative order; the others are the fewest items
+// that have to be moved to explain the new order.
+func moved(kept []move) []move {
+	from := make([]int, len(kept))
+	for i, m := range kept {
+		from[i] = m.from
+	}
+	inOrder := lis.Longest(from)
+
+	var result []move
+	for i, m := range kept {
+		if !inOrder[i] {
+			result = append(result, m)
+		}
+	}
+	return result
+}
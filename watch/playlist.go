@@ -0,0 +1,92 @@
+package watch
+
+import (
+	"context"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// playlistHeadFields selects the fields of a playlist that are requested on
+// every poll by [WatchPlaylist].
+const playlistHeadFields = "collaborative,description,external_urls,href,id,images,name,owner,public,snapshot_id,uri"
+
+// WatchPlaylist watches a single playlist for changes, which makes it a
+// building block for bots that manage collaborative playlists.  Every
+// interval, it requests the playlist's snapshot ID, and only when that has
+// changed does it fetch the items and emit a [PlaylistItemAdded],
+// [PlaylistItemRemoved] or [PlaylistItemMoved] event for each difference.
+// If polling fails, an [Error] is emitted and the next poll is compared
+// against the last successful one.  Intervals that aren't positive are
+// replaced by [DefaultInterval].
+//
+// The playlist is fetched once before WatchPlaylist returns, and an error is
+// returned if that fails.  The returned channel is closed once ctx is done.
+// Events must be received promptly, as the playlist isn't polled while an
+// event is waiting to be delivered.
+//
+// Private playlists require the [spotifyauth.ScopePlaylistReadPrivate] scope.
+func WatchPlaylist(ctx context.Context, client *spotify.Client, id spotify.ID, interval time.Duration) (<-chan Event, error) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	last, err := playlistState(ctx, client, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			var pending []Event
+			s, err := playlistState(ctx, client, id, &last)
+			switch {
+			case err != nil && ctx.Err() != nil:
+				return
+			case err != nil:
+				pending = []Event{Error{Err: err}}
+			case s.playlist.SnapshotID != last.playlist.SnapshotID:
+				pending = diffPlaylist(last, s)
+				last = s
+			}
+
+			for _, e := range pending {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// playlistState fetches the playlist and, unless its snapshot ID matches
+// that of last, its items.
+func playlistState(ctx context.Context, client *spotify.Client, id spotify.ID, last *playlistSnapshot) (playlistSnapshot, error) {
+	head, err := client.GetPlaylist(ctx, id, spotify.Fields(playlistHeadFields))
+	if err != nil {
+		return playlistSnapshot{}, err
+	}
+	if last != nil && last.playlist.SnapshotID == head.SnapshotID {
+		return *last, nil
+	}
+	items, err := playlistItems(ctx, client, id)
+	if err != nil {
+		return playlistSnapshot{}, err
+	}
+	return playlistSnapshot{playlist: head.SimplePlaylist, items: items}, nil
+}
@@ -0,0 +1,383 @@
+// Package watch provides webhook-like notifications for changes to a Spotify
+// user's library.
+//
+// The Spotify Web API doesn't push notifications when a user saves a track,
+// follows an artist or edits a playlist, so a [Watcher] periodically takes a
+// snapshot of this data, compares it to the previous one, and emits an [Event]
+// for each difference that it finds.
+//
+// Example:
+//
+//	w := watch.New(client, watch.WithInterval(time.Minute))
+//	events, err := w.Watch(ctx)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for event := range events {
+//		switch e := event.(type) {
+//		case watch.TrackSaved:
+//			fmt.Println("saved", e.Track.Name)
+//		case watch.Error:
+//			log.Println("poll failed:", e.Err)
+//		}
+//	}
+package watch
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// DefaultInterval is the time between snapshots if [WithInterval] isn't used.
+const DefaultInterval = 5 * time.Minute
+
+// Event is implemented by all of the events emitted by a [Watcher] and by
+// [WatchPlaylist]: [TrackSaved], [TrackRemoved], [ArtistFollowed],
+// [ArtistUnfollowed], [PlaylistItemAdded], [PlaylistItemRemoved],
+// [PlaylistItemMoved] and [Error].
+type Event interface {
+	event()
+}
+
+// TrackSaved is emitted when a track is added to the user's "Your Music" library.
+type TrackSaved struct {
+	Track spotify.SavedTrack
+}
+
+// TrackRemoved is emitted when a track is removed from the user's "Your Music" library.
+type TrackRemoved struct {
+	Track spotify.SavedTrack
+}
+
+// ArtistFollowed is emitted when the user follows an artist.
+type ArtistFollowed struct {
+	Artist spotify.FullArtist
+}
+
+// ArtistUnfollowed is emitted when the user stops following an artist.
+type ArtistUnfollowed struct {
+	Artist spotify.FullArtist
+}
+
+// PlaylistItemAdded is emitted when a track or episode is added to one of the
+// user's playlists.
+type PlaylistItemAdded struct {
+	Playlist spotify.SimplePlaylist
+	Item     spotify.PlaylistItem
+	// Position is the index of the item in the new version of the playlist.
+	Position int
+}
+
+// PlaylistItemRemoved is emitted when a track or episode is removed from one of
+// the user's playlists.
+type PlaylistItemRemoved struct {
+	Playlist spotify.SimplePlaylist
+	Item     spotify.PlaylistItem
+	// Position is the index the item had in the old version of the playlist.
+	Position int
+}
+
+// PlaylistItemMoved is emitted when a track or episode is moved within one of
+// the user's playlists.  When items are reordered, the fewest moves that
+// explain the new order are reported.  Items that only shifted because other
+// items were added or removed aren't reported as moved.
+type PlaylistItemMoved struct {
+	Playlist spotify.SimplePlaylist
+	Item     spotify.PlaylistItem
+	// From and To are the indices of the item in the old and new versions
+	// of the playlist.
+	From, To int
+}
+
+// Error is emitted when a snapshot could not be taken.  The watcher keeps
+// polling, and compares the next successful snapshot against the last
+// successful one.
+type Error struct {
+	Err error
+}
+
+func (TrackSaved) event()          {}
+func (TrackRemoved) event()        {}
+func (ArtistFollowed) event()      {}
+func (ArtistUnfollowed) event()    {}
+func (PlaylistItemAdded) event()   {}
+func (PlaylistItemRemoved) event() {}
+func (PlaylistItemMoved) event()   {}
+func (Error) event()               {}
+
+// Watcher periodically snapshots a user's library and reports changes.
+// You should always use [New] to make them.
+type Watcher struct {
+	client   *spotify.Client
+	interval time.Duration
+
+	tracks    bool
+	artists   bool
+	playlists bool
+	only      map[spotify.ID]bool
+
+	last *snapshot
+}
+
+// Option configures a [Watcher] made by [New].
+type Option func(w *Watcher)
+
+// WithInterval sets the time between snapshots.  Each snapshot costs at least
+// one request per watched category, plus one request per page of every
+// playlist that changed, so avoid intervals that are too short.  Intervals
+// that aren't positive are replaced by [DefaultInterval].
+func WithInterval(d time.Duration) Option {
+	return func(w *Watcher) {
+		w.interval = d
+	}
+}
+
+// WithoutTracks stops the watcher from tracking the user's saved tracks.
+func WithoutTracks() Option {
+	return func(w *Watcher) {
+		w.tracks = false
+	}
+}
+
+// WithoutArtists stops the watcher from tracking the user's followed artists.
+func WithoutArtists() Option {
+	return func(w *Watcher) {
+		w.artists = false
+	}
+}
+
+// WithoutPlaylists stops the watcher from tracking the user's playlists.
+func WithoutPlaylists() Option {
+	return func(w *Watcher) {
+		w.playlists = false
+	}
+}
+
+// WithPlaylists restricts the watcher to the specified playlists.  By default,
+// every playlist owned or followed by the user is watched.
+func WithPlaylists(ids ...spotify.ID) Option {
+	return func(w *Watcher) {
+		w.only = make(map[spotify.ID]bool, len(ids))
+		for _, id := range ids {
+			w.only[id] = true
+		}
+	}
+}
+
+// New creates a watcher for the user that client is authenticated as.
+//
+// Depending on what is being watched, the client needs the
+// [spotifyauth.ScopeUserLibraryRead], [spotifyauth.ScopeUserFollowRead] and
+// [spotifyauth.ScopePlaylistReadPrivate] scopes.
+func New(client *spotify.Client, opts ...Option) *Watcher {
+	w := &Watcher{
+		client:    client,
+		interval:  DefaultInterval,
+		tracks:    true,
+		artists:   true,
+		playlists: true,
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.interval <= 0 {
+		w.interval = DefaultInterval
+	}
+
+	return w
+}
+
+// Watch takes an initial snapshot and then starts polling for changes in the
+// background.  The initial snapshot doesn't produce any events; an error is
+// returned if it cannot be taken.
+//
+// The returned channel is closed once ctx is done.  Events must be received
+// promptly, as the watcher doesn't take a new snapshot while an event is
+// waiting to be delivered.
+func (w *Watcher) Watch(ctx context.Context) (<-chan Event, error) {
+	s, err := w.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w.last = s
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			for _, e := range w.poll(ctx) {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// poll takes a new snapshot and returns the events describing how it differs
+// from the previous one.
+func (w *Watcher) poll(ctx context.Context) []Event {
+	s, err := w.snapshot(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return []Event{Error{Err: err}}
+	}
+	events := diff(w.last, s)
+	w.last = s
+	return events
+}
+
+type snapshot struct {
+	tracks    []spotify.SavedTrack
+	artists   []spotify.FullArtist
+	playlists []playlistSnapshot
+}
+
+type playlistSnapshot struct {
+	playlist spotify.SimplePlaylist
+	items    []spotify.PlaylistItem
+}
+
+func (w *Watcher) snapshot(ctx context.Context) (*snapshot, error) {
+	var (
+		s   snapshot
+		err error
+	)
+	if w.tracks {
+		s.tracks, err = w.savedTracks(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if w.artists {
+		s.artists, err = w.followedArtists(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if w.playlists {
+		s.playlists, err = w.playlistSnapshots(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &s, nil
+}
+
+func (w *Watcher) savedTracks(ctx context.Context) ([]spotify.SavedTrack, error) {
+	page, err := w.client.CurrentUsersTracks(ctx, spotify.Limit(50))
+	if err != nil {
+		return nil, err
+	}
+	var tracks []spotify.SavedTrack
+	for {
+		tracks = append(tracks, page.Tracks...)
+		err = w.client.NextPage(ctx, page)
+		if errors.Is(err, spotify.ErrNoMorePages) {
+			return tracks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (w *Watcher) followedArtists(ctx context.Context) ([]spotify.FullArtist, error) {
+	var artists []spotify.FullArtist
+	opts := []spotify.RequestOption{spotify.Limit(50)}
+	for {
+		page, err := w.client.CurrentUsersFollowedArtists(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		artists = append(artists, page.Artists...)
+		if page.Next == "" || page.Cursor.After == "" {
+			return artists, nil
+		}
+		opts = []spotify.RequestOption{spotify.Limit(50), spotify.After(page.Cursor.After)}
+	}
+}
+
+func (w *Watcher) playlistSnapshots(ctx context.Context) ([]playlistSnapshot, error) {
+	previous := make(map[spotify.ID]playlistSnapshot)
+	if w.last != nil {
+		for _, p := range w.last.playlists {
+			previous[p.playlist.ID] = p
+		}
+	}
+
+	page, err := w.client.CurrentUsersPlaylists(ctx, spotify.Limit(50))
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []playlistSnapshot
+	for {
+		for _, p := range page.Playlists {
+			if w.only != nil && !w.only[p.ID] {
+				continue
+			}
+			// the snapshot ID changes whenever a playlist is modified, so
+			// there's no need to fetch the items of unchanged playlists
+			if prev, ok := previous[p.ID]; ok && prev.playlist.SnapshotID == p.SnapshotID {
+				snapshots = append(snapshots, prev)
+				continue
+			}
+			items, err := w.playlistItems(ctx, p.ID)
+			if err != nil {
+				return nil, err
+			}
+			snapshots = append(snapshots, playlistSnapshot{playlist: p, items: items})
+		}
+
+		err = w.client.NextPage(ctx, page)
+		if errors.Is(err, spotify.ErrNoMorePages) {
+			return snapshots, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (w *Watcher) playlistItems(ctx context.Context, id spotify.ID) ([]spotify.PlaylistItem, error) {
+	return playlistItems(ctx, w.client, id)
+}
+
+func playlistItems(ctx context.Context, client *spotify.Client, id spotify.ID) ([]spotify.PlaylistItem, error) {
+	page, err := client.GetPlaylistItems(ctx, id, spotify.Limit(100))
+	if err != nil {
+		return nil, err
+	}
+	var items []spotify.PlaylistItem
+	for {
+		items = append(items, page.Items...)
+		err = client.NextPage(ctx, page)
+		if errors.Is(err, spotify.ErrNoMorePages) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
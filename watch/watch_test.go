@@ -0,0 +1,237 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// library is a fake Spotify library served over HTTP.
+type library struct {
+	tracks   []string
+	artists  []string
+	snapshot string
+	items    []string
+}
+
+func (l *library) serve(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/me/tracks":
+		fmt.Fprint(w, `{"items": [`)
+		for i, id := range l.tracks {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"added_at": "2021-01-01T00:00:00Z", "track": {"id": %q, "uri": "spotify:track:%s", "type": "track"}}`, id, id)
+		}
+		fmt.Fprint(w, `], "next": null}`)
+	case "/me/following":
+		fmt.Fprint(w, `{"artists": {"items": [`)
+		for i, id := range l.artists {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"id": %q}`, id)
+		}
+		fmt.Fprint(w, `], "next": null, "cursors": {"after": null}}}`)
+	case "/me/playlists":
+		fmt.Fprintf(w, `{"items": [{"id": "p1", "name": "Mix", "snapshot_id": %q}], "next": null}`, l.snapshot)
+	case "/playlists/p1":
+		fmt.Fprintf(w, `{"id": "p1", "name": "Mix", "snapshot_id": %q}`, l.snapshot)
+	case "/playlists/p1/tracks":
+		fmt.Fprint(w, `{"items": [`)
+		for i, id := range l.items {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"track": {"id": %q, "uri": "spotify:track:%s", "type": "track"}}`, id, id)
+		}
+		fmt.Fprint(w, `], "next": null}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestPoll(t *testing.T) {
+	lib := &library{
+		tracks:   []string{"t1", "t2"},
+		artists:  []string{"a1"},
+		snapshot: "s1",
+		items:    []string{"t1", "t3"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(lib.serve))
+	defer server.Close()
+
+	client := spotify.New(http.DefaultClient, spotify.WithBaseURL(server.URL+"/"))
+	w := New(client)
+	ctx := context.Background()
+
+	s, err := w.snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.last = s
+
+	if events := w.poll(ctx); len(events) != 0 {
+		t.Fatalf("Expected no events for an unchanged library, got %v", events)
+	}
+
+	lib.tracks = []string{"t2", "t4"}
+	lib.artists = []string{"a1", "a2"}
+	lib.snapshot = "s2"
+	lib.items = []string{"t1", "t3", "t3"}
+
+	events := w.poll(ctx)
+	if len(events) != 4 {
+		t.Fatalf("Expected 4 events, got %d: %v", len(events), events)
+	}
+	if e, ok := events[0].(TrackSaved); !ok || e.Track.ID != "t4" {
+		t.Errorf("Expected t4 to be saved, got %#v", events[0])
+	}
+	if e, ok := events[1].(TrackRemoved); !ok || e.Track.ID != "t1" {
+		t.Errorf("Expected t1 to be removed, got %#v", events[1])
+	}
+	if e, ok := events[2].(ArtistFollowed); !ok || e.Artist.ID != "a2" {
+		t.Errorf("Expected a2 to be followed, got %#v", events[2])
+	}
+	if e, ok := events[3].(PlaylistItemAdded); !ok || e.Item.Track.Track.ID != "t3" || e.Playlist.ID != "p1" {
+		t.Errorf("Expected t3 to be added to p1, got %#v", events[3])
+	}
+}
+
+func TestPollError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	client := spotify.New(http.DefaultClient, spotify.WithBaseURL(server.URL+"/"))
+	w := New(client, WithoutArtists(), WithoutPlaylists())
+	w.last = &snapshot{}
+
+	events := w.poll(context.Background())
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if _, ok := events[0].(Error); !ok {
+		t.Errorf("Expected an error event, got %#v", events[0])
+	}
+}
+
+func TestNewInvalidInterval(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		if w := New(nil, WithInterval(d)); w.interval != DefaultInterval {
+			t.Errorf("Expected an interval of %v to be replaced by the default, got %v", d, w.interval)
+		}
+	}
+}
+
+func TestWatchClosesChannel(t *testing.T) {
+	lib := &library{snapshot: "s1"}
+	server := httptest.NewServer(http.HandlerFunc(lib.serve))
+	defer server.Close()
+
+	client := spotify.New(http.DefaultClient, spotify.WithBaseURL(server.URL+"/"))
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := New(client, WithInterval(time.Millisecond)).Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("Expected no events")
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected channel to be closed")
+	}
+}
+
+func playlistOf(ids ...string) playlistSnapshot {
+	var p playlistSnapshot
+	for _, id := range ids {
+		var item spotify.PlaylistItem
+		item.Track.Track = &spotify.FullTrack{}
+		item.Track.Track.URI = spotify.URI("spotify:track:" + id)
+		p.items = append(p.items, item)
+	}
+	return p
+}
+
+func TestDiffPlaylistMoves(t *testing.T) {
+	events := diffPlaylist(playlistOf("a", "b", "c", "d"), playlistOf("b", "c", "a", "e"))
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d: %v", len(events), events)
+	}
+	if e, ok := events[0].(PlaylistItemAdded); !ok || e.Position != 3 {
+		t.Errorf("Expected e to be added at 3, got %#v", events[0])
+	}
+	if e, ok := events[1].(PlaylistItemRemoved); !ok || e.Position != 3 {
+		t.Errorf("Expected d to be removed from 3, got %#v", events[1])
+	}
+	if e, ok := events[2].(PlaylistItemMoved); !ok || e.From != 0 || e.To != 2 {
+		t.Errorf("Expected a to be moved from 0 to 2, got %#v", events[2])
+	}
+}
+
+func TestWatchPlaylist(t *testing.T) {
+	var mu sync.Mutex
+	lib := &library{snapshot: "s1", items: []string{"t1", "t2"}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		lib.serve(w, r)
+	}))
+	defer server.Close()
+
+	client := spotify.New(http.DefaultClient, spotify.WithBaseURL(server.URL+"/"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := WatchPlaylist(ctx, client, "p1", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	lib.snapshot = "s2"
+	lib.items = []string{"t2", "t1"}
+	mu.Unlock()
+
+	select {
+	case e := <-events:
+		moved, ok := e.(PlaylistItemMoved)
+		if !ok || moved.Playlist.ID != "p1" || moved.Item.Track.Track.ID != "t2" || moved.From != 1 || moved.To != 0 {
+			t.Errorf("Expected t2 to be moved to the start, got %#v", e)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected an event")
+	}
+}
+
+func TestWatchPlaylistInvalidInterval(t *testing.T) {
+	lib := &library{snapshot: "s1"}
+	server := httptest.NewServer(http.HandlerFunc(lib.serve))
+	defer server.Close()
+
+	client := spotify.New(http.DefaultClient, spotify.WithBaseURL(server.URL+"/"))
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := WatchPlaylist(ctx, client, "p1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("Expected no events")
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected channel to be closed")
+	}
+}